@@ -76,7 +76,92 @@ func (h *Handle) AddrDel(link Link, addr *Addr) error {
 	return h.addrHandle(link, addr, req)
 }
 
+// AddrReplaceAll converges the addresses of a link device to addrs. The
+// addresses missing from addrs are deleted first, then every address in
+// addrs is re-sent with AddrReplace, including the ones already present.
+// Calling it again with the same addrs leaves the same addresses in place.
+//
+// IPv6 link-local addresses are only deleted when addrs holds a link-local
+// address itself, and addresses with a label other than the link name
+// (aliases like eth0:1) are never deleted.
+func AddrReplaceAll(link Link, addrs []*Addr) error {
+	return pkgHandle.AddrReplaceAll(link, addrs)
+}
+
+// AddrReplaceAll converges the addresses of a link device to addrs. The
+// addresses missing from addrs are deleted first, then every address in
+// addrs is re-sent with AddrReplace, including the ones already present.
+// Calling it again with the same addrs leaves the same addresses in place.
+//
+// IPv6 link-local addresses are only deleted when addrs holds a link-local
+// address itself, and addresses with a label other than the link name
+// (aliases like eth0:1) are never deleted.
+func (h *Handle) AddrReplaceAll(link Link, addrs []*Addr) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	name := base.Name
+	if name == "" {
+		l, err := h.LinkByIndex(base.Index)
+		if err != nil {
+			return err
+		}
+		name = l.Attrs().Name
+	}
+
+	manageLinkLocal := false
+	for _, addr := range addrs {
+		if addr.IP.To4() == nil && addr.IP.IsLinkLocalUnicast() {
+			manageLinkLocal = true
+		}
+	}
+
+	current, err := h.AddrList(link, FAMILY_ALL)
+	if err != nil {
+		return err
+	}
+	for i := range current {
+		addr := &current[i]
+		if addr.Label != "" && addr.Label != name {
+			continue
+		}
+		if !manageLinkLocal && addr.IP.To4() == nil && addr.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		wanted := false
+		for _, a := range addrs {
+			if addr.Equal(*a) {
+				wanted = true
+				break
+			}
+		}
+		if wanted {
+			continue
+		}
+		// deleting a primary address takes its secondaries along
+		// unless promote_secondaries is set, so they may be gone
+		if err := h.AddrDel(link, addr); err != nil && err != unix.EADDRNOTAVAIL {
+			return err
+		}
+	}
+
+	for _, addr := range addrs {
+		if err := h.AddrReplace(link, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *Handle) addrHandle(link Link, addr *Addr, req *nl.NetlinkRequest) error {
+	if err := h.addrRequest(link, addr, req); err != nil {
+		return err
+	}
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
+// addrRequest adds the address message and attributes of addr to req.
+func (h *Handle) addrRequest(link Link, addr *Addr, req *nl.NetlinkRequest) error {
 	base := link.Attrs()
 	if addr.Label != "" && !strings.HasPrefix(addr.Label, base.Name) {
 		return fmt.Errorf("label must begin with interface name")
@@ -163,35 +248,42 @@ func (h *Handle) addrHandle(link Link, addr *Addr, req *nl.NetlinkRequest) error
 		req.AddData(nl.NewRtAttr(unix.IFA_CACHEINFO, cachedata.Serialize()))
 	}
 
-	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
-	return err
+	return nil
 }
 
 // AddrList gets a list of IP addresses in the system.
 // Equivalent to: `ip addr show`.
-// The list can be filtered by link and ip family.
+// The list can be filtered by link and ip family. Kernels 4.20 and newer
+// filter by link themselves, on the sockets of a Handle only once
+// SetStrictCheck enabled it.
 func AddrList(link Link, family int) ([]Addr, error) {
 	return pkgHandle.AddrList(link, family)
 }
 
 // AddrList gets a list of IP addresses in the system.
 // Equivalent to: `ip addr show`.
-// The list can be filtered by link and ip family.
+// The list can be filtered by link and ip family. Kernels 4.20 and newer
+// filter by link themselves, on the sockets of a Handle only once
+// SetStrictCheck enabled it.
 func (h *Handle) AddrList(link Link, family int) ([]Addr, error) {
 	req := h.newNetlinkRequest(unix.RTM_GETADDR, unix.NLM_F_DUMP)
-	msg := nl.NewIfInfomsg(family)
-	req.AddData(msg)
-
-	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWADDR)
-	if err != nil {
-		return nil, err
-	}
+	msg := nl.NewIfAddrmsg(family)
 
 	indexFilter := 0
 	if link != nil {
 		base := link.Attrs()
 		h.ensureIndex(base)
 		indexFilter = base.Index
+		// Kernels with strict checking only dump the addresses of the
+		// link, the others are still filtered below
+		msg.Index = uint32(indexFilter)
+		req.StrictCheck = true
+	}
+	req.AddData(msg)
+
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWADDR)
+	if err != nil {
+		return nil, err
 	}
 
 	var res []Addr
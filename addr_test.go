@@ -3,8 +3,11 @@
 package netlink
 
 import (
+	"fmt"
 	"net"
 	"os"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -194,6 +197,103 @@ func TestAddrAddReplace(t *testing.T) {
 	}
 }
 
+func TestAddrListKernelFilter(t *testing.T) {
+	minKernelRequired(t, 4, 20)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	var links []Link
+	for i, name := range []string{"foo", "bar"} {
+		link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr, err := ParseAddr(fmt.Sprintf("10.0.%d.1/24", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := AddrAdd(link, addr); err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, link)
+	}
+
+	// a handle without sockets opens one with strict checking per request
+	h := &Handle{}
+	var dumped int
+	h.SetRequestHook(func(msgType int, data []byte) {
+		if msgType == unix.RTM_NEWADDR {
+			dumped++
+		}
+	})
+	addrs, err := h.AddrList(links[1], FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || addrs[0].IPNet.String() != "10.0.1.1/24" {
+		t.Fatalf("Unexpected addresses %v", addrs)
+	}
+	if dumped != 1 {
+		t.Fatalf("Expected the kernel to send 1 address, got %d", dumped)
+	}
+
+	// the kernel reports an unknown link at the end of the dump
+	addrs, err = h.AddrList(&Bridge{LinkAttrs: LinkAttrs{Index: 9999}}, FAMILY_ALL)
+	if err != nil || len(addrs) != 0 {
+		t.Fatalf("Expected no addresses for a missing link, got %v, %v", addrs, err)
+	}
+}
+
+func TestAddrReplaceAll(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"10.0.0.1/24 foo:1", "10.0.1.1/24", "2001:db8::1/64", "fe80::1/64"} {
+		addr, err := ParseAddr(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := AddrAdd(link, addr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var want []*Addr
+	for _, s := range []string{"10.0.1.1/24", "10.0.2.1/24", "2001:db8::2/64"} {
+		addr, err := ParseAddr(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, addr)
+	}
+	// labelled and link-local addresses are kept
+	expected := []string{"10.0.0.1/24", "10.0.1.1/24", "10.0.2.1/24", "2001:db8::2/64", "fe80::1/64"}
+	for i := 0; i < 2; i++ {
+		if err := AddrReplaceAll(link, want); err != nil {
+			t.Fatal(err)
+		}
+		addrs, err := AddrList(link, FAMILY_ALL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, addr := range addrs {
+			got = append(got, addr.IPNet.String())
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Expected addresses %v, got %v", expected, got)
+		}
+	}
+}
+
 func expectAddrUpdate(ch <-chan AddrUpdate, add bool, dst net.IP) bool {
 	for {
 		timeout := time.After(time.Minute)
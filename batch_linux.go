@@ -0,0 +1,115 @@
+package netlink
+
+import (
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// Batch queues route and address changes and sends them to the kernel
+// together with Commit. This avoids a round trip per change when a large
+// number of routes or addresses are programmed. rtnetlink has no
+// transactions, so each change is applied or rejected on its own.
+type Batch struct {
+	h    *Handle
+	reqs []*nl.NetlinkRequest
+	errs []error
+}
+
+// NewBatch creates an empty batch that is committed with the package handle.
+func NewBatch() *Batch {
+	return pkgHandle.NewBatch()
+}
+
+// NewBatch creates an empty batch that is committed with the sockets of
+// the handle.
+func (h *Handle) NewBatch() *Batch {
+	return &Batch{h: h}
+}
+
+// Len returns the number of queued changes.
+func (b *Batch) Len() int {
+	return len(b.reqs)
+}
+
+// RouteAdd queues the addition of a route.
+// Equivalent to: `ip route add $route`
+func (b *Batch) RouteAdd(route *Route) {
+	flags := unix.NLM_F_CREATE | unix.NLM_F_EXCL | unix.NLM_F_ACK
+	req := b.h.newNetlinkRequest(unix.RTM_NEWROUTE, flags)
+	b.add(req, b.h.routeRequest(route, req, nl.NewRtMsg()))
+}
+
+// RouteReplace queues the replacement of a route.
+// Equivalent to: `ip route replace $route`
+func (b *Batch) RouteReplace(route *Route) {
+	flags := unix.NLM_F_CREATE | unix.NLM_F_REPLACE | unix.NLM_F_ACK
+	req := b.h.newNetlinkRequest(unix.RTM_NEWROUTE, flags)
+	b.add(req, b.h.routeRequest(route, req, nl.NewRtMsg()))
+}
+
+// RouteDel queues the deletion of a route.
+// Equivalent to: `ip route del $route`
+func (b *Batch) RouteDel(route *Route) {
+	req := b.h.newNetlinkRequest(unix.RTM_DELROUTE, unix.NLM_F_ACK)
+	b.add(req, b.h.routeRequest(route, req, nl.NewRtDelMsg()))
+}
+
+// AddrAdd queues the addition of an IP address to a link device.
+// Equivalent to: `ip addr add $addr dev $link`
+func (b *Batch) AddrAdd(link Link, addr *Addr) {
+	req := b.h.newNetlinkRequest(unix.RTM_NEWADDR, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ACK)
+	b.add(req, b.h.addrRequest(link, addr, req))
+}
+
+// AddrReplace queues the replacement of an IP address on a link device.
+// Equivalent to: `ip addr replace $addr dev $link`
+func (b *Batch) AddrReplace(link Link, addr *Addr) {
+	req := b.h.newNetlinkRequest(unix.RTM_NEWADDR, unix.NLM_F_CREATE|unix.NLM_F_REPLACE|unix.NLM_F_ACK)
+	b.add(req, b.h.addrRequest(link, addr, req))
+}
+
+// AddrDel queues the deletion of an IP address from a link device.
+// Equivalent to: `ip addr del $addr dev $link`
+func (b *Batch) AddrDel(link Link, addr *Addr) {
+	req := b.h.newNetlinkRequest(unix.RTM_DELADDR, unix.NLM_F_ACK)
+	b.add(req, b.h.addrRequest(link, addr, req))
+}
+
+// add queues req, or the error building it which is then reported by
+// Commit without sending the request.
+func (b *Batch) add(req *nl.NetlinkRequest, err error) {
+	if err != nil {
+		req = nil
+	}
+	b.reqs = append(b.reqs, req)
+	b.errs = append(b.errs, err)
+}
+
+// Commit sends all queued changes in as few sendmsg calls as possible and
+// empties the batch. The returned slice holds the result of each change in
+// the order they were queued, nil for the ones that were applied. The error
+// is only set when the batch could not be sent, in which case some of the
+// changes may have been applied.
+func (b *Batch) Commit() ([]error, error) {
+	reqs, res := b.reqs, b.errs
+	b.reqs, b.errs = nil, nil
+
+	var (
+		send    []*nl.NetlinkRequest
+		indices []int
+	)
+	for i, req := range reqs {
+		if req != nil {
+			send = append(send, req)
+			indices = append(indices, i)
+		}
+	}
+	errs, err := nl.ExecuteBatch(unix.NETLINK_ROUTE, send)
+	if err != nil {
+		return nil, err
+	}
+	for i, err := range errs {
+		res[indices[i]] = err
+	}
+	return res, nil
+}
@@ -0,0 +1,140 @@
+// +build linux
+
+package netlink
+
+import (
+	"net"
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func testBatchRoutes(t *testing.T, h *Handle) {
+	link, err := h.LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 3*nl.BatchWindow + 1
+	var routes []*Route
+	for i := 0; i < count; i++ {
+		routes = append(routes, &Route{
+			LinkIndex: link.Attrs().Index,
+			Dst:       &net.IPNet{IP: net.IPv4(10, 1, byte(i>>8), byte(i)), Mask: net.CIDRMask(32, 32)},
+		})
+	}
+
+	batch := h.NewBatch()
+	for _, route := range routes {
+		batch.RouteAdd(route)
+	}
+	// a duplicate is rejected by the kernel and an empty route is
+	// rejected before it is sent
+	batch.RouteAdd(routes[0])
+	batch.RouteAdd(&Route{})
+	if batch.Len() != count+2 {
+		t.Fatalf("Expected %d queued changes, got %d", count+2, batch.Len())
+	}
+	errs, err := batch.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if batch.Len() != 0 {
+		t.Fatal("Commit did not empty the batch")
+	}
+	for i := 0; i < count; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Adding route %s failed: %v", routes[i].Dst, errs[i])
+		}
+	}
+	if errs[count] != unix.EEXIST {
+		t.Fatalf("Expected EEXIST for the duplicate route, got %v", errs[count])
+	}
+	if errs[count+1] == nil {
+		t.Fatal("Expected an error for the empty route")
+	}
+
+	list, err := h.RouteList(link, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) < count {
+		t.Fatalf("Expected at least %d routes, got %d", count, len(list))
+	}
+
+	for _, route := range routes {
+		batch.RouteDel(route)
+	}
+	errs, err = batch.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Deleting route %s failed: %v", routes[i].Dst, err)
+		}
+	}
+	list, err = h.RouteListFiltered(FAMILY_V4, routes[0], RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Fatalf("Routes not deleted: %v", list)
+	}
+}
+
+func TestBatchRoutes(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testBatchRoutes(t, pkgHandle)
+}
+
+func TestBatchRoutesHandle(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	h, err := NewHandle(unix.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+	testBatchRoutes(t, h)
+
+	// the socket of the handle is still usable after a batch
+	if _, err := h.LinkByName("lo"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBatchAddrs(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := &Addr{IPNet: &net.IPNet{IP: net.IPv4(127, 0, 0, 2), Mask: net.CIDRMask(32, 32)}}
+	batch := NewBatch()
+	batch.AddrAdd(link, addr)
+	batch.AddrReplace(link, addr)
+	batch.AddrDel(link, addr)
+	batch.AddrDel(link, addr)
+	errs, err := batch.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, err := range errs[:3] {
+		if err != nil {
+			t.Fatalf("Address change %d failed: %v", i, err)
+		}
+	}
+	if errs[3] != unix.EADDRNOTAVAIL {
+		t.Fatalf("Expected EADDRNOTAVAIL for deleting a missing address, got %v", errs[3])
+	}
+}
@@ -2,6 +2,7 @@ package netlink
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Class interfaces for all classes
@@ -44,9 +45,9 @@ type GnetStatsQueue struct {
 // ClassStatistics representation based on generic networking statistics for netlink.
 // See Documentation/networking/gen_stats.txt in Linux source code for more details.
 type ClassStatistics struct {
-	Basic   *GnetStatsBasic
-	Queue   *GnetStatsQueue
-	RateEst *GnetStatsRateEst
+	Basic   *GnetStatsBasic   // TCA_STATS_BASIC: bytes and packets sent
+	Queue   *GnetStatsQueue   // TCA_STATS_QUEUE: qlen, backlog, drops, requeues and overlimits
+	RateEst *GnetStatsRateEst // TCA_STATS_RATE_EST
 }
 
 // NewClassStatistics Construct a ClassStatistics struct which fields are all initialized by 0.
@@ -73,6 +74,139 @@ func (q ClassAttrs) String() string {
 	return fmt.Sprintf("{LinkIndex: %d, Handle: %s, Parent: %s, Leaf: %d}", q.LinkIndex, HandleStr(q.Handle), HandleStr(q.Parent), q.Leaf)
 }
 
+// classString formats a class like `tc class show`:
+// class $type $handle dev $index root|parent $parent [leaf $leaf] $options
+func classString(class Class, options ...string) string {
+	attrs := class.Attrs()
+	elems := []string{"class", class.Type(), tcHandleStr(attrs.Handle), fmt.Sprintf("dev %d", attrs.LinkIndex)}
+	if attrs.Parent == HANDLE_ROOT {
+		elems = append(elems, "root")
+	} else {
+		elems = append(elems, "parent", tcHandleStr(attrs.Parent))
+	}
+	if attrs.Leaf != 0 {
+		elems = append(elems, "leaf", tcHandleStr(attrs.Leaf))
+	}
+	return strings.Join(append(elems, options...), " ")
+}
+
+// The kernel clamps the quantum it derives from the rate of a leaf class
+// to HTB_MIN_QUANTUM and HTB_MAX_QUANTUM bytes, logging a warning.
+const (
+	HTB_MIN_QUANTUM = 1000
+	HTB_MAX_QUANTUM = 200000
+)
+
+// HtbQuantum returns the quantum in bytes the kernel derives for a leaf
+// class with rate in bytes per second under an htb qdisc with the given
+// Rate2Quantum, clamped to the range the kernel accepts without warning.
+func HtbQuantum(rate uint64, r2q uint32) uint32 {
+	if r2q == 0 {
+		r2q = 1
+	}
+	quantum := rate / uint64(r2q)
+	if quantum < HTB_MIN_QUANTUM {
+		return HTB_MIN_QUANTUM
+	}
+	if quantum > HTB_MAX_QUANTUM {
+		return HTB_MAX_QUANTUM
+	}
+	return uint32(quantum)
+}
+
+// checkHtbQuantum rejects an explicit quantum outside of the range the
+// kernel accepts without warning.
+func checkHtbQuantum(quantum uint32) error {
+	if quantum < HTB_MIN_QUANTUM || quantum > HTB_MAX_QUANTUM {
+		return fmt.Errorf("quantum %d is out of range %d-%d", quantum, HTB_MIN_QUANTUM, HTB_MAX_QUANTUM)
+	}
+	return nil
+}
+
+// HTB_MAX_DEPTH is the maximum depth of an HTB class tree.
+const HTB_MAX_DEPTH = 8
+
+// ValidateHtbTree checks an HTB class hierarchy before it is applied. Each
+// class must hang off root or another class in classes, a class rate must
+// not exceed its ceil, and the rates of the children of a class must not
+// add up to more than its ceil. The quantum of a leaf class, or the one
+// derived from its rate and root.Rate2Quantum when zero, must be in the
+// range the kernel accepts without warning.
+// Inner classes don't use a quantum. The first problem found is returned.
+func ValidateHtbTree(root *Htb, classes []*HtbClass) error {
+	if root == nil {
+		return fmt.Errorf("HTB: no root qdisc")
+	}
+	rootMajor, _ := MajorMinor(root.Handle)
+	byHandle := make(map[uint32]*HtbClass, len(classes))
+	for _, class := range classes {
+		if major, _ := MajorMinor(class.Handle); major != rootMajor {
+			return fmt.Errorf("HTB class %s: major does not match root qdisc %s", HandleStr(class.Handle), HandleStr(root.Handle))
+		}
+		if _, ok := byHandle[class.Handle]; ok {
+			return fmt.Errorf("HTB class %s: duplicate handle", HandleStr(class.Handle))
+		}
+		byHandle[class.Handle] = class
+	}
+
+	childRates := make(map[uint32]uint64)
+	for _, class := range classes {
+		name := HandleStr(class.Handle)
+		if class.Ceil != 0 && class.Rate > class.Ceil {
+			return fmt.Errorf("HTB class %s: rate %d exceeds ceil %d", name, class.Rate, class.Ceil)
+		}
+
+		depth := 1
+		for parent := class.Parent; parent != root.Handle; depth++ {
+			p, ok := byHandle[parent]
+			if !ok {
+				return fmt.Errorf("HTB class %s: parent %s is neither the root qdisc nor a class", name, HandleStr(parent))
+			}
+			if depth >= HTB_MAX_DEPTH {
+				return fmt.Errorf("HTB class %s: deeper than %d levels or part of a loop", name, HTB_MAX_DEPTH)
+			}
+			parent = p.Parent
+		}
+		if parent, ok := byHandle[class.Parent]; ok {
+			childRates[parent.Handle] += class.Rate
+		}
+	}
+
+	for _, class := range classes {
+		name := HandleStr(class.Handle)
+		if sum, ok := childRates[class.Handle]; ok {
+			if sum > htbCeil(class) {
+				return fmt.Errorf("HTB class %s: child rates add up to %d and exceed its ceil %d", name, sum, htbCeil(class))
+			}
+			continue
+		}
+
+		quantum := class.Quantum
+		if quantum == 0 {
+			r2q := root.Rate2Quantum
+			if r2q == 0 {
+				r2q = 1
+			}
+			derived := class.Rate / uint64(r2q)
+			if derived < HTB_MIN_QUANTUM || derived > HTB_MAX_QUANTUM {
+				return fmt.Errorf("HTB class %s: quantum %d derived from rate %d and r2q %d is out of range %d-%d, set Quantum or change Rate2Quantum",
+					name, derived, class.Rate, r2q, HTB_MIN_QUANTUM, HTB_MAX_QUANTUM)
+			}
+		} else if err := checkHtbQuantum(quantum); err != nil {
+			return fmt.Errorf("HTB class %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// htbCeil returns the ceil of class, which defaults to its rate.
+func htbCeil(class *HtbClass) uint64 {
+	if class.Ceil == 0 {
+		return class.Rate
+	}
+	return class.Ceil
+}
+
 // HtbClassAttrs stores the attributes of HTB class
 type HtbClassAttrs struct {
 	// TODO handle all attributes
@@ -83,6 +217,9 @@ type HtbClassAttrs struct {
 	Quantum uint32
 	Level   uint32
 	Prio    uint32
+	// Rate2Quantum of the htb qdisc, used by NewHtbClass to derive the
+	// quantum when Quantum is 0. Defaults to 10 like the qdisc.
+	Rate2Quantum uint32
 }
 
 func (q HtbClassAttrs) String() string {
@@ -101,10 +238,6 @@ type HtbClass struct {
 	Prio    uint32
 }
 
-func (q HtbClass) String() string {
-	return fmt.Sprintf("{Rate: %d, Ceil: %d, Buffer: %d, Cbuffer: %d}", q.Rate, q.Ceil, q.Buffer, q.Cbuffer)
-}
-
 // Attrs returns the class attributes
 func (q *HtbClass) Attrs() *ClassAttrs {
 	return &q.ClassAttrs
@@ -122,6 +255,10 @@ type GenericClass struct {
 	ClassType string
 }
 
+func (class *GenericClass) String() string {
+	return classString(class)
+}
+
 // Attrs return the class attributes
 func (class *GenericClass) Attrs() *ClassAttrs {
 	return &class.ClassAttrs
@@ -189,7 +326,7 @@ func (hfsc *HfscClass) SetRsc(m1 uint32, d uint32, m2 uint32) {
 }
 
 // SetSC implements the SC from the `tc` CLI. This function behaves the same as if one would set the
-// USC through the `tc` command-line tool. This means bandwidth (m1 and m2) is specified in bits and
+// RSC and FSC through the `tc` command-line tool. This means bandwidth (m1 and m2) is specified in bits and
 // the delay in ms.
 func (hfsc *HfscClass) SetSC(m1 uint32, d uint32, m2 uint32) {
 	hfsc.SetRsc(m1, d, m2)
@@ -204,12 +341,48 @@ func (hfsc *HfscClass) SetUL(m1 uint32, d uint32, m2 uint32) {
 }
 
 // SetLS implements the LS from the `tc` CLI. This function behaves the same as if one would set the
-// USC through the `tc` command-line tool. This means bandwidth (m1 and m2) is specified in bits and
+// FSC through the `tc` command-line tool. This means bandwidth (m1 and m2) is specified in bits and
 // the delay in ms.
 func (hfsc *HfscClass) SetLS(m1 uint32, d uint32, m2 uint32) {
 	hfsc.SetFsc(m1, d, m2)
 }
 
+// String formats the curve like tc, the bandwidth is in bits.
+func (c ServiceCurve) String() string {
+	return fmt.Sprintf("m1 %s d %s m2 %s", formatRate(uint64(c.m1/8)), formatTime(c.d), formatRate(uint64(c.m2/8)))
+}
+
+func (c *ServiceCurve) isZero() bool {
+	return c.m1 == 0 && c.m2 == 0
+}
+
+// validate checks the curves of the class the way tc does before the
+// kernel rejects them with a bare EINVAL: a new class needs a real-time or
+// a link-sharing curve, and the upper limit only caps link-sharing so it
+// needs an FSC whose rate it does not undercut. A change of an existing
+// class may leave out curves, which keep their current values.
+func (hfsc *HfscClass) validate(create bool) error {
+	if !create {
+		if !hfsc.Usc.isZero() && !hfsc.Fsc.isZero() && hfsc.Usc.m2 < hfsc.Fsc.m2 {
+			return fmt.Errorf("HFSC: class %s upper limit rate %d is below its link-sharing rate %d", HandleStr(hfsc.Handle), hfsc.Usc.m2, hfsc.Fsc.m2)
+		}
+		return nil
+	}
+	if hfsc.Rsc.isZero() && hfsc.Fsc.isZero() {
+		return fmt.Errorf("HFSC: class %s needs a real-time (rsc) or link-sharing (fsc) curve", HandleStr(hfsc.Handle))
+	}
+	if hfsc.Usc.isZero() {
+		return nil
+	}
+	if hfsc.Fsc.isZero() {
+		return fmt.Errorf("HFSC: class %s has an upper limit (usc) but no link-sharing (fsc) curve", HandleStr(hfsc.Handle))
+	}
+	if hfsc.Usc.m2 < hfsc.Fsc.m2 {
+		return fmt.Errorf("HFSC: class %s upper limit rate %d is below its link-sharing rate %d", HandleStr(hfsc.Handle), hfsc.Usc.m2, hfsc.Fsc.m2)
+	}
+	return nil
+}
+
 // NewHfscClass returns a new HFSC struct with the set parameters
 func NewHfscClass(attrs ClassAttrs) *HfscClass {
 	return &HfscClass{
@@ -220,12 +393,19 @@ func NewHfscClass(attrs ClassAttrs) *HfscClass {
 	}
 }
 
-// String() returns a string that contains the information and attributes of the HFSC class
+// String formats the HFSC class like `tc class show`, with the curves
+// that are set as rt, ls and ul.
 func (hfsc *HfscClass) String() string {
-	return fmt.Sprintf(
-		"{%s -- {RSC: {m1=%d d=%d m2=%d}} {FSC: {m1=%d d=%d m2=%d}} {USC: {m1=%d d=%d m2=%d}}}",
-		hfsc.Attrs(), hfsc.Rsc.m1*8, hfsc.Rsc.d, hfsc.Rsc.m2*8, hfsc.Fsc.m1*8, hfsc.Fsc.d, hfsc.Fsc.m2*8, hfsc.Usc.m1*8, hfsc.Usc.d, hfsc.Usc.m2*8,
-	)
+	options := []string{}
+	for _, curve := range []struct {
+		name string
+		sc   ServiceCurve
+	}{{"rt", hfsc.Rsc}, {"ls", hfsc.Fsc}, {"ul", hfsc.Usc}} {
+		if !curve.sc.isZero() {
+			options = append(options, fmt.Sprintf("%s %s", curve.name, curve.sc))
+		}
+	}
+	return classString(hfsc, options...)
 }
 
 // Attrs return the Hfsc parameters
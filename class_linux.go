@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
 	"syscall"
 
 	"github.com/vishvananda/netlink/nl"
@@ -28,7 +29,15 @@ type tcStats struct {
 	Backlog    uint32
 }
 
-// NewHtbClass NOTE: function is in here because it uses other linux functions
+// NewHtbClass returns an HtbClass with the rates converted to bytes per
+// second and the bursts converted to ticks, like the tc CLI does.
+// When Ceil is 0 it defaults to Rate. When Buffer (or Cbuffer) is 0 the
+// burst defaults to rate/Hz() + mtu bytes, the amount of data sent during
+// one timer tick plus one 1600 byte packet, so the class created with only
+// Rate set matches `tc class add ... htb rate $rate`.
+// When Quantum is 0 it is derived from the rate and Rate2Quantum like the
+// kernel does and clamped to the range it accepts, see HtbQuantum.
+// NOTE: function is in here because it uses other linux functions
 func NewHtbClass(attrs ClassAttrs, cattrs HtbClassAttrs) *HtbClass {
 	mtu := 1600
 	rate := cattrs.Rate / 8
@@ -50,18 +59,42 @@ func NewHtbClass(attrs ClassAttrs, cattrs HtbClassAttrs) *HtbClass {
 	}
 	cbuffer = uint32(Xmittime(ceil, cbuffer))
 
+	quantum := cattrs.Quantum
+	if quantum == 0 {
+		r2q := cattrs.Rate2Quantum
+		if r2q == 0 {
+			r2q = 10
+		}
+		quantum = HtbQuantum(rate, r2q)
+	}
+
 	return &HtbClass{
 		ClassAttrs: attrs,
 		Rate:       rate,
 		Ceil:       ceil,
 		Buffer:     buffer,
 		Cbuffer:    cbuffer,
-		Quantum:    10,
+		Quantum:    quantum,
 		Level:      0,
 		Prio:       0,
 	}
 }
 
+// String formats the class like `tc class show`, with the bursts
+// converted back from ticks.
+// NOTE: function is in here because it uses other linux functions
+func (q HtbClass) String() string {
+	options := []string{fmt.Sprintf(
+		"prio %d rate %s ceil %s burst %s cburst %s",
+		q.Prio, formatRate(q.Rate), formatRate(q.Ceil),
+		formatSize(burst(q.Rate, q.Buffer)), formatSize(burst(q.Ceil, q.Cbuffer)),
+	)}
+	if q.Quantum > 0 {
+		options = append(options, fmt.Sprintf("quantum %d", q.Quantum))
+	}
+	return classString(&q, options...)
+}
+
 // ClassDel will delete a class from the system.
 // Equivalent to: `tc class del $class`
 func ClassDel(class Class) error {
@@ -122,7 +155,39 @@ func (h *Handle) ClassAdd(class Class) error {
 	)
 }
 
+// ClassAddAndGet will add a class to the system and return the class as
+// echoed by the kernel.
+// Equivalent to: `tc -echo class add $class`
+func ClassAddAndGet(class Class) (Class, error) {
+	return pkgHandle.ClassAddAndGet(class)
+}
+
+// ClassAddAndGet will add a class to the system and return the class as
+// echoed by the kernel.
+// Equivalent to: `tc -echo class add $class`
+func (h *Handle) ClassAddAndGet(class Class) (Class, error) {
+	msgs, err := h.classExecute(
+		unix.RTM_NEWTCLASS,
+		unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ECHO,
+		class,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("kernel did not echo the added class")
+	}
+	return parseClassMsg(msgs[0])
+}
+
 func (h *Handle) classModify(cmd, flags int, class Class) error {
+	_, err := h.classExecute(cmd, flags, class)
+	return err
+}
+
+// classExecute sends a class request and returns the RTM_NEWTCLASS messages
+// echoed by the kernel when flags has NLM_F_ECHO set.
+func (h *Handle) classExecute(cmd, flags int, class Class) ([][]byte, error) {
 	req := h.newNetlinkRequest(cmd, flags|unix.NLM_F_ACK)
 	base := class.Attrs()
 	msg := &nl.TcMsg{
@@ -135,11 +200,15 @@ func (h *Handle) classModify(cmd, flags int, class Class) error {
 
 	if cmd != unix.RTM_DELTCLASS {
 		if err := classPayload(req, class); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
-	return err
+
+	var resType uint16
+	if flags&unix.NLM_F_ECHO != 0 {
+		resType = unix.RTM_NEWTCLASS
+	}
+	return req.Execute(unix.NETLINK_ROUTE, resType)
 }
 
 func classPayload(req *nl.NetlinkRequest, class Class) error {
@@ -149,6 +218,12 @@ func classPayload(req *nl.NetlinkRequest, class Class) error {
 	switch class.Type() {
 	case "htb":
 		htb := class.(*HtbClass)
+		// a quantum of 0 lets the kernel derive it from the rate
+		if htb.Quantum != 0 {
+			if err := checkHtbQuantum(htb.Quantum); err != nil {
+				return fmt.Errorf("HTB: %v", err)
+			}
+		}
 		opt := nl.TcHtbCopt{}
 		opt.Buffer = htb.Buffer
 		opt.Cbuffer = htb.Cbuffer
@@ -163,12 +238,20 @@ func classPayload(req *nl.NetlinkRequest, class Class) error {
 		mtu := 1600
 		var rtab [256]uint32
 		var ctab [256]uint32
+		// rates that don't fit the 32 bit rate spec are sent in
+		// TCA_HTB_RATE64 and TCA_HTB_CEIL64 like tc does
 		tcrate := nl.TcRateSpec{Rate: uint32(htb.Rate)}
+		if htb.Rate >= 1<<32 {
+			tcrate.Rate = math.MaxUint32
+		}
 		if CalcRtable(&tcrate, rtab[:], cellLog, uint32(mtu), linklayer) < 0 {
 			return errors.New("HTB: failed to calculate rate table")
 		}
 		opt.Rate = tcrate
 		tcceil := nl.TcRateSpec{Rate: uint32(htb.Ceil)}
+		if htb.Ceil >= 1<<32 {
+			tcceil.Rate = math.MaxUint32
+		}
 		if CalcRtable(&tcceil, ctab[:], ccellLog, uint32(mtu), linklayer) < 0 {
 			return errors.New("HTB: failed to calculate ceil rate table")
 		}
@@ -176,8 +259,18 @@ func classPayload(req *nl.NetlinkRequest, class Class) error {
 		options.AddRtAttr(nl.TCA_HTB_PARMS, opt.Serialize())
 		options.AddRtAttr(nl.TCA_HTB_RTAB, SerializeRtab(rtab))
 		options.AddRtAttr(nl.TCA_HTB_CTAB, SerializeRtab(ctab))
+		if htb.Rate >= 1<<32 {
+			options.AddRtAttr(nl.TCA_HTB_RATE64, nl.Uint64Attr(htb.Rate))
+		}
+		if htb.Ceil >= 1<<32 {
+			options.AddRtAttr(nl.TCA_HTB_CEIL64, nl.Uint64Attr(htb.Ceil))
+		}
 	case "hfsc":
 		hfsc := class.(*HfscClass)
+		// requests without NLM_F_CREATE only change an existing class
+		if err := hfsc.validate(req.Flags&unix.NLM_F_CREATE != 0); err != nil {
+			return err
+		}
 		opt := nl.HfscCopt{}
 		rm1, rd, rm2 := hfsc.Rsc.Attrs()
 		opt.Rsc.Set(rm1/8, rd, rm2/8)
@@ -223,73 +316,89 @@ func (h *Handle) ClassList(link Link, parent uint32) ([]Class, error) {
 
 	var res []Class
 	for _, m := range msgs {
-		msg := nl.DeserializeTcMsg(m)
-
-		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+		class, err := parseClassMsg(m)
 		if err != nil {
 			return nil, err
 		}
+		res = append(res, class)
+	}
 
-		base := ClassAttrs{
-			LinkIndex:  int(msg.Ifindex),
-			Handle:     msg.Handle,
-			Parent:     msg.Parent,
-			Statistics: nil,
-		}
+	return res, nil
+}
+
+// parseClassMsg decodes a RTM_NEWTCLASS message into a Class.
+// classTypes maps the kind of a class to a constructor of its type. Other
+// kinds are decoded as a GenericClass.
+var classTypes = map[string]func() Class{
+	"htb":  func() Class { return &HtbClass{} },
+	"hfsc": func() Class { return &HfscClass{} },
+}
+
+func newClassOfType(kind string) Class {
+	if newClass, ok := classTypes[kind]; ok {
+		return newClass()
+	}
+	return &GenericClass{ClassType: kind}
+}
+
+func parseClassMsg(m []byte) (Class, error) {
+	msg := nl.DeserializeTcMsg(m)
 
-		var class Class
-		classType := ""
-		for _, attr := range attrs {
-			switch attr.Attr.Type {
-			case nl.TCA_KIND:
-				classType = string(attr.Value[:len(attr.Value)-1])
-				switch classType {
-				case "htb":
-					class = &HtbClass{}
-				case "hfsc":
-					class = &HfscClass{}
-				default:
-					class = &GenericClass{ClassType: classType}
+	attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+	if err != nil {
+		return nil, err
+	}
+
+	base := ClassAttrs{
+		LinkIndex:  int(msg.Ifindex),
+		Handle:     msg.Handle,
+		Parent:     msg.Parent,
+		Statistics: nil,
+	}
+
+	var class Class
+	classType := ""
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.TCA_KIND:
+			classType = string(attr.Value[:len(attr.Value)-1])
+			class = newClassOfType(classType)
+		case nl.TCA_OPTIONS:
+			switch classType {
+			case "htb":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
 				}
-			case nl.TCA_OPTIONS:
-				switch classType {
-				case "htb":
-					data, err := nl.ParseRouteAttr(attr.Value)
-					if err != nil {
-						return nil, err
-					}
-					_, err = parseHtbClassData(class, data)
-					if err != nil {
-						return nil, err
-					}
-				case "hfsc":
-					data, err := nl.ParseRouteAttr(attr.Value)
-					if err != nil {
-						return nil, err
-					}
-					_, err = parseHfscClassData(class, data)
-					if err != nil {
-						return nil, err
-					}
+				_, err = parseHtbClassData(class, data)
+				if err != nil {
+					return nil, err
 				}
-			// For backward compatibility.
-			case nl.TCA_STATS:
-				base.Statistics, err = parseTcStats(attr.Value)
+			case "hfsc":
+				data, err := nl.ParseRouteAttr(attr.Value)
 				if err != nil {
 					return nil, err
 				}
-			case nl.TCA_STATS2:
-				base.Statistics, err = parseTcStats2(attr.Value)
+				_, err = parseHfscClassData(class, data)
 				if err != nil {
 					return nil, err
 				}
 			}
+		// For backward compatibility.
+		case nl.TCA_STATS:
+			base.Statistics, err = parseTcStats(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+		case nl.TCA_STATS2:
+			base.Statistics, err = parseTcStats2(attr.Value)
+			if err != nil {
+				return nil, err
+			}
 		}
-		*class.Attrs() = base
-		res = append(res, class)
 	}
-
-	return res, nil
+	*class.Attrs() = base
+	return class, nil
 }
 
 func parseHtbClassData(class Class, data []syscall.NetlinkRouteAttr) (bool, error) {
@@ -306,6 +415,12 @@ func parseHtbClassData(class Class, data []syscall.NetlinkRouteAttr) (bool, erro
 			htb.Quantum = opt.Quantum
 			htb.Level = opt.Level
 			htb.Prio = opt.Prio
+		// the kernel caps the rates in TCA_HTB_PARMS at 32 bits and
+		// sends the full rates after it
+		case nl.TCA_HTB_RATE64:
+			htb.Rate = native.Uint64(datum.Value[0:8])
+		case nl.TCA_HTB_CEIL64:
+			htb.Ceil = native.Uint64(datum.Value[0:8])
 		}
 	}
 	return detailed, nil
@@ -5,6 +5,9 @@ package netlink
 import (
 	"reflect"
 	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
 )
 
 func SafeQdiscList(link Link) ([]Qdisc, error) {
@@ -51,6 +54,75 @@ func testClassStats(this, that *ClassStatistics, t *testing.T) {
 	}
 }
 
+func TestParseTcStats2(t *testing.T) {
+	native := nl.NativeEndian()
+	stats := nl.NewRtAttr(nl.TCA_STATS2, nil)
+	basic := make([]byte, 12)
+	native.PutUint64(basic[0:8], 1500)
+	native.PutUint32(basic[8:12], 10)
+	stats.AddRtAttr(nl.TCA_STATS_BASIC, basic)
+	queue := make([]byte, 20)
+	for i, v := range []uint32{1, 2, 3, 4, 5} {
+		native.PutUint32(queue[i*4:], v)
+	}
+	stats.AddRtAttr(nl.TCA_STATS_QUEUE, queue)
+
+	parsed, err := parseTcStats2(stats.Serialize()[unix.SizeofRtAttr:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := NewClassStatistics()
+	expected.Basic = &GnetStatsBasic{Bytes: 1500, Packets: 10}
+	expected.Queue = &GnetStatsQueue{Qlen: 1, Backlog: 2, Drops: 3, Requeues: 4, Overlimits: 5}
+	testClassStats(parsed, expected, t)
+}
+
+func TestValidateHtbTree(t *testing.T) {
+	root := NewHtb(QdiscAttrs{Handle: MakeHandle(1, 0), Parent: HANDLE_ROOT})
+	newClass := func(parent uint32, minor uint16, rate, ceil uint64) *HtbClass {
+		return &HtbClass{
+			ClassAttrs: ClassAttrs{Parent: parent, Handle: MakeHandle(1, minor)},
+			Rate:       rate,
+			Ceil:       ceil,
+		}
+	}
+	top := newClass(root.Handle, 1, 1000000, 1000000)
+	tests := []struct {
+		name    string
+		classes []*HtbClass
+		valid   bool
+	}{
+		{"valid", []*HtbClass{top, newClass(top.Handle, 10, 400000, 1000000), newClass(top.Handle, 20, 600000, 0)}, true},
+		{"rate above ceil", []*HtbClass{newClass(root.Handle, 1, 200000, 100000)}, false},
+		{"ceil above parent ceil", []*HtbClass{top, newClass(top.Handle, 10, 400000, 2000000)}, true},
+		{"inner class above max quantum", []*HtbClass{
+			newClass(root.Handle, 1, 125000000, 0), newClass(MakeHandle(1, 1), 10, 1000000, 0),
+		}, true},
+		{"child rates above parent ceil", []*HtbClass{top, newClass(top.Handle, 10, 600000, 0), newClass(top.Handle, 20, 600000, 0)}, false},
+		{"unknown parent", []*HtbClass{newClass(MakeHandle(1, 5), 10, 100000, 0)}, false},
+		{"wrong major", []*HtbClass{{ClassAttrs: ClassAttrs{Parent: root.Handle, Handle: MakeHandle(2, 1)}, Rate: 100000}}, false},
+		{"duplicate handle", []*HtbClass{top, top}, false},
+		{"loop", []*HtbClass{newClass(MakeHandle(1, 2), 1, 100000, 0), newClass(MakeHandle(1, 1), 2, 100000, 0)}, false},
+		{"quantum too small", []*HtbClass{newClass(root.Handle, 1, 5000, 0)}, false},
+	}
+	for _, test := range tests {
+		err := ValidateHtbTree(root, test.classes)
+		if test.valid && err != nil {
+			t.Fatalf("%s: unexpected error %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Fatalf("%s: expected an error", test.name)
+		}
+	}
+
+	// an explicit quantum overrides the one derived from r2q
+	small := newClass(root.Handle, 1, 5000, 0)
+	small.Quantum = HTB_MIN_QUANTUM
+	if err := ValidateHtbTree(root, []*HtbClass{small}); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestClassAddDel(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -128,6 +200,9 @@ func TestClassAddDel(t *testing.T) {
 	}
 
 	testClassStats(htb.ClassAttrs.Statistics, NewClassStatistics(), t)
+	if queue := htb.ClassAttrs.Statistics.Queue; queue.Qlen != 0 || queue.Backlog != 0 || queue.Requeues != 0 || queue.Overlimits != 0 {
+		t.Fatalf("Queue stats of a new class should be zero: %+v", queue)
+	}
 
 	qattrs := QdiscAttrs{
 		LinkIndex: link.Attrs().Index,
@@ -209,6 +284,73 @@ func TestClassAddDel(t *testing.T) {
 	}
 }
 
+func TestClassAddDelRate64(t *testing.T) {
+	minKernelRequired(t, 3, 13)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(0xffff, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	classattrs := ClassAttrs{
+		LinkIndex: link.Attrs().Index,
+		Parent:    MakeHandle(0xffff, 0),
+		Handle:    MakeHandle(0xffff, 2),
+	}
+	// 40 and 100 Gbit don't fit the 32 bit byte rates of TCA_HTB_PARMS
+	htbclassattrs := HtbClassAttrs{
+		Rate: 40000000000,
+		Ceil: 100000000000,
+	}
+	class := NewHtbClass(classattrs, htbclassattrs)
+	if class.Rate < 1<<32 || class.Ceil < 1<<32 {
+		t.Fatalf("Expected rates above 32 bits, got %d and %d", class.Rate, class.Ceil)
+	}
+	if err := ClassAdd(class); err != nil {
+		t.Fatal(err)
+	}
+	classes, err := SafeClassList(link, MakeHandle(0xffff, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 1 {
+		t.Fatal("Failed to add class")
+	}
+	htb, ok := classes[0].(*HtbClass)
+	if !ok {
+		t.Fatal("Class is the wrong type")
+	}
+	if htb.Rate != class.Rate {
+		t.Fatalf("Rate %d doesn't match %d", htb.Rate, class.Rate)
+	}
+	if htb.Ceil != class.Ceil {
+		t.Fatalf("Ceil %d doesn't match %d", htb.Ceil, class.Ceil)
+	}
+
+	if err := ClassDel(class); err != nil {
+		t.Fatal(err)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestHtbClassAddHtbClassChangeDel(t *testing.T) {
 	/**
 	This test first set up a interface ans set up a Htb qdisc
@@ -665,3 +807,160 @@ func TestClassHfsc(t *testing.T) {
 	}
 
 }
+
+func TestHtbClassDefaults(t *testing.T) {
+	attrs := ClassAttrs{
+		Parent: MakeHandle(0xffff, 0),
+		Handle: MakeHandle(0xffff, 2),
+	}
+	class := NewHtbClass(attrs, HtbClassAttrs{Rate: 1234000})
+
+	rate := uint64(1234000 / 8)
+	if class.Rate != rate {
+		t.Fatalf("Rate %d is expected but it actually was %d", rate, class.Rate)
+	}
+	if class.Ceil != class.Rate {
+		t.Fatal("Ceil should default to Rate")
+	}
+	burst := uint32(Xmittime(rate, uint32(float64(rate)/Hz()+1600)))
+	if class.Buffer != burst {
+		t.Fatalf("Buffer %d is expected but it actually was %d", burst, class.Buffer)
+	}
+	if class.Cbuffer != burst {
+		t.Fatalf("Cbuffer %d is expected but it actually was %d", burst, class.Cbuffer)
+	}
+	if quantum := HtbQuantum(rate, 10); class.Quantum != quantum {
+		t.Fatalf("Quantum %d is expected but it actually was %d", quantum, class.Quantum)
+	}
+
+	class = NewHtbClass(attrs, HtbClassAttrs{Rate: 1234000, Ceil: 2468000, Cbuffer: 1690})
+	if class.Ceil != 2468000/8 {
+		t.Fatal("Ceil doesn't match")
+	}
+	if cbuffer := uint32(Xmittime(class.Ceil, 1690)); class.Cbuffer != cbuffer {
+		t.Fatalf("Cbuffer %d is expected but it actually was %d", cbuffer, class.Cbuffer)
+	}
+}
+
+func TestHtbClassQuantum(t *testing.T) {
+	for _, q := range []struct {
+		rate     uint64
+		r2q      uint32
+		expected uint32
+	}{
+		{1000, 10, HTB_MIN_QUANTUM},
+		{125000, 10, 12500},
+		{125000000, 10, HTB_MAX_QUANTUM},
+		{125000, 0, 125000},
+	} {
+		if quantum := HtbQuantum(q.rate, q.r2q); quantum != q.expected {
+			t.Fatalf("HtbQuantum(%d, %d) = %d, expected %d", q.rate, q.r2q, quantum, q.expected)
+		}
+	}
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	qdisc.Rate2Quantum = 5
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	attrs := ClassAttrs{
+		LinkIndex: link.Attrs().Index,
+		Parent:    MakeHandle(1, 0),
+		Handle:    MakeHandle(1, 1),
+	}
+	class := NewHtbClass(attrs, HtbClassAttrs{Rate: 1000000, Quantum: HTB_MAX_QUANTUM + 1})
+	if err := ClassAdd(class); err == nil {
+		t.Fatal("Expected an error for a quantum above HTB_MAX_QUANTUM")
+	}
+	class.Quantum = HTB_MIN_QUANTUM - 1
+	if err := ClassAdd(class); err == nil {
+		t.Fatal("Expected an error for a quantum below HTB_MIN_QUANTUM")
+	}
+
+	// the quantum is derived from the r2q of the qdisc
+	class = NewHtbClass(attrs, HtbClassAttrs{Rate: 1000000, Rate2Quantum: qdisc.Rate2Quantum})
+	if err := ClassAdd(class); err != nil {
+		t.Fatal(err)
+	}
+	classes, err := ClassList(link, MakeHandle(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(classes) != 1 {
+		t.Fatalf("Expected 1 class, got %d", len(classes))
+	}
+	quantum := HtbQuantum(class.Rate, qdisc.Rate2Quantum)
+	htb, ok := classes[0].(*HtbClass)
+	if !ok || htb.Quantum != quantum {
+		t.Fatalf("Expected quantum %d, got %v", quantum, classes[0])
+	}
+
+	// explicit quantums in range are taken as they are
+	class.Quantum = 5000
+	if err := ClassChange(class); err != nil {
+		t.Fatal(err)
+	}
+	classes, err = ClassList(link, MakeHandle(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if htb, ok := classes[0].(*HtbClass); !ok || htb.Quantum != class.Quantum {
+		t.Fatalf("Expected quantum %d, got %v", class.Quantum, classes[0])
+	}
+}
+
+func TestHfscClassValidate(t *testing.T) {
+	attrs := ClassAttrs{LinkIndex: 1, Parent: MakeHandle(1, 0), Handle: MakeHandle(1, 1)}
+	for _, tc := range []struct {
+		name  string
+		setup func(c *HfscClass)
+		ok    bool
+	}{
+		{"ls only", func(c *HfscClass) { c.SetLS(0, 0, 5e6) }, true},
+		{"rt only", func(c *HfscClass) { c.SetRsc(0, 0, 2e6) }, true},
+		{"ls with ul", func(c *HfscClass) { c.SetLS(0, 0, 5e6); c.SetUL(0, 0, 10e6) }, true},
+		{"no curve", func(c *HfscClass) {}, false},
+		{"ul only", func(c *HfscClass) { c.SetUL(0, 0, 10e6) }, false},
+		{"rt with ul", func(c *HfscClass) { c.SetRsc(0, 0, 2e6); c.SetUL(0, 0, 10e6) }, false},
+		{"ul below ls", func(c *HfscClass) { c.SetLS(0, 0, 5e6); c.SetUL(0, 0, 1e6) }, false},
+	} {
+		c := NewHfscClass(attrs)
+		tc.setup(c)
+		err := c.validate(true)
+		if tc.ok && err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.name, err)
+		}
+		if !tc.ok && err == nil {
+			t.Fatalf("%s: expected an error", tc.name)
+		}
+	}
+
+	// a change may leave out the curves of the existing class
+	c := NewHfscClass(attrs)
+	if err := c.validate(false); err != nil {
+		t.Fatalf("unexpected error for a change without curves: %v", err)
+	}
+	c.SetUL(0, 0, 10e6)
+	if err := c.validate(false); err != nil {
+		t.Fatalf("unexpected error for a change of the upper limit: %v", err)
+	}
+	c.SetLS(0, 0, 20e6)
+	if err := c.validate(false); err == nil {
+		t.Fatal("expected an error for an upper limit below the link-sharing rate")
+	}
+
+	// ClassAdd rejects the class before sending it
+	if err := ClassAdd(NewHfscClass(attrs)); err == nil {
+		t.Fatal("Expected ClassAdd to reject a class without curves")
+	}
+}
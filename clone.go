@@ -0,0 +1,95 @@
+package netlink
+
+import "reflect"
+
+// CloneFilter returns a deep copy of filter, including its actions, so
+// the copy can be changed and added to another link without touching the
+// original. Unexported fields are copied shallowly.
+func CloneFilter(filter Filter) Filter {
+	if filter == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(filter)).Interface().(Filter)
+}
+
+// CloneAction returns a deep copy of action.
+func CloneAction(action Action) Action {
+	if action == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(action)).Interface().(Action)
+}
+
+// CloneQdisc returns a deep copy of qdisc.
+func CloneQdisc(qdisc Qdisc) Qdisc {
+	if qdisc == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(qdisc)).Interface().(Qdisc)
+}
+
+// CloneClass returns a deep copy of class.
+func CloneClass(class Class) Class {
+	if class == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(class)).Interface().(Class)
+}
+
+// deepCopy copies v and everything its pointers, slices, maps and
+// interfaces refer to. Unexported struct fields can't be set through
+// reflection, so they are copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Elem().Type())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,66 @@
+// +build linux
+
+package netlink
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCloneFilter(t *testing.T) {
+	filter := &U32{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: 1,
+			Parent:    MakeHandle(0xffff, 0),
+			Priority:  1,
+		},
+		Sel: &TcU32Sel{
+			Flags: TC_U32_TERMINAL,
+			Keys:  []TcU32Key{{Mask: 0xff, Val: 0x11}},
+		},
+		Actions: []Action{NewMirredAction(2)},
+	}
+
+	clone, ok := CloneFilter(filter).(*U32)
+	if !ok {
+		t.Fatalf("Clone is the wrong type %T", clone)
+	}
+	if !reflect.DeepEqual(clone, filter) {
+		t.Fatalf("Clone %+v does not match %+v", clone, filter)
+	}
+
+	clone.LinkIndex = 3
+	clone.Sel.Keys[0].Val = 0x22
+	clone.Actions[0].(*MirredAction).Ifindex = 4
+	if filter.LinkIndex != 1 || filter.Sel.Keys[0].Val != 0x11 || filter.Actions[0].(*MirredAction).Ifindex != 2 {
+		t.Fatalf("Changing the clone changed the original %+v", filter)
+	}
+
+	if CloneFilter(nil) != nil {
+		t.Fatal("Expected the clone of nil to be nil")
+	}
+}
+
+func TestCloneQdiscClass(t *testing.T) {
+	qdisc := NewTaprio(QdiscAttrs{LinkIndex: 1, Handle: MakeHandle(1, 0), Parent: HANDLE_ROOT})
+	qdisc.Schedule = []TaprioEntry{{Command: TAPRIO_CMD_SET_GATES, GateMask: 1, Interval: 300000}}
+	qclone := CloneQdisc(qdisc).(*Taprio)
+	if !reflect.DeepEqual(qclone, qdisc) {
+		t.Fatalf("Clone %+v does not match %+v", qclone, qdisc)
+	}
+	qclone.Schedule[0].GateMask = 2
+	if qdisc.Schedule[0].GateMask != 1 {
+		t.Fatal("Changing the clone changed the original schedule")
+	}
+
+	class := NewHfscClass(ClassAttrs{LinkIndex: 1, Parent: MakeHandle(1, 0), Handle: MakeHandle(1, 1)})
+	class.SetSC(1000, 10, 500)
+	cclone := CloneClass(class).(*HfscClass)
+	if !reflect.DeepEqual(cclone, class) {
+		t.Fatalf("Clone %+v does not match %+v", cclone, class)
+	}
+	cclone.SetSC(2000, 10, 1000)
+	if reflect.DeepEqual(cclone, class) {
+		t.Fatal("Changing the clone changed the original class")
+	}
+}
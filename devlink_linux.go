@@ -27,6 +27,26 @@ type DevlinkDevice struct {
 	Attrs      DevlinkDevAttrs
 }
 
+// DevlinkPort represents a devlink port and its attributes
+type DevlinkPort struct {
+	BusName        string
+	DeviceName     string
+	PortIndex      uint32
+	PortType       uint16 // one of the nl.DEVLINK_PORT_TYPE_* constants
+	NetdeviceName  string
+	NetdevIfIndex  uint32
+	RdmaDeviceName string
+	PortFlavour    uint16 // one of the nl.DEVLINK_PORT_FLAVOUR_* constants
+	PortNumber     uint32
+	// SplitCount is the number of ports a port was split into, it is only
+	// set on the port that was split.
+	SplitCount uint32
+	// SplitGroup and SplitSubportNumber are set on the ports created by a
+	// split and hold the index of the split port and the subport number.
+	SplitGroup         uint32
+	SplitSubportNumber uint32
+}
+
 func parseDevLinkDeviceList(msgs [][]byte) ([]*DevlinkDevice, error) {
 	devices := make([]*DevlinkDevice, 0, len(msgs))
 	for _, m := range msgs {
@@ -53,6 +73,30 @@ func eswitchStringToMode(modeName string) (uint16, error) {
 	}
 }
 
+func eswitchStringToInlineMode(modeName string) (uint8, error) {
+	switch modeName {
+	case "none":
+		return nl.DEVLINK_ESWITCH_INLINE_MODE_NONE, nil
+	case "link":
+		return nl.DEVLINK_ESWITCH_INLINE_MODE_LINK, nil
+	case "network":
+		return nl.DEVLINK_ESWITCH_INLINE_MODE_NETWORK, nil
+	case "transport":
+		return nl.DEVLINK_ESWITCH_INLINE_MODE_TRANSPORT, nil
+	}
+	return 0xff, fmt.Errorf("invalid eswitch inline mode")
+}
+
+func eswitchStringToEncapMode(modeName string) (uint8, error) {
+	switch modeName {
+	case "disable", "none":
+		return nl.DEVLINK_ESWITCH_ENCAP_MODE_NONE, nil
+	case "enable", "basic":
+		return nl.DEVLINK_ESWITCH_ENCAP_MODE_BASIC, nil
+	}
+	return 0xff, fmt.Errorf("invalid eswitch encap mode")
+}
+
 func parseEswitchMode(mode uint16) string {
 	var eswitchMode = map[uint16]string{
 		nl.DEVLINK_ESWITCH_MODE_LEGACY:    "legacy",
@@ -242,6 +286,33 @@ func DevLinkGetDeviceByName(Bus string, Device string) (*DevlinkDevice, error) {
 	return pkgHandle.DevLinkGetDeviceByName(Bus, Device)
 }
 
+// DevLinkGetEswitchMode provides the eswitch attributes of a devlink
+// device and nil error, otherwise returns an error code.
+// Equivalent to: `devlink dev eswitch show $dev`
+func (h *Handle) DevLinkGetEswitchMode(Bus string, Device string) (*DevlinkDevEswitchAttr, error) {
+	_, req, err := h.createCmdReq(nl.DEVLINK_CMD_ESWITCH_GET, Bus, Device)
+	if err != nil {
+		return nil, err
+	}
+
+	respmsg, err := req.Execute(unix.NETLINK_GENERIC, 0)
+	if err != nil {
+		return nil, err
+	}
+	dev, err := parseDevlinkDevice(respmsg)
+	if err != nil {
+		return nil, err
+	}
+	return &dev.Attrs.Eswitch, nil
+}
+
+// DevLinkGetEswitchMode provides the eswitch attributes of a devlink
+// device and nil error, otherwise returns an error code.
+// Equivalent to: `devlink dev eswitch show $dev`
+func DevLinkGetEswitchMode(Bus string, Device string) (*DevlinkDevEswitchAttr, error) {
+	return pkgHandle.DevLinkGetEswitchMode(Bus, Device)
+}
+
 // DevLinkSetEswitchMode sets eswitch mode if able to set successfully or
 // returns an error code.
 // Equivalent to: `devlink dev eswitch set $dev mode switchdev`
@@ -270,3 +341,170 @@ func (h *Handle) DevLinkSetEswitchMode(Dev *DevlinkDevice, NewMode string) error
 func DevLinkSetEswitchMode(Dev *DevlinkDevice, NewMode string) error {
 	return pkgHandle.DevLinkSetEswitchMode(Dev, NewMode)
 }
+
+// DevLinkSetEswitchInlineMode sets the minimal inline mode of the eswitch,
+// the headers the NIC needs to see in the descriptor to match packets
+// sent by VFs. The mode is one of none, link, network or transport.
+// Equivalent to: `devlink dev eswitch set $dev inline-mode $mode`
+func (h *Handle) DevLinkSetEswitchInlineMode(Dev *DevlinkDevice, NewMode string) error {
+	mode, err := eswitchStringToInlineMode(NewMode)
+	if err != nil {
+		return err
+	}
+
+	_, req, err := h.createCmdReq(nl.DEVLINK_CMD_ESWITCH_SET, Dev.BusName, Dev.DeviceName)
+	if err != nil {
+		return err
+	}
+
+	req.AddData(nl.NewRtAttr(nl.DEVLINK_ATTR_ESWITCH_INLINE_MODE, nl.Uint8Attr(mode)))
+
+	_, err = req.Execute(unix.NETLINK_GENERIC, 0)
+	return err
+}
+
+// DevLinkSetEswitchInlineMode sets the minimal inline mode of the eswitch,
+// the headers the NIC needs to see in the descriptor to match packets
+// sent by VFs. The mode is one of none, link, network or transport.
+// Equivalent to: `devlink dev eswitch set $dev inline-mode $mode`
+func DevLinkSetEswitchInlineMode(Dev *DevlinkDevice, NewMode string) error {
+	return pkgHandle.DevLinkSetEswitchInlineMode(Dev, NewMode)
+}
+
+// DevLinkSetEswitchEncapMode sets whether the eswitch offloads tunnel
+// encapsulation and decapsulation. The mode is disable or enable, basic
+// and none are accepted as aliases.
+// Equivalent to: `devlink dev eswitch set $dev encap-mode $mode`
+func (h *Handle) DevLinkSetEswitchEncapMode(Dev *DevlinkDevice, NewMode string) error {
+	mode, err := eswitchStringToEncapMode(NewMode)
+	if err != nil {
+		return err
+	}
+
+	_, req, err := h.createCmdReq(nl.DEVLINK_CMD_ESWITCH_SET, Dev.BusName, Dev.DeviceName)
+	if err != nil {
+		return err
+	}
+
+	req.AddData(nl.NewRtAttr(nl.DEVLINK_ATTR_ESWITCH_ENCAP_MODE, nl.Uint8Attr(mode)))
+
+	_, err = req.Execute(unix.NETLINK_GENERIC, 0)
+	return err
+}
+
+// DevLinkSetEswitchEncapMode sets whether the eswitch offloads tunnel
+// encapsulation and decapsulation. The mode is disable or enable, basic
+// and none are accepted as aliases.
+// Equivalent to: `devlink dev eswitch set $dev encap-mode $mode`
+func DevLinkSetEswitchEncapMode(Dev *DevlinkDevice, NewMode string) error {
+	return pkgHandle.DevLinkSetEswitchEncapMode(Dev, NewMode)
+}
+
+func (port *DevlinkPort) parseAttributes(attrs []syscall.NetlinkRouteAttr) error {
+	for _, a := range attrs {
+		switch a.Attr.Type {
+		case nl.DEVLINK_ATTR_BUS_NAME:
+			port.BusName = nl.BytesToString(a.Value)
+		case nl.DEVLINK_ATTR_DEV_NAME:
+			port.DeviceName = nl.BytesToString(a.Value)
+		case nl.DEVLINK_ATTR_PORT_INDEX:
+			port.PortIndex = native.Uint32(a.Value)
+		case nl.DEVLINK_ATTR_PORT_TYPE:
+			port.PortType = native.Uint16(a.Value)
+		case nl.DEVLINK_ATTR_PORT_NETDEV_NAME:
+			port.NetdeviceName = nl.BytesToString(a.Value)
+		case nl.DEVLINK_ATTR_PORT_NETDEV_IFINDEX:
+			port.NetdevIfIndex = native.Uint32(a.Value)
+		case nl.DEVLINK_ATTR_PORT_IBDEV_NAME:
+			port.RdmaDeviceName = nl.BytesToString(a.Value)
+		case nl.DEVLINK_ATTR_PORT_FLAVOUR:
+			port.PortFlavour = native.Uint16(a.Value)
+		case nl.DEVLINK_ATTR_PORT_NUMBER:
+			port.PortNumber = native.Uint32(a.Value)
+		case nl.DEVLINK_ATTR_PORT_SPLIT_COUNT:
+			port.SplitCount = native.Uint32(a.Value)
+		case nl.DEVLINK_ATTR_PORT_SPLIT_GROUP:
+			port.SplitGroup = native.Uint32(a.Value)
+		case nl.DEVLINK_ATTR_PORT_SPLIT_SUBPORT_NUMBER:
+			port.SplitSubportNumber = native.Uint32(a.Value)
+		}
+	}
+	return nil
+}
+
+func parseDevLinkAllPortList(msgs [][]byte) ([]*DevlinkPort, error) {
+	ports := make([]*DevlinkPort, 0, len(msgs))
+	for _, m := range msgs {
+		attrs, err := nl.ParseRouteAttr(m[nl.SizeofGenlmsg:])
+		if err != nil {
+			return nil, err
+		}
+		port := &DevlinkPort{}
+		if err = port.parseAttributes(attrs); err != nil {
+			return nil, err
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// DevLinkGetPortList provides a pointer to the ports of all devlink
+// devices and nil error, otherwise returns an error code.
+// Equivalent to: `devlink port show`
+func (h *Handle) DevLinkGetPortList() ([]*DevlinkPort, error) {
+	f, err := h.GenlFamilyGet(nl.GENL_DEVLINK_NAME)
+	if err != nil {
+		return nil, err
+	}
+	msg := &nl.Genlmsg{
+		Command: nl.DEVLINK_CMD_PORT_GET,
+		Version: nl.GENL_DEVLINK_VERSION,
+	}
+	req := h.newNetlinkRequest(int(f.ID),
+		unix.NLM_F_REQUEST|unix.NLM_F_ACK|unix.NLM_F_DUMP)
+	req.AddData(msg)
+	msgs, err := req.Execute(unix.NETLINK_GENERIC, 0)
+	if err != nil {
+		return nil, err
+	}
+	return parseDevLinkAllPortList(msgs)
+}
+
+// DevLinkGetPortList provides a pointer to the ports of all devlink
+// devices and nil error, otherwise returns an error code.
+// Equivalent to: `devlink port show`
+func DevLinkGetPortList() ([]*DevlinkPort, error) {
+	return pkgHandle.DevLinkGetPortList()
+}
+
+// DevLinkGetPortByIndex provides a pointer to the devlink port with the
+// given index on a device and nil error, otherwise returns an error code.
+// Equivalent to: `devlink port show $bus/$device/$port`
+func (h *Handle) DevLinkGetPortByIndex(Bus string, Device string, PortIndex uint32) (*DevlinkPort, error) {
+	_, req, err := h.createCmdReq(nl.DEVLINK_CMD_PORT_GET, Bus, Device)
+	if err != nil {
+		return nil, err
+	}
+
+	req.AddData(nl.NewRtAttr(nl.DEVLINK_ATTR_PORT_INDEX, nl.Uint32Attr(PortIndex)))
+
+	respmsg, err := req.Execute(unix.NETLINK_GENERIC, 0)
+	if err != nil {
+		return nil, err
+	}
+	ports, err := parseDevLinkAllPortList(respmsg)
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) != 1 {
+		return nil, fmt.Errorf("invalid response for DEVLINK_CMD_PORT_GET")
+	}
+	return ports[0], nil
+}
+
+// DevLinkGetPortByIndex provides a pointer to the devlink port with the
+// given index on a device and nil error, otherwise returns an error code.
+// Equivalent to: `devlink port show $bus/$device/$port`
+func DevLinkGetPortByIndex(Bus string, Device string, PortIndex uint32) (*DevlinkPort, error) {
+	return pkgHandle.DevLinkGetPortByIndex(Bus, Device, PortIndex)
+}
@@ -40,3 +40,49 @@ func TestDevLinkSetEswitchMode(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestDevLinkGetPortList(t *testing.T) {
+	minKernelRequired(t, 5, 4)
+	setUpNetlinkTestWithKModule(t, "devlink")
+	ports, err := DevLinkGetPortList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ports) == 0 {
+		t.Skip("No devlink ports to test with")
+	}
+	for _, port := range ports {
+		p, err := DevLinkGetPortByIndex(port.BusName, port.DeviceName, port.PortIndex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.PortFlavour != port.PortFlavour || p.NetdeviceName != port.NetdeviceName {
+			t.Fatalf("Port %s/%s/%d doesn't match the dumped port", p.BusName, p.DeviceName, p.PortIndex)
+		}
+	}
+}
+
+func TestDevLinkEswitchInlineEncapMode(t *testing.T) {
+	minKernelRequired(t, 4, 12)
+	setUpNetlinkTestWithKModule(t, "devlink")
+	dev, err := DevLinkGetDeviceByName("foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := DevLinkSetEswitchInlineMode(dev, "transport"); err != nil {
+		t.Fatal(err)
+	}
+	if err := DevLinkSetEswitchEncapMode(dev, "enable"); err != nil {
+		t.Fatal(err)
+	}
+	eswitch, err := DevLinkGetEswitchMode(dev.BusName, dev.DeviceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eswitch.InlineMode != "transport" || eswitch.EncapMode != "enable" {
+		t.Fatalf("Unexpected eswitch attributes %+v", eswitch)
+	}
+	if err := DevLinkSetEswitchInlineMode(dev, "bogus"); err == nil {
+		t.Fatal("Expected an error for an invalid inline mode")
+	}
+}
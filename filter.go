@@ -19,6 +19,12 @@ type FilterAttrs struct {
 	Parent    uint32
 	Priority  uint16 // lower is higher priority
 	Protocol  uint16 // unix.ETH_P_*
+	// SkipHw and SkipSw restrict the filter to software or hardware
+	// only. InHw is set on list when the filter is offloaded to hardware.
+	// They are supported by the u32, bpf and matchall filters.
+	SkipHw bool
+	SkipSw bool
+	InHw   bool
 }
 
 func (q FilterAttrs) String() string {
@@ -93,13 +99,17 @@ func (a TcPolAct) String() string {
 }
 
 type ActionAttrs struct {
-	Index   int
-	Capab   int
-	Action  TcAct
-	Refcnt  int
-	Bindcnt int
+	Index      int
+	Capab      int
+	Action     TcAct
+	Refcnt     int
+	Bindcnt    int
+	Statistics *ActionStatistics // read only
 }
 
+// ActionStatistics are the packet, byte and drop counters of an action.
+type ActionStatistics ClassStatistics
+
 func (q ActionAttrs) String() string {
 	return fmt.Sprintf("{Index: %d, Capab: %x, Action: %s, Refcnt: %d, Bindcnt: %d}", q.Index, q.Capab, q.Action.String(), q.Refcnt, q.Bindcnt)
 }
@@ -110,8 +120,20 @@ type Action interface {
 	Type() string
 }
 
+const (
+	GACT_PROB_NONE    = 0
+	GACT_PROB_NETRAND = 1 // random, one in PGactVal packets
+	GACT_PROB_DETERM  = 2 // deterministic, every PGactVal-th packet
+)
+
+// GenericAction is the gact action. If PGactProb is not GACT_PROB_NONE,
+// PGactAction is taken instead of Action for the packets selected by
+// PGactProb and PGactVal.
 type GenericAction struct {
 	ActionAttrs
+	PGactProb   uint16
+	PGactVal    uint16
+	PGactAction TcAct
 }
 
 func (action *GenericAction) Type() string {
@@ -211,12 +233,18 @@ const (
 	TCA_TUNNEL_KEY_UNSET TunnelKeyAct = 2 // unset tunnel key
 )
 
+// TunnelKeyAction sets or releases the tunnel metadata used by flow
+// based (external) tunnel devices. DestPort, Tos and Ttl are optional
+// and left to the tunnel device when zero.
 type TunnelKeyAction struct {
 	ActionAttrs
-	Action  TunnelKeyAct
-	SrcAddr net.IP
-	DstAddr net.IP
-	KeyID   uint32
+	Action   TunnelKeyAct
+	SrcAddr  net.IP
+	DstAddr  net.IP
+	KeyID    uint32
+	DestPort uint16
+	Tos      uint8
+	Ttl      uint8
 }
 
 func (action *TunnelKeyAction) Type() string {
@@ -235,12 +263,16 @@ func NewTunnelKeyAction() *TunnelKeyAction {
 	}
 }
 
+// SkbEditAction edits the metadata of a packet. Only the fields that are
+// set are changed. Mask restricts which bits of the packet mark are set
+// by Mark.
 type SkbEditAction struct {
 	ActionAttrs
 	QueueMapping *uint16
 	PType        *uint16
 	Priority     *uint32
 	Mark         *uint32
+	Mask         *uint32
 }
 
 func (action *SkbEditAction) Type() string {
@@ -259,9 +291,71 @@ func NewSkbEditAction() *SkbEditAction {
 	}
 }
 
+type VlanAct int32
+
+const (
+	TCA_VLAN_ACT_POP    VlanAct = 1 // pop the outer vlan tag
+	TCA_VLAN_ACT_PUSH   VlanAct = 2 // push a vlan tag
+	TCA_VLAN_ACT_MODIFY VlanAct = 3 // modify the outer vlan tag
+)
+
+// VlanAction pops, pushes or modifies the outer vlan tag of a packet.
+// VlanID, Priority and Protocol (unix.ETH_P_8021Q by default) are only
+// used for push and modify.
+type VlanAction struct {
+	ActionAttrs
+	Mode     VlanAct
+	VlanID   uint16
+	Priority uint8
+	Protocol uint16
+}
+
+func (action *VlanAction) Type() string {
+	return "vlan"
+}
+
+func (action *VlanAction) Attrs() *ActionAttrs {
+	return &action.ActionAttrs
+}
+
+func NewVlanAction() *VlanAction {
+	return &VlanAction{
+		ActionAttrs: ActionAttrs{
+			Action: TC_ACT_PIPE,
+		},
+	}
+}
+
+// SampleAction samples one in every Rate packets to the psample group
+// Group. Sampled packets are truncated to TruncSize bytes if it is set.
+type SampleAction struct {
+	ActionAttrs
+	Rate      uint32
+	Group     uint32
+	TruncSize uint32
+}
+
+func (action *SampleAction) Type() string {
+	return "sample"
+}
+
+func (action *SampleAction) Attrs() *ActionAttrs {
+	return &action.ActionAttrs
+}
+
+func NewSampleAction() *SampleAction {
+	return &SampleAction{
+		ActionAttrs: ActionAttrs{
+			Action: TC_ACT_PIPE,
+		},
+	}
+}
+
 // MatchAll filters match all packets
 type MatchAll struct {
 	FilterAttrs
+	// ClassId is the class matching packets are sent to. It is optional,
+	// a filter without one only runs its actions, e.g. on clsact hooks.
 	ClassId uint32
 	Actions []Action
 }
@@ -274,6 +368,177 @@ func (filter *MatchAll) Type() string {
 	return "matchall"
 }
 
+// Basic filters classify packets matching an optional ematch tree. Without
+// Ematches every packet matches.
+type Basic struct {
+	FilterAttrs
+	ClassId  uint32
+	Ematches []Ematch
+	Actions  []Action
+}
+
+func (filter *Basic) Attrs() *FilterAttrs {
+	return &filter.FilterAttrs
+}
+
+func (filter *Basic) Type() string {
+	return "basic"
+}
+
+// EmatchRelation combines an ematch with the ematch following it.
+type EmatchRelation uint16
+
+const (
+	EMATCH_REL_END EmatchRelation = 0
+	EMATCH_REL_AND EmatchRelation = 1
+	EMATCH_REL_OR  EmatchRelation = 2
+)
+
+// EmatchLayer is the header the offset of a cmp ematch is relative to.
+type EmatchLayer uint8
+
+const (
+	EMATCH_LAYER_LINK      EmatchLayer = 0
+	EMATCH_LAYER_NETWORK   EmatchLayer = 1
+	EMATCH_LAYER_TRANSPORT EmatchLayer = 2
+)
+
+// EmatchOperand is the comparison done by a cmp ematch.
+type EmatchOperand uint8
+
+const (
+	EMATCH_OPND_EQ EmatchOperand = 0
+	EMATCH_OPND_GT EmatchOperand = 1
+	EMATCH_OPND_LT EmatchOperand = 2
+)
+
+// EmatchAttrs holds the fields shared by all ematches. The ematches of a
+// tree are evaluated in order, each combined with the next by its Relation,
+// until one with EMATCH_REL_END. Invert negates the result of the match.
+type EmatchAttrs struct {
+	Relation EmatchRelation
+	Invert   bool
+}
+
+// Ematch is an extended match used by the basic filter.
+type Ematch interface {
+	Attrs() *EmatchAttrs
+	Type() string
+}
+
+// U32Ematch matches the 32 bits at Off bytes from the network header
+// against Val under Mask. Mask and Val are in host byte order.
+type U32Ematch struct {
+	EmatchAttrs
+	Mask uint32
+	Val  uint32
+	Off  int32
+}
+
+func (ematch *U32Ematch) Attrs() *EmatchAttrs {
+	return &ematch.EmatchAttrs
+}
+
+func (ematch *U32Ematch) Type() string {
+	return "u32"
+}
+
+// CmpEmatch compares the Align bytes (1, 2 or 4) at Off from the start of
+// Layer, masked with Mask, against Val. With Trans the data is converted
+// from network byte order before the comparison.
+type CmpEmatch struct {
+	EmatchAttrs
+	Val     uint32
+	Mask    uint32
+	Off     uint16
+	Align   uint8
+	Layer   EmatchLayer
+	Operand EmatchOperand
+	Trans   bool
+}
+
+func (ematch *CmpEmatch) Attrs() *EmatchAttrs {
+	return &ematch.EmatchAttrs
+}
+
+func (ematch *CmpEmatch) Type() string {
+	return "cmp"
+}
+
+// EmatchMetaId is the packet metadata compared by a meta ematch.
+type EmatchMetaId uint16
+
+const (
+	EMATCH_META_ID_RANDOM    EmatchMetaId = 1
+	EMATCH_META_ID_LOADAVG_0 EmatchMetaId = 2
+	EMATCH_META_ID_LOADAVG_1 EmatchMetaId = 3
+	EMATCH_META_ID_LOADAVG_2 EmatchMetaId = 4
+	EMATCH_META_ID_DEV       EmatchMetaId = 5 // index of the input link
+	EMATCH_META_ID_PRIORITY  EmatchMetaId = 6
+	EMATCH_META_ID_PROTOCOL  EmatchMetaId = 7
+	EMATCH_META_ID_PKTTYPE   EmatchMetaId = 8
+	EMATCH_META_ID_PKTLEN    EmatchMetaId = 9
+	EMATCH_META_ID_DATALEN   EmatchMetaId = 10
+	EMATCH_META_ID_MACLEN    EmatchMetaId = 11
+	EMATCH_META_ID_NFMARK    EmatchMetaId = 12 // the mark of the packet
+	EMATCH_META_ID_TCINDEX   EmatchMetaId = 13
+	EMATCH_META_ID_RTCLASSID EmatchMetaId = 14
+	EMATCH_META_ID_RTIIF     EmatchMetaId = 15
+	EMATCH_META_ID_VLAN_TAG  EmatchMetaId = 46
+	EMATCH_META_ID_RXHASH    EmatchMetaId = 47
+)
+
+// MetaEmatch compares the metadata Id of the packet, shifted right by
+// Shift and masked with Mask unless it is 0, against Val. Only the integer
+// metadata is supported, other meta ematches are read as GenericEmatch.
+type MetaEmatch struct {
+	EmatchAttrs
+	Id      EmatchMetaId
+	Operand EmatchOperand
+	Shift   uint8
+	Mask    uint32
+	Val     uint32
+}
+
+func (ematch *MetaEmatch) Attrs() *EmatchAttrs {
+	return &ematch.EmatchAttrs
+}
+
+func (ematch *MetaEmatch) Type() string {
+	return "meta"
+}
+
+// ContainerEmatch evaluates the sub tree starting at the ematch with index
+// Ref, which must come after the container. It allows expressions such as
+// "a AND (b OR c)".
+type ContainerEmatch struct {
+	EmatchAttrs
+	Ref uint32
+}
+
+func (ematch *ContainerEmatch) Attrs() *EmatchAttrs {
+	return &ematch.EmatchAttrs
+}
+
+func (ematch *ContainerEmatch) Type() string {
+	return "container"
+}
+
+// GenericEmatch carries an ematch of a kind this package does not decode.
+type GenericEmatch struct {
+	EmatchAttrs
+	Kind uint16
+	Data []byte
+}
+
+func (ematch *GenericEmatch) Attrs() *EmatchAttrs {
+	return &ematch.EmatchAttrs
+}
+
+func (ematch *GenericEmatch) Type() string {
+	return "generic"
+}
+
 type FilterFwAttrs struct {
 	ClassId   uint32
 	InDev     string
@@ -290,6 +555,9 @@ type FilterFwAttrs struct {
 	LinkLayer int
 }
 
+// BpfFilter attaches the cls_bpf program Fd as a filter. With DirectAction
+// the return code of the program is used as the tc action. Id and Tag
+// identify the loaded program and are only set on list.
 type BpfFilter struct {
 	FilterAttrs
 	ClassId      uint32
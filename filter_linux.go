@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sort"
 	"syscall"
 
 	"github.com/vishvananda/netlink/nl"
@@ -32,11 +33,17 @@ type TcU32Sel = nl.TcU32Sel
 type TcU32Key = nl.TcU32Key
 
 // U32 filters on many packet related properties
+//
+// A filter with Divisor set creates a hash table with Divisor buckets. Hash
+// places a filter in a hash table bucket, see U32HashHandle, and Link sends
+// the packets matching a filter on to the hash table with that handle, in
+// the bucket selected by the hash key of the selector, see SetHashKey.
 type U32 struct {
 	FilterAttrs
 	ClassId    uint32
 	Divisor    uint32 // Divisor MUST be power of 2.
 	Hash       uint32
+	Link       uint32
 	RedirIndex int
 	Sel        *TcU32Sel
 	Actions    []Action
@@ -46,6 +53,40 @@ func (filter *U32) Attrs() *FilterAttrs {
 	return &filter.FilterAttrs
 }
 
+// SetHashKey selects the bucket of the linked hash table with the bits in
+// mask of the 32 bit word at offset off from the network header, e.g. mask
+// 0xff at 16 hashes IPv4 packets on the last byte of the destination.
+// Equivalent to: `hashkey mask $mask at $off`
+func (filter *U32) SetHashKey(mask uint32, off int16) {
+	if filter.Sel == nil {
+		// match all without ending the classification at this filter
+		filter.Sel = &TcU32Sel{Keys: []TcU32Key{{}}}
+	}
+	filter.Sel.Hmask = mask
+	filter.Sel.Hoff = off
+}
+
+// U32HashBucket returns the bucket of a hash table with divisor buckets
+// that a packet with key at the offset of the hash key lands in. It is
+// used to place filters in the right bucket like `tc ... sample` does.
+func U32HashBucket(key, mask, divisor uint32) uint32 {
+	if mask == 0 || divisor == 0 {
+		return 0
+	}
+	shift := uint(0)
+	for mask&(1<<shift) == 0 {
+		shift++
+	}
+	return ((key & mask) >> shift) & (divisor - 1)
+}
+
+// U32HashHandle returns the Hash of a filter placed in bucket of the hash
+// table with handle ht.
+// Equivalent to: `ht $ht:$bucket:`
+func U32HashHandle(ht, bucket uint32) uint32 {
+	return ht&0xfff00000 | (bucket&0xff)<<12
+}
+
 func (filter *U32) Type() string {
 	return "u32"
 }
@@ -168,7 +209,45 @@ func (h *Handle) FilterReplace(filter Filter) error {
 	return h.filterModify(filter, unix.NLM_F_CREATE)
 }
 
+// FilterAddAndGet will add a filter to the system and return the filter as
+// echoed by the kernel. The handle allocated by the kernel for a filter
+// added with handle 0 is also set in the attributes of the passed filter.
+// Equivalent to: `tc -echo filter add $filter`
+func FilterAddAndGet(filter Filter) (Filter, error) {
+	return pkgHandle.FilterAddAndGet(filter)
+}
+
+// FilterAddAndGet will add a filter to the system and return the filter as
+// echoed by the kernel. The handle allocated by the kernel for a filter
+// added with handle 0 is also set in the attributes of the passed filter.
+// Equivalent to: `tc -echo filter add $filter`
+func (h *Handle) FilterAddAndGet(filter Filter) (Filter, error) {
+	msgs, err := h.filterExecute(filter, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ECHO)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("kernel did not echo the added filter")
+	}
+	res, _, err := parseFilterMsg(msgs[0])
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, fmt.Errorf("kernel echoed a filter without a kind")
+	}
+	filter.Attrs().Handle = res.Attrs().Handle
+	return res, nil
+}
+
 func (h *Handle) filterModify(filter Filter, flags int) error {
+	_, err := h.filterExecute(filter, flags)
+	return err
+}
+
+// filterExecute sends a filter request and returns the RTM_NEWTFILTER
+// messages echoed by the kernel when flags has NLM_F_ECHO set.
+func (h *Handle) filterExecute(filter Filter, flags int) ([][]byte, error) {
 	native = nl.NativeEndian()
 	req := h.newNetlinkRequest(unix.RTM_NEWTFILTER, flags|unix.NLM_F_ACK)
 	base := filter.Attrs()
@@ -193,6 +272,10 @@ func (h *Handle) filterModify(filter Filter, flags int) error {
 				Nkeys: 1,
 				Flags: nl.TC_U32_TERMINAL,
 			}
+			if filter.Link != 0 {
+				// continue in the linked hash table
+				sel.Flags = 0
+			}
 			sel.Keys = append(sel.Keys, nl.TcU32Key{})
 		}
 
@@ -219,20 +302,26 @@ func (h *Handle) filterModify(filter Filter, flags int) error {
 		}
 		if filter.Divisor != 0 {
 			if (filter.Divisor-1)&filter.Divisor != 0 {
-				return fmt.Errorf("illegal divisor %d. Must be a power of 2", filter.Divisor)
+				return nil, fmt.Errorf("illegal divisor %d. Must be a power of 2", filter.Divisor)
 			}
 			options.AddRtAttr(nl.TCA_U32_DIVISOR, nl.Uint32Attr(filter.Divisor))
 		}
 		if filter.Hash != 0 {
 			options.AddRtAttr(nl.TCA_U32_HASH, nl.Uint32Attr(filter.Hash))
 		}
+		if filter.Link != 0 {
+			options.AddRtAttr(nl.TCA_U32_LINK, nl.Uint32Attr(filter.Link))
+		}
+		if clsFlags := filter.clsFlags(); clsFlags != 0 {
+			options.AddRtAttr(nl.TCA_U32_FLAGS, nl.Uint32Attr(clsFlags))
+		}
 		actionsAttr := options.AddRtAttr(nl.TCA_U32_ACT, nil)
 		// backwards compatibility
 		if filter.RedirIndex != 0 {
 			filter.Actions = append([]Action{NewMirredAction(filter.RedirIndex)}, filter.Actions...)
 		}
 		if err := EncodeActions(actionsAttr, filter.Actions); err != nil {
-			return err
+			return nil, err
 		}
 	case *Fw:
 		if filter.Mask != 0 {
@@ -276,24 +365,52 @@ func (h *Handle) filterModify(filter Filter, flags int) error {
 			bpfFlags |= nl.TCA_BPF_FLAG_ACT_DIRECT
 		}
 		options.AddRtAttr(nl.TCA_BPF_FLAGS, nl.Uint32Attr(bpfFlags))
+		if clsFlags := filter.clsFlags(); clsFlags != 0 {
+			options.AddRtAttr(nl.TCA_BPF_FLAGS_GEN, nl.Uint32Attr(clsFlags))
+		}
 	case *MatchAll:
 		actionsAttr := options.AddRtAttr(nl.TCA_MATCHALL_ACT, nil)
 		if err := EncodeActions(actionsAttr, filter.Actions); err != nil {
-			return err
+			return nil, err
 		}
 		if filter.ClassId != 0 {
 			options.AddRtAttr(nl.TCA_MATCHALL_CLASSID, nl.Uint32Attr(filter.ClassId))
 		}
+		if clsFlags := filter.clsFlags(); clsFlags != 0 {
+			options.AddRtAttr(nl.TCA_MATCHALL_FLAGS, nl.Uint32Attr(clsFlags))
+		}
+	case *Basic:
+		if filter.ClassId != 0 {
+			options.AddRtAttr(nl.TCA_BASIC_CLASSID, nl.Uint32Attr(filter.ClassId))
+		}
+		if len(filter.Ematches) > 0 {
+			ematchesAttr := options.AddRtAttr(nl.TCA_BASIC_EMATCHES, nil)
+			if err := encodeEmatches(ematchesAttr, filter.Ematches); err != nil {
+				return nil, err
+			}
+		}
+		if len(filter.Actions) > 0 {
+			actionsAttr := options.AddRtAttr(nl.TCA_BASIC_ACT, nil)
+			if err := EncodeActions(actionsAttr, filter.Actions); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	req.AddData(options)
-	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
-	return err
+
+	var resType uint16
+	if flags&unix.NLM_F_ECHO != 0 {
+		resType = unix.RTM_NEWTFILTER
+	}
+	return req.Execute(unix.NETLINK_ROUTE, resType)
 }
 
 // FilterList gets a list of filters in the system.
 // Equivalent to: `tc filter show`.
 // Generally returns nothing if link and parent are not specified.
+// Filters are sorted by priority and then by handle. Filters added
+// with a zero handle are given a unique handle by the kernel.
 func FilterList(link Link, parent uint32) ([]Filter, error) {
 	return pkgHandle.FilterList(link, parent)
 }
@@ -301,6 +418,8 @@ func FilterList(link Link, parent uint32) ([]Filter, error) {
 // FilterList gets a list of filters in the system.
 // Equivalent to: `tc filter show`.
 // Generally returns nothing if link and parent are not specified.
+// Filters are sorted by priority and then by handle. Filters added
+// with a zero handle are given a unique handle by the kernel.
 func (h *Handle) FilterList(link Link, parent uint32) ([]Filter, error) {
 	req := h.newNetlinkRequest(unix.RTM_GETTFILTER, unix.NLM_F_DUMP)
 	msg := &nl.TcMsg{
@@ -321,79 +440,109 @@ func (h *Handle) FilterList(link Link, parent uint32) ([]Filter, error) {
 
 	var res []Filter
 	for _, m := range msgs {
-		msg := nl.DeserializeTcMsg(m)
-
-		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+		filter, detailed, err := parseFilterMsg(m)
 		if err != nil {
 			return nil, err
 		}
+		// only return the detailed version of the filter
+		if detailed {
+			res = append(res, filter)
+		}
+	}
 
-		base := FilterAttrs{
-			LinkIndex: int(msg.Ifindex),
-			Handle:    msg.Handle,
-			Parent:    msg.Parent,
+	sort.SliceStable(res, func(i, j int) bool {
+		a, b := res[i].Attrs(), res[j].Attrs()
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
 		}
-		base.Priority, base.Protocol = MajorMinor(msg.Info)
-		base.Protocol = nl.Swap16(base.Protocol)
+		return a.Handle < b.Handle
+	})
+	return res, nil
+}
 
-		var filter Filter
-		filterType := ""
-		detailed := false
-		for _, attr := range attrs {
-			switch attr.Attr.Type {
-			case nl.TCA_KIND:
-				filterType = string(attr.Value[:len(attr.Value)-1])
-				switch filterType {
-				case "u32":
-					filter = &U32{}
-				case "fw":
-					filter = &Fw{}
-				case "bpf":
-					filter = &BpfFilter{}
-				case "matchall":
-					filter = &MatchAll{}
-				default:
-					filter = &GenericFilter{FilterType: filterType}
+// parseFilterMsg decodes a RTM_NEWTFILTER message into a Filter. Filters
+// that are not detailed, such as u32 hash tables, only carry their kind.
+// filterTypes maps the kind of a filter to a constructor of its type. Other
+// kinds are decoded as a GenericFilter.
+var filterTypes = map[string]func() Filter{
+	"u32":      func() Filter { return &U32{} },
+	"fw":       func() Filter { return &Fw{} },
+	"bpf":      func() Filter { return &BpfFilter{} },
+	"matchall": func() Filter { return &MatchAll{} },
+	"basic":    func() Filter { return &Basic{} },
+}
+
+func newFilterOfType(kind string) Filter {
+	if newFilter, ok := filterTypes[kind]; ok {
+		return newFilter()
+	}
+	return &GenericFilter{FilterType: kind}
+}
+
+func parseFilterMsg(m []byte) (Filter, bool, error) {
+	msg := nl.DeserializeTcMsg(m)
+
+	attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+	if err != nil {
+		return nil, false, err
+	}
+
+	base := FilterAttrs{
+		LinkIndex: int(msg.Ifindex),
+		Handle:    msg.Handle,
+		Parent:    msg.Parent,
+	}
+	base.Priority, base.Protocol = MajorMinor(msg.Info)
+	base.Protocol = nl.Swap16(base.Protocol)
+
+	var filter Filter
+	filterType := ""
+	detailed := false
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.TCA_KIND:
+			filterType = string(attr.Value[:len(attr.Value)-1])
+			filter = newFilterOfType(filterType)
+		case nl.TCA_OPTIONS:
+			data, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, false, err
+			}
+			switch filterType {
+			case "u32":
+				detailed, err = parseU32Data(filter, data)
+				if err != nil {
+					return nil, false, err
 				}
-			case nl.TCA_OPTIONS:
-				data, err := nl.ParseRouteAttr(attr.Value)
+			case "fw":
+				detailed, err = parseFwData(filter, data)
 				if err != nil {
-					return nil, err
+					return nil, false, err
 				}
-				switch filterType {
-				case "u32":
-					detailed, err = parseU32Data(filter, data)
-					if err != nil {
-						return nil, err
-					}
-				case "fw":
-					detailed, err = parseFwData(filter, data)
-					if err != nil {
-						return nil, err
-					}
-				case "bpf":
-					detailed, err = parseBpfData(filter, data)
-					if err != nil {
-						return nil, err
-					}
-				case "matchall":
-					detailed, err = parseMatchAllData(filter, data)
-					if err != nil {
-						return nil, err
-					}
-				default:
-					detailed = true
+			case "bpf":
+				detailed, err = parseBpfData(filter, data)
+				if err != nil {
+					return nil, false, err
 				}
+			case "matchall":
+				detailed, err = parseMatchAllData(filter, data)
+				if err != nil {
+					return nil, false, err
+				}
+			case "basic":
+				detailed, err = parseBasicData(filter, data)
+				if err != nil {
+					return nil, false, err
+				}
+			default:
+				detailed = true
 			}
 		}
-		// only return the detailed version of the filter
-		if detailed {
-			*filter.Attrs() = base
-			res = append(res, filter)
-		}
 	}
-
-	return res, nil
+	if filter != nil {
+		*filter.Attrs() = base
+	}
+	return filter, detailed, nil
 }
 
 func toTcGen(attrs *ActionAttrs, tcgen *nl.TcGen) {
@@ -456,6 +605,15 @@ func EncodeActions(attr *nl.RtAttr, actions []Action) error {
 				} else {
 					return fmt.Errorf("invalid dst addr %s for tunnel_key action", action.DstAddr)
 				}
+				if action.DestPort != 0 {
+					aopts.AddRtAttr(nl.TCA_TUNNEL_KEY_ENC_DST_PORT, htons(action.DestPort))
+				}
+				if action.Tos != 0 {
+					aopts.AddRtAttr(nl.TCA_TUNNEL_KEY_ENC_TOS, nl.Uint8Attr(action.Tos))
+				}
+				if action.Ttl != 0 {
+					aopts.AddRtAttr(nl.TCA_TUNNEL_KEY_ENC_TTL, nl.Uint8Attr(action.Ttl))
+				}
 			}
 		case *SkbEditAction:
 			table := attr.AddRtAttr(tabIndex, nil)
@@ -477,6 +635,54 @@ func EncodeActions(attr *nl.RtAttr, actions []Action) error {
 			if action.Mark != nil {
 				aopts.AddRtAttr(nl.TCA_SKBEDIT_MARK, nl.Uint32Attr(*action.Mark))
 			}
+			if action.Mask != nil {
+				if action.Mark == nil {
+					return fmt.Errorf("skbedit mask requires a mark")
+				}
+				aopts.AddRtAttr(nl.TCA_SKBEDIT_MASK, nl.Uint32Attr(*action.Mask))
+			}
+		case *VlanAction:
+			table := attr.AddRtAttr(tabIndex, nil)
+			tabIndex++
+			table.AddRtAttr(nl.TCA_ACT_KIND, nl.ZeroTerminated("vlan"))
+			aopts := table.AddRtAttr(nl.TCA_ACT_OPTIONS, nil)
+			vlan := nl.TcVlan{
+				VAction: int32(action.Mode),
+			}
+			toTcGen(action.Attrs(), &vlan.TcGen)
+			aopts.AddRtAttr(nl.TCA_VLAN_PARMS, vlan.Serialize())
+			switch action.Mode {
+			case TCA_VLAN_ACT_PUSH, TCA_VLAN_ACT_MODIFY:
+				if action.VlanID > 4095 {
+					return fmt.Errorf("invalid vlan id %d for vlan action", action.VlanID)
+				}
+				aopts.AddRtAttr(nl.TCA_VLAN_PUSH_VLAN_ID, nl.Uint16Attr(action.VlanID))
+				if action.Protocol != 0 {
+					aopts.AddRtAttr(nl.TCA_VLAN_PUSH_VLAN_PROTOCOL, htons(action.Protocol))
+				}
+				if action.Priority != 0 {
+					aopts.AddRtAttr(nl.TCA_VLAN_PUSH_VLAN_PRIORITY, nl.Uint8Attr(action.Priority))
+				}
+			case TCA_VLAN_ACT_POP:
+			default:
+				return fmt.Errorf("invalid mode %d for vlan action", action.Mode)
+			}
+		case *SampleAction:
+			if action.Rate == 0 {
+				return fmt.Errorf("sample action requires a non-zero rate")
+			}
+			table := attr.AddRtAttr(tabIndex, nil)
+			tabIndex++
+			table.AddRtAttr(nl.TCA_ACT_KIND, nl.ZeroTerminated("sample"))
+			aopts := table.AddRtAttr(nl.TCA_ACT_OPTIONS, nil)
+			gen := nl.TcGen{}
+			toTcGen(action.Attrs(), &gen)
+			aopts.AddRtAttr(nl.TCA_SAMPLE_PARMS, gen.Serialize())
+			aopts.AddRtAttr(nl.TCA_SAMPLE_RATE, nl.Uint32Attr(action.Rate))
+			aopts.AddRtAttr(nl.TCA_SAMPLE_PSAMPLE_GROUP, nl.Uint32Attr(action.Group))
+			if action.TruncSize != 0 {
+				aopts.AddRtAttr(nl.TCA_SAMPLE_TRUNC_SIZE, nl.Uint32Attr(action.TruncSize))
+			}
 		case *ConnmarkAction:
 			table := attr.AddRtAttr(tabIndex, nil)
 			tabIndex++
@@ -505,16 +711,52 @@ func EncodeActions(attr *nl.RtAttr, actions []Action) error {
 			gen := nl.TcGen{}
 			toTcGen(action.Attrs(), &gen)
 			aopts.AddRtAttr(nl.TCA_GACT_PARMS, gen.Serialize())
+			if action.PGactProb != GACT_PROB_NONE {
+				prob := nl.TcGactP{
+					Ptype:   action.PGactProb,
+					Pval:    action.PGactVal,
+					Paction: int32(action.PGactAction),
+				}
+				aopts.AddRtAttr(nl.TCA_GACT_PROB, prob.Serialize())
+			}
 		}
 	}
 	return nil
 }
 
+// actionTypes maps the kind of an action to a constructor of its type.
+// Actions of other kinds are skipped when parsing.
+var actionTypes = map[string]func() Action{
+	"mirred":     func() Action { return &MirredAction{} },
+	"bpf":        func() Action { return &BpfAction{} },
+	"connmark":   func() Action { return &ConnmarkAction{} },
+	"gact":       func() Action { return &GenericAction{} },
+	"tunnel_key": func() Action { return &TunnelKeyAction{} },
+	"skbedit":    func() Action { return &SkbEditAction{} },
+	"sample":     func() Action { return &SampleAction{} },
+	"vlan":       func() Action { return &VlanAction{} },
+}
+
+// newActionOfType returns an empty action of the given kind, or of the
+// type reported by Action.Type().
+func newActionOfType(kind string) (Action, error) {
+	// GenericAction reports its type as generic, the kernel kind is gact
+	if kind == "generic" {
+		kind = "gact"
+	}
+	newAction, ok := actionTypes[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown action type %s", kind)
+	}
+	return newAction(), nil
+}
+
 func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 	var actions []Action
 	for _, table := range tables {
 		var action Action
 		var actionType string
+		var statistics *ActionStatistics
 		aattrs, err := nl.ParseRouteAttr(table.Value)
 		if err != nil {
 			return nil, err
@@ -524,23 +766,18 @@ func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 			switch aattr.Attr.Type {
 			case nl.TCA_KIND:
 				actionType = string(aattr.Value[:len(aattr.Value)-1])
-				// only parse if the action is mirred or bpf
-				switch actionType {
-				case "mirred":
-					action = &MirredAction{}
-				case "bpf":
-					action = &BpfAction{}
-				case "connmark":
-					action = &ConnmarkAction{}
-				case "gact":
-					action = &GenericAction{}
-				case "tunnel_key":
-					action = &TunnelKeyAction{}
-				case "skbedit":
-					action = &SkbEditAction{}
-				default:
+				// only parse the actions with a type of their own
+				newAction, ok := actionTypes[actionType]
+				if !ok {
 					break nextattr
 				}
+				action = newAction()
+			case nl.TCA_ACT_STATS:
+				stats, err := parseTcStats2(aattr.Value)
+				if err != nil {
+					return nil, err
+				}
+				statistics = (*ActionStatistics)(stats)
 			case nl.TCA_OPTIONS:
 				adata, err := nl.ParseRouteAttr(aattr.Value)
 				if err != nil {
@@ -566,12 +803,16 @@ func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 							action.(*TunnelKeyAction).Action = TunnelKeyAct(tun.Action)
 						case nl.TCA_TUNNEL_KEY_ENC_KEY_ID:
 							action.(*TunnelKeyAction).KeyID = networkOrder.Uint32(adatum.Value[0:4])
-						case nl.TCA_TUNNEL_KEY_ENC_IPV6_SRC:
-						case nl.TCA_TUNNEL_KEY_ENC_IPV4_SRC:
+						case nl.TCA_TUNNEL_KEY_ENC_IPV6_SRC, nl.TCA_TUNNEL_KEY_ENC_IPV4_SRC:
 							action.(*TunnelKeyAction).SrcAddr = net.IP(adatum.Value[:])
-						case nl.TCA_TUNNEL_KEY_ENC_IPV6_DST:
-						case nl.TCA_TUNNEL_KEY_ENC_IPV4_DST:
+						case nl.TCA_TUNNEL_KEY_ENC_IPV6_DST, nl.TCA_TUNNEL_KEY_ENC_IPV4_DST:
 							action.(*TunnelKeyAction).DstAddr = net.IP(adatum.Value[:])
+						case nl.TCA_TUNNEL_KEY_ENC_DST_PORT:
+							action.(*TunnelKeyAction).DestPort = ntohs(adatum.Value[0:2])
+						case nl.TCA_TUNNEL_KEY_ENC_TOS:
+							action.(*TunnelKeyAction).Tos = adatum.Value[0]
+						case nl.TCA_TUNNEL_KEY_ENC_TTL:
+							action.(*TunnelKeyAction).Ttl = adatum.Value[0]
 						}
 					case "skbedit":
 						switch adatum.Attr.Type {
@@ -582,6 +823,9 @@ func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 						case nl.TCA_SKBEDIT_MARK:
 							mark := native.Uint32(adatum.Value[0:4])
 							action.(*SkbEditAction).Mark = &mark
+						case nl.TCA_SKBEDIT_MASK:
+							mask := native.Uint32(adatum.Value[0:4])
+							action.(*SkbEditAction).Mask = &mask
 						case nl.TCA_SKBEDIT_PRIORITY:
 							priority := native.Uint32(adatum.Value[0:4])
 							action.(*SkbEditAction).Priority = &priority
@@ -592,6 +836,31 @@ func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 							mapping := native.Uint16(adatum.Value[0:2])
 							action.(*SkbEditAction).QueueMapping = &mapping
 						}
+					case "vlan":
+						switch adatum.Attr.Type {
+						case nl.TCA_VLAN_PARMS:
+							vlan := *nl.DeserializeTcVlan(adatum.Value)
+							toAttrs(&vlan.TcGen, action.Attrs())
+							action.(*VlanAction).Mode = VlanAct(vlan.VAction)
+						case nl.TCA_VLAN_PUSH_VLAN_ID:
+							action.(*VlanAction).VlanID = native.Uint16(adatum.Value[0:2])
+						case nl.TCA_VLAN_PUSH_VLAN_PROTOCOL:
+							action.(*VlanAction).Protocol = ntohs(adatum.Value[0:2])
+						case nl.TCA_VLAN_PUSH_VLAN_PRIORITY:
+							action.(*VlanAction).Priority = adatum.Value[0]
+						}
+					case "sample":
+						switch adatum.Attr.Type {
+						case nl.TCA_SAMPLE_PARMS:
+							gen := *nl.DeserializeTcGen(adatum.Value)
+							toAttrs(&gen, action.Attrs())
+						case nl.TCA_SAMPLE_RATE:
+							action.(*SampleAction).Rate = native.Uint32(adatum.Value[0:4])
+						case nl.TCA_SAMPLE_PSAMPLE_GROUP:
+							action.(*SampleAction).Group = native.Uint32(adatum.Value[0:4])
+						case nl.TCA_SAMPLE_TRUNC_SIZE:
+							action.(*SampleAction).TruncSize = native.Uint32(adatum.Value[0:4])
+						}
 					case "bpf":
 						switch adatum.Attr.Type {
 						case nl.TCA_ACT_BPF_PARMS:
@@ -615,11 +884,20 @@ func parseActions(tables []syscall.NetlinkRouteAttr) ([]Action, error) {
 						case nl.TCA_GACT_PARMS:
 							gen := *nl.DeserializeTcGen(adatum.Value)
 							toAttrs(&gen, action.Attrs())
+						case nl.TCA_GACT_PROB:
+							prob := *nl.DeserializeTcGactP(adatum.Value)
+							action.(*GenericAction).PGactProb = prob.Ptype
+							action.(*GenericAction).PGactVal = prob.Pval
+							action.(*GenericAction).PGactAction = TcAct(prob.Paction)
 						}
 					}
 				}
 			}
 		}
+		// the stats precede the options, which reset the action attributes
+		if action != nil {
+			action.Attrs().Statistics = statistics
+		}
 		actions = append(actions, action)
 	}
 	return actions, nil
@@ -664,6 +942,10 @@ func parseU32Data(filter Filter, data []syscall.NetlinkRouteAttr) (bool, error)
 			u32.Divisor = native.Uint32(datum.Value)
 		case nl.TCA_U32_HASH:
 			u32.Hash = native.Uint32(datum.Value)
+		case nl.TCA_U32_LINK:
+			u32.Link = native.Uint32(datum.Value)
+		case nl.TCA_U32_FLAGS:
+			u32.setClsFlags(native.Uint32(datum.Value[0:4]))
 		}
 	}
 	return detailed, nil
@@ -715,10 +997,12 @@ func parseBpfData(filter Filter, data []syscall.NetlinkRouteAttr) (bool, error)
 			if (flags & nl.TCA_BPF_FLAG_ACT_DIRECT) != 0 {
 				bpf.DirectAction = true
 			}
+		case nl.TCA_BPF_FLAGS_GEN:
+			bpf.setClsFlags(native.Uint32(datum.Value[0:4]))
 		case nl.TCA_BPF_ID:
 			bpf.Id = int(native.Uint32(datum.Value[0:4]))
 		case nl.TCA_BPF_TAG:
-			bpf.Tag = hex.EncodeToString(datum.Value[:len(datum.Value)-1])
+			bpf.Tag = hex.EncodeToString(datum.Value)
 		}
 	}
 	return detailed, nil
@@ -732,6 +1016,8 @@ func parseMatchAllData(filter Filter, data []syscall.NetlinkRouteAttr) (bool, er
 		switch datum.Attr.Type {
 		case nl.TCA_MATCHALL_CLASSID:
 			matchall.ClassId = native.Uint32(datum.Value[0:4])
+		case nl.TCA_MATCHALL_FLAGS:
+			matchall.setClsFlags(native.Uint32(datum.Value[0:4]))
 		case nl.TCA_MATCHALL_ACT:
 			tables, err := nl.ParseRouteAttr(datum.Value)
 			if err != nil {
@@ -746,6 +1032,250 @@ func parseMatchAllData(filter Filter, data []syscall.NetlinkRouteAttr) (bool, er
 	return detailed, nil
 }
 
+func parseBasicData(filter Filter, data []syscall.NetlinkRouteAttr) (bool, error) {
+	native = nl.NativeEndian()
+	basic := filter.(*Basic)
+	detailed := true
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_BASIC_CLASSID:
+			basic.ClassId = native.Uint32(datum.Value[0:4])
+		case nl.TCA_BASIC_EMATCHES:
+			var err error
+			basic.Ematches, err = parseEmatches(datum.Value)
+			if err != nil {
+				return detailed, err
+			}
+		case nl.TCA_BASIC_ACT:
+			tables, err := nl.ParseRouteAttr(datum.Value)
+			if err != nil {
+				return detailed, err
+			}
+			basic.Actions, err = parseActions(tables)
+			if err != nil {
+				return detailed, err
+			}
+		}
+	}
+	return detailed, nil
+}
+
+// encodeEmatches adds the ematch tree header and the list of ematches to
+// attr. Ematches are numbered from 1 in the list.
+func encodeEmatches(attr *nl.RtAttr, ematches []Ematch) error {
+	native = nl.NativeEndian()
+	tree := nl.TcfEmatchTree{
+		Nmatches: uint16(len(ematches)),
+		Progid:   nl.TCF_EM_PROG_TC,
+	}
+	attr.AddRtAttr(nl.TCA_EMATCH_TREE_HDR, tree.Serialize())
+	list := attr.AddRtAttr(nl.TCA_EMATCH_TREE_LIST, nil)
+	for i, ematch := range ematches {
+		hdr := nl.TcfEmatchHdr{Flags: uint16(ematch.Attrs().Relation)}
+		if ematch.Attrs().Invert {
+			hdr.Flags |= nl.TCF_EM_INVERT
+		}
+		var data []byte
+		switch ematch := ematch.(type) {
+		case *U32Ematch:
+			hdr.Kind = nl.TCF_EM_U32
+			key := nl.TcU32Key{
+				Mask: native.Uint32(htonl(ematch.Mask)),
+				Val:  native.Uint32(htonl(ematch.Val)),
+				Off:  ematch.Off,
+			}
+			data = key.Serialize()
+		case *CmpEmatch:
+			switch ematch.Align {
+			case nl.TCF_EM_ALIGN_U8, nl.TCF_EM_ALIGN_U16, nl.TCF_EM_ALIGN_U32:
+			default:
+				return fmt.Errorf("invalid cmp ematch align %d, must be 1, 2 or 4", ematch.Align)
+			}
+			hdr.Kind = nl.TCF_EM_CMP
+			cmp := nl.TcfEmCmp{
+				Val:   ematch.Val,
+				Mask:  ematch.Mask,
+				Off:   ematch.Off,
+				Align: ematch.Align,
+				Layer: uint8(ematch.Layer),
+				Opnd:  uint8(ematch.Operand),
+			}
+			if ematch.Trans {
+				cmp.Flags = nl.TCF_EM_CMP_TRANS
+			}
+			data = cmp.Serialize()
+		case *MetaEmatch:
+			hdr.Kind = nl.TCF_EM_META
+			meta := nl.TcfMetaHdr{
+				Left: nl.TcfMetaVal{
+					Kind:  nl.TCF_META_TYPE_INT<<nl.TCF_META_TYPE_SHIFT | uint16(ematch.Id)&nl.TCF_META_ID_MASK,
+					Shift: ematch.Shift,
+					Op:    uint8(ematch.Operand),
+				},
+				Right: nl.TcfMetaVal{
+					Kind: nl.TCF_META_TYPE_INT<<nl.TCF_META_TYPE_SHIFT | nl.TCF_META_ID_VALUE,
+				},
+			}
+			data = nl.NewRtAttr(nl.TCA_EM_META_HDR, meta.Serialize()).Serialize()
+			if ematch.Mask != 0 {
+				data = append(data, nl.NewRtAttr(nl.TCA_EM_META_LVALUE, nl.Uint32Attr(ematch.Mask)).Serialize()...)
+			}
+			data = append(data, nl.NewRtAttr(nl.TCA_EM_META_RVALUE, nl.Uint32Attr(ematch.Val)).Serialize()...)
+		case *ContainerEmatch:
+			if int(ematch.Ref) <= i || int(ematch.Ref) >= len(ematches) {
+				return fmt.Errorf("container ematch %d refers to invalid ematch %d", i, ematch.Ref)
+			}
+			hdr.Kind = nl.TCF_EM_CONTAINER
+			data = nl.Uint32Attr(ematch.Ref)
+		case *GenericEmatch:
+			hdr.Kind = ematch.Kind
+			data = ematch.Data
+		default:
+			return fmt.Errorf("unknown ematch type: %s", ematch.Type())
+		}
+		value := append([]byte{}, hdr.Serialize()...)
+		list.AddRtAttr(i+1, append(value, data...))
+	}
+	return nil
+}
+
+func parseEmatches(b []byte) ([]Ematch, error) {
+	native = nl.NativeEndian()
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+	var ematches []Ematch
+	for _, attr := range attrs {
+		if attr.Attr.Type != nl.TCA_EMATCH_TREE_LIST {
+			continue
+		}
+		list, err := nl.ParseRouteAttr(attr.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range list {
+			if len(item.Value) < nl.SizeofTcfEmatchHdr {
+				return nil, fmt.Errorf("ematch %d too short", item.Attr.Type)
+			}
+			hdr := nl.DeserializeTcfEmatchHdr(item.Value)
+			data := item.Value[nl.SizeofTcfEmatchHdr:]
+			ematchAttrs := EmatchAttrs{
+				Relation: EmatchRelation(hdr.Flags & nl.TCF_EM_REL_MASK),
+				Invert:   hdr.Flags&nl.TCF_EM_INVERT != 0,
+			}
+			var ematch Ematch
+			switch {
+			case hdr.Kind == nl.TCF_EM_U32 && len(data) >= nl.SizeofTcU32Key:
+				key := nl.DeserializeTcU32Key(data)
+				ematch = &U32Ematch{
+					EmatchAttrs: ematchAttrs,
+					Mask:        native.Uint32(htonl(key.Mask)),
+					Val:         native.Uint32(htonl(key.Val)),
+					Off:         key.Off,
+				}
+			case hdr.Kind == nl.TCF_EM_CMP && len(data) >= nl.SizeofTcfEmCmp:
+				cmp := nl.DeserializeTcfEmCmp(data)
+				ematch = &CmpEmatch{
+					EmatchAttrs: ematchAttrs,
+					Val:         cmp.Val,
+					Mask:        cmp.Mask,
+					Off:         cmp.Off,
+					Align:       cmp.Align,
+					Layer:       EmatchLayer(cmp.Layer),
+					Operand:     EmatchOperand(cmp.Opnd),
+					Trans:       cmp.Flags&nl.TCF_EM_CMP_TRANS != 0,
+				}
+			case hdr.Kind == nl.TCF_EM_CONTAINER && len(data) >= 4:
+				ematch = &ContainerEmatch{
+					EmatchAttrs: ematchAttrs,
+					Ref:         native.Uint32(data[0:4]),
+				}
+			case hdr.Kind == nl.TCF_EM_META:
+				ematch = parseMetaEmatch(ematchAttrs, data)
+			default:
+				ematch = &GenericEmatch{
+					EmatchAttrs: ematchAttrs,
+					Kind:        hdr.Kind,
+					Data:        append([]byte{}, data...),
+				}
+			}
+			ematches = append(ematches, ematch)
+		}
+	}
+	return ematches, nil
+}
+
+// parseMetaEmatch decodes a meta ematch comparing integer metadata with a
+// value, any other meta ematch is returned as a GenericEmatch.
+func parseMetaEmatch(ematchAttrs EmatchAttrs, data []byte) Ematch {
+	native = nl.NativeEndian()
+	generic := &GenericEmatch{
+		EmatchAttrs: ematchAttrs,
+		Kind:        nl.TCF_EM_META,
+		Data:        append([]byte{}, data...),
+	}
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return generic
+	}
+	var hdr *nl.TcfMetaHdr
+	var mask, val []byte
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.TCA_EM_META_HDR:
+			if len(attr.Value) >= nl.SizeofTcfMetaHdr {
+				hdr = nl.DeserializeTcfMetaHdr(attr.Value)
+			}
+		case nl.TCA_EM_META_LVALUE:
+			mask = attr.Value
+		case nl.TCA_EM_META_RVALUE:
+			val = attr.Value
+		}
+	}
+	if hdr == nil ||
+		hdr.Left.Kind&nl.TCF_META_TYPE_MASK != nl.TCF_META_TYPE_INT<<nl.TCF_META_TYPE_SHIFT ||
+		hdr.Right.Kind != nl.TCF_META_TYPE_INT<<nl.TCF_META_TYPE_SHIFT|nl.TCF_META_ID_VALUE {
+		return generic
+	}
+	// the kernel sends back integers as long as they were sent
+	metaInt := func(b []byte) uint32 {
+		switch len(b) {
+		case 4:
+			return native.Uint32(b)
+		case 8:
+			return uint32(native.Uint64(b))
+		}
+		return 0
+	}
+	return &MetaEmatch{
+		EmatchAttrs: ematchAttrs,
+		Id:          EmatchMetaId(hdr.Left.Kind & nl.TCF_META_ID_MASK),
+		Operand:     EmatchOperand(hdr.Left.Op),
+		Shift:       hdr.Left.Shift,
+		Mask:        metaInt(mask),
+		Val:         metaInt(val),
+	}
+}
+
+// clsFlags returns the generic classifier flags of the filter.
+func (attrs *FilterAttrs) clsFlags() uint32 {
+	var flags uint32
+	if attrs.SkipHw {
+		flags |= nl.TCA_CLS_FLAGS_SKIP_HW
+	}
+	if attrs.SkipSw {
+		flags |= nl.TCA_CLS_FLAGS_SKIP_SW
+	}
+	return flags
+}
+
+func (attrs *FilterAttrs) setClsFlags(flags uint32) {
+	attrs.SkipHw = flags&nl.TCA_CLS_FLAGS_SKIP_HW != 0
+	attrs.SkipSw = flags&nl.TCA_CLS_FLAGS_SKIP_SW != 0
+	attrs.InHw = flags&nl.TCA_CLS_FLAGS_IN_HW != 0
+}
+
 func AlignToAtm(size uint) uint {
 	var linksize, cells int
 	cells = int(size / nl.ATM_CELL_PAYLOAD)
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/vishvananda/netlink/nl"
 	"golang.org/x/sys/unix"
 )
 
@@ -872,6 +873,12 @@ func TestFilterClsActBpfAddDel(t *testing.T) {
 	if bpf.DirectAction != filter.DirectAction {
 		t.Fatal("Filter DirectAction does not match")
 	}
+	if bpf.Name != filter.Name {
+		t.Fatalf("Filter Name %q does not match", bpf.Name)
+	}
+	if bpf.Id == 0 || len(bpf.Tag) != 16 {
+		t.Fatalf("Filter program id %d or tag %q not reported", bpf.Id, bpf.Tag)
+	}
 
 	if err := FilterDel(filter); err != nil {
 		t.Fatal(err)
@@ -954,6 +961,10 @@ func TestFilterMatchAllAddDel(t *testing.T) {
 		t.Fatal("Action ifindex does not match")
 	}
 
+	if mirredAction.Statistics == nil || mirredAction.Statistics.Basic == nil {
+		t.Fatal("Action statistics not parsed")
+	}
+
 	if err := FilterDel(filter); err != nil {
 		t.Fatal(err)
 	}
@@ -967,6 +978,271 @@ func TestFilterMatchAllAddDel(t *testing.T) {
 
 }
 
+func TestFilterMatchAllSkipHw(t *testing.T) {
+	// TCA_MATCHALL_FLAGS was added in kernel 4.8
+	minKernelRequired(t, 4, 8)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	_, link := setupLinkForTestWithQdisc(t, "foo")
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_MIN_INGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+			SkipHw:    true,
+		},
+		Actions: []Action{
+			&GenericAction{
+				ActionAttrs: ActionAttrs{
+					Action: TC_ACT_OK,
+				},
+			},
+		},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, HANDLE_MIN_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	attrs := filters[0].Attrs()
+	if !attrs.SkipHw || attrs.SkipSw {
+		t.Fatalf("Filter flags do not match: %+v", attrs)
+	}
+	if attrs.InHw {
+		t.Fatal("Filter with skip_hw can not be offloaded")
+	}
+
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilterMatchAllSampleAddDel(t *testing.T) {
+	// The sample action was added in kernel 4.11
+	minKernelRequired(t, 4, 11)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	_, link := setupLinkForTestWithQdisc(t, "foo")
+	sample := NewSampleAction()
+	sample.Rate = 100
+	sample.Group = 5
+	sample.TruncSize = 128
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_MIN_INGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []Action{sample},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, HANDLE_MIN_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	matchall, ok := filters[0].(*MatchAll)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if len(matchall.Actions) != 1 {
+		t.Fatal("Filter has no actions")
+	}
+	result, ok := matchall.Actions[0].(*SampleAction)
+	if !ok {
+		t.Fatal("Action does not match")
+	}
+	if result.Rate != sample.Rate || result.Group != sample.Group || result.TruncSize != sample.TruncSize {
+		t.Fatalf("Sample action does not match: %+v", result)
+	}
+
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+	filters, err = FilterList(link, HANDLE_MIN_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 0 {
+		t.Fatal("Failed to remove filter")
+	}
+}
+
+func TestFilterMatchAllGactProb(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	_, link := setupLinkForTestWithQdisc(t, "foo")
+	gact := &GenericAction{
+		ActionAttrs: ActionAttrs{
+			Action: TC_ACT_OK,
+		},
+		PGactProb:   GACT_PROB_NETRAND,
+		PGactVal:    10,
+		PGactAction: TC_ACT_SHOT,
+	}
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_MIN_INGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []Action{gact},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, HANDLE_MIN_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	matchall, ok := filters[0].(*MatchAll)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if len(matchall.Actions) != 1 {
+		t.Fatal("Filter has no actions")
+	}
+	result, ok := matchall.Actions[0].(*GenericAction)
+	if !ok {
+		t.Fatal("Action does not match")
+	}
+	if result.Action != gact.Action || result.PGactProb != gact.PGactProb ||
+		result.PGactVal != gact.PGactVal || result.PGactAction != gact.PGactAction {
+		t.Fatalf("Gact action does not match: %+v", result)
+	}
+
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilterMatchAllVlanAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	_, link := setupLinkForTestWithQdisc(t, "foo")
+	vlan := NewVlanAction()
+	vlan.Mode = TCA_VLAN_ACT_PUSH
+	vlan.VlanID = 100
+	vlan.Priority = 3
+	vlan.Protocol = unix.ETH_P_8021AD
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_MIN_EGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []Action{vlan},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, HANDLE_MIN_EGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	matchall, ok := filters[0].(*MatchAll)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if len(matchall.Actions) != 1 {
+		t.Fatal("Filter has no actions")
+	}
+	result, ok := matchall.Actions[0].(*VlanAction)
+	if !ok {
+		t.Fatal("Action does not match")
+	}
+	if result.Mode != vlan.Mode || result.VlanID != vlan.VlanID ||
+		result.Priority != vlan.Priority || result.Protocol != vlan.Protocol {
+		t.Fatalf("Vlan action does not match: %+v", result)
+	}
+
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilterMatchAllTunnelKeyTosTtl(t *testing.T) {
+	// tos and ttl of the tunnel_key action were added in kernel 4.19
+	minKernelRequired(t, 4, 19)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	_, link := setupLinkForTestWithQdisc(t, "foo")
+	tunnelAct := NewTunnelKeyAction()
+	tunnelAct.SrcAddr = net.IPv4(10, 10, 10, 1)
+	tunnelAct.DstAddr = net.IPv4(10, 10, 10, 2)
+	tunnelAct.KeyID = 0x01
+	tunnelAct.DestPort = 8472
+	tunnelAct.Tos = 0x10
+	tunnelAct.Ttl = 64
+	tunnelAct.Action = TCA_TUNNEL_KEY_SET
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_MIN_EGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []Action{tunnelAct},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, HANDLE_MIN_EGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	matchall, ok := filters[0].(*MatchAll)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if len(matchall.Actions) != 1 {
+		t.Fatal("Filter has no actions")
+	}
+	tun, ok := matchall.Actions[0].(*TunnelKeyAction)
+	if !ok {
+		t.Fatal("Unable to find tunnel action")
+	}
+	if tun.DestPort != tunnelAct.DestPort {
+		t.Fatal("Action DestPort doesn't match")
+	}
+	if tun.Tos != tunnelAct.Tos || tun.Ttl != tunnelAct.Ttl {
+		t.Fatal("Action Tos or Ttl doesn't match")
+	}
+
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestFilterU32TunnelKeyAddDel(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -1306,3 +1582,624 @@ func TestFilterU32SkbEditAddDel(t *testing.T) {
 		t.Fatal("Failed to remove qdisc")
 	}
 }
+
+func TestFilterClsactParents(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, parent := range []uint32{HANDLE_CLSACT_INGRESS, HANDLE_CLSACT_EGRESS} {
+		filter := &U32{
+			FilterAttrs: FilterAttrs{
+				LinkIndex: link.Attrs().Index,
+				Parent:    parent,
+				Priority:  1,
+				Protocol:  unix.ETH_P_ALL,
+			},
+			ClassId: MakeHandle(1, 1),
+		}
+		if err := FilterAdd(filter); err != nil {
+			t.Fatal(err)
+		}
+		filters, err := FilterList(link, parent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filters) != 1 {
+			t.Fatalf("Failed to add filter to parent %s", HandleStr(parent))
+		}
+		if filters[0].Attrs().Parent != parent {
+			t.Fatalf("Filter parent %s doesn't match %s", HandleStr(filters[0].Attrs().Parent), HandleStr(parent))
+		}
+		if err := FilterDel(filter); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestParseActionStatistics(t *testing.T) {
+	table := nl.NewRtAttr(nl.TCA_ACT_TAB, nil)
+	table.AddRtAttr(nl.TCA_ACT_KIND, nl.ZeroTerminated("gact"))
+	stats := table.AddRtAttr(nl.TCA_ACT_STATS, nil)
+	basic := make([]byte, 12)
+	native := nl.NativeEndian()
+	native.PutUint64(basic[0:8], 1500)
+	native.PutUint32(basic[8:12], 10)
+	stats.AddRtAttr(nl.TCA_STATS_BASIC, basic)
+	queue := make([]byte, 20)
+	native.PutUint32(queue[8:12], 3)
+	stats.AddRtAttr(nl.TCA_STATS_QUEUE, queue)
+	options := table.AddRtAttr(nl.TCA_ACT_OPTIONS, nil)
+	gen := nl.TcGen{Action: int32(TC_ACT_SHOT)}
+	options.AddRtAttr(nl.TCA_GACT_PARMS, gen.Serialize())
+
+	tables, err := nl.ParseRouteAttr(table.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err := parseActions(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("Expected 1 action, got %d", len(actions))
+	}
+	attrs := actions[0].Attrs()
+	if attrs.Action != TC_ACT_SHOT {
+		t.Fatalf("Expected action %s, got %s", TC_ACT_SHOT, attrs.Action)
+	}
+	if attrs.Statistics == nil {
+		t.Fatal("Action statistics not parsed")
+	}
+	if attrs.Statistics.Basic.Bytes != 1500 || attrs.Statistics.Basic.Packets != 10 {
+		t.Fatalf("Unexpected basic stats %+v", attrs.Statistics.Basic)
+	}
+	if attrs.Statistics.Queue.Drops != 3 {
+		t.Fatalf("Unexpected queue stats %+v", attrs.Statistics.Queue)
+	}
+}
+
+func TestSkbEditActionEncodeParse(t *testing.T) {
+	skbedit := NewSkbEditAction()
+	priority := uint32(MakeHandle(1, 10))
+	skbedit.Priority = &priority
+	mark := uint32(0x10)
+	skbedit.Mark = &mark
+	mask := uint32(0xf0)
+	skbedit.Mask = &mask
+
+	attr := nl.NewRtAttr(nl.TCA_U32_ACT, nil)
+	if err := EncodeActions(attr, []Action{skbedit}); err != nil {
+		t.Fatal(err)
+	}
+	tables, err := nl.ParseRouteAttr(attr.Serialize()[unix.SizeofRtAttr:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions, err := parseActions(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 1 {
+		t.Fatalf("Expected 1 action, got %d", len(actions))
+	}
+	edit, ok := actions[0].(*SkbEditAction)
+	if !ok {
+		t.Fatal("Action is the wrong type")
+	}
+	if edit.Priority == nil || *edit.Priority != priority {
+		t.Fatal("Action Priority doesn't match")
+	}
+	if edit.Mark == nil || *edit.Mark != mark {
+		t.Fatal("Action Mark doesn't match")
+	}
+	if edit.Mask == nil || *edit.Mask != mask {
+		t.Fatal("Action Mask doesn't match")
+	}
+	if edit.QueueMapping != nil || edit.PType != nil {
+		t.Fatal("Unset fields should not be decoded")
+	}
+
+	skbedit.Mark = nil
+	if err := EncodeActions(nl.NewRtAttr(nl.TCA_U32_ACT, nil), []Action{skbedit}); err == nil {
+		t.Fatal("Expected an error for a mask without a mark")
+	}
+}
+
+func TestEmatchEncodeParse(t *testing.T) {
+	// u32(u32 0x1 0xff at 0) AND (proto tcp OR dport 443)
+	ematches := []Ematch{
+		&U32Ematch{EmatchAttrs: EmatchAttrs{Relation: EMATCH_REL_AND}, Mask: 0xff, Val: 0x1},
+		&ContainerEmatch{Ref: 2},
+		&CmpEmatch{
+			EmatchAttrs: EmatchAttrs{Relation: EMATCH_REL_OR},
+			Val:         unix.IPPROTO_TCP,
+			Mask:        0xff,
+			Off:         9,
+			Align:       1,
+			Layer:       EMATCH_LAYER_NETWORK,
+		},
+		&CmpEmatch{
+			EmatchAttrs: EmatchAttrs{Invert: true},
+			Val:         443,
+			Mask:        0xffff,
+			Off:         2,
+			Align:       2,
+			Layer:       EMATCH_LAYER_TRANSPORT,
+			Trans:       true,
+		},
+	}
+	attr := nl.NewRtAttr(nl.TCA_BASIC_EMATCHES, nil)
+	if err := encodeEmatches(attr, ematches); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parseEmatches(attr.Serialize()[unix.SizeofRtAttr:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, ematches) {
+		t.Fatalf("Parsed ematches %v, expected %v", parsed, ematches)
+	}
+
+	// containers may only refer to later ematches
+	ematches[1].(*ContainerEmatch).Ref = 0
+	if err := encodeEmatches(nl.NewRtAttr(nl.TCA_BASIC_EMATCHES, nil), ematches); err == nil {
+		t.Fatal("Expected an error for a backward container reference")
+	}
+	ematches[1].(*ContainerEmatch).Ref = 2
+	ematches[2].(*CmpEmatch).Align = 3
+	if err := encodeEmatches(nl.NewRtAttr(nl.TCA_BASIC_EMATCHES, nil), ematches); err == nil {
+		t.Fatal("Expected an error for an invalid cmp align")
+	}
+
+	// meta(priority eq 5) AND meta(nf_mark mask 0xff gt 1)
+	ematches = []Ematch{
+		&MetaEmatch{
+			EmatchAttrs: EmatchAttrs{Relation: EMATCH_REL_AND},
+			Id:          EMATCH_META_ID_PRIORITY,
+			Val:         5,
+		},
+		&MetaEmatch{Id: EMATCH_META_ID_NFMARK, Operand: EMATCH_OPND_GT, Shift: 4, Mask: 0xff, Val: 1},
+	}
+	attr = nl.NewRtAttr(nl.TCA_BASIC_EMATCHES, nil)
+	if err := encodeEmatches(attr, ematches); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err = parseEmatches(attr.Serialize()[unix.SizeofRtAttr:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, ematches) {
+		t.Fatalf("Parsed meta ematches %v, expected %v", parsed, ematches)
+	}
+}
+
+func TestFilterBasicAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	// proto tcp AND dport 443
+	filter := &Basic{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_CLSACT_INGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_IP,
+		},
+		ClassId: MakeHandle(1, 1),
+		Ematches: []Ematch{
+			&CmpEmatch{
+				EmatchAttrs: EmatchAttrs{Relation: EMATCH_REL_AND},
+				Val:         unix.IPPROTO_TCP,
+				Mask:        0xff,
+				Off:         9,
+				Align:       1,
+				Layer:       EMATCH_LAYER_NETWORK,
+			},
+			&U32Ematch{Mask: 0xffff, Val: 443, Off: 20},
+		},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+	filters, err := FilterList(link, HANDLE_CLSACT_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	basic, ok := filters[0].(*Basic)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if basic.ClassId != filter.ClassId {
+		t.Fatal("ClassId doesn't match")
+	}
+	if !reflect.DeepEqual(basic.Ematches, filter.Ematches) {
+		t.Fatalf("Ematches %v don't match %v", basic.Ematches, filter.Ematches)
+	}
+	if err := FilterDel(basic); err != nil {
+		t.Fatal(err)
+	}
+	filters, err = FilterList(link, HANDLE_CLSACT_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 0 {
+		t.Fatal("Failed to remove filter")
+	}
+}
+
+func TestFilterBasicMetaAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	// meta(priority eq 5) AND meta(nf_mark mask 0xff gt 1)
+	filter := &Basic{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_CLSACT_INGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		ClassId: MakeHandle(1, 5),
+		Ematches: []Ematch{
+			&MetaEmatch{
+				EmatchAttrs: EmatchAttrs{Relation: EMATCH_REL_AND},
+				Id:          EMATCH_META_ID_PRIORITY,
+				Operand:     EMATCH_OPND_EQ,
+				Val:         5,
+			},
+			&MetaEmatch{
+				Id:      EMATCH_META_ID_NFMARK,
+				Operand: EMATCH_OPND_GT,
+				Mask:    0xff,
+				Val:     1,
+			},
+		},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+	filters, err := FilterList(link, HANDLE_CLSACT_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	basic, ok := filters[0].(*Basic)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if !reflect.DeepEqual(basic.Ematches, filter.Ematches) {
+		t.Fatalf("Ematches %v don't match %v", basic.Ematches, filter.Ematches)
+	}
+	if err := FilterDel(basic); err != nil {
+		t.Fatal(err)
+	}
+	filters, err = FilterList(link, HANDLE_CLSACT_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 0 {
+		t.Fatal("Failed to remove filter")
+	}
+}
+
+func TestFilterListOrder(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add out of order, the handles are left for the kernel to allocate
+	for _, prio := range []uint16{3, 1, 2} {
+		filter := &U32{
+			FilterAttrs: FilterAttrs{
+				LinkIndex: link.Attrs().Index,
+				Parent:    HANDLE_CLSACT_INGRESS,
+				Priority:  prio,
+				Protocol:  unix.ETH_P_ALL,
+			},
+			ClassId: MakeHandle(1, prio),
+		}
+		if err := FilterAdd(filter); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filters, err := FilterList(link, HANDLE_CLSACT_INGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 3 {
+		t.Fatalf("Expected 3 filters, got %d", len(filters))
+	}
+	handles := make(map[uint32]bool)
+	for i, filter := range filters {
+		if filter.Attrs().Priority != uint16(i+1) {
+			t.Fatalf("Filter %d has priority %d, expected %d", i, filter.Attrs().Priority, i+1)
+		}
+		if filter.Attrs().Handle == 0 || handles[filter.Attrs().Handle] {
+			t.Fatalf("Filter %d has a zero or duplicate handle %s", i, HandleStr(filter.Attrs().Handle))
+		}
+		handles[filter.Attrs().Handle] = true
+	}
+}
+
+func TestFilterAddAndGet(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Parent:    HANDLE_ROOT,
+	})
+	echoed, err := QdiscAddAndGet(qdisc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := echoed.(*Htb); !ok {
+		t.Fatalf("Expected an htb qdisc, got %v", echoed)
+	}
+	if qdisc.Handle == 0 || echoed.Attrs().Handle != qdisc.Handle {
+		t.Fatalf("Expected an allocated qdisc handle, got %s", HandleStr(qdisc.Handle))
+	}
+
+	major, _ := MajorMinor(qdisc.Handle)
+	class := NewHtbClass(ClassAttrs{
+		LinkIndex: link.Attrs().Index,
+		Parent:    qdisc.Handle,
+		Handle:    MakeHandle(major, 1),
+	}, HtbClassAttrs{Rate: 1234000, Cbuffer: 1690})
+	echoedClass, err := ClassAddAndGet(class)
+	if err != nil {
+		t.Fatal(err)
+	}
+	htbClass, ok := echoedClass.(*HtbClass)
+	if !ok || htbClass.Handle != class.Handle || htbClass.Rate != class.Rate {
+		t.Fatalf("Echoed class %v doesn't match %v", echoedClass, class)
+	}
+
+	filter := &U32{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    qdisc.Handle,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		ClassId: class.Handle,
+	}
+	echoedFilter, err := FilterAddAndGet(filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u32, ok := echoedFilter.(*U32)
+	if !ok || u32.ClassId != class.Handle {
+		t.Fatalf("Echoed filter %v doesn't match %v", echoedFilter, filter)
+	}
+	if filter.Handle == 0 || u32.Handle != filter.Handle {
+		t.Fatalf("Expected an allocated filter handle, got %s", HandleStr(filter.Handle))
+	}
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FilterAddAndGet(filter); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilterU32HashTable(t *testing.T) {
+	if bucket := U32HashBucket(0x0a000005, 0xff, 256); bucket != 5 {
+		t.Fatalf("Expected bucket 5, got %d", bucket)
+	}
+	if bucket := U32HashBucket(0x0a000a05, 0xff00, 16); bucket != 0xa {
+		t.Fatalf("Expected bucket 10, got %d", bucket)
+	}
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := link.Attrs().Index
+	qdiscHandle := MakeHandle(1, 0)
+	if err := QdiscAdd(NewHtb(QdiscAttrs{LinkIndex: index, Handle: qdiscHandle, Parent: HANDLE_ROOT})); err != nil {
+		t.Fatal(err)
+	}
+
+	ht := uint32(0x00200000)
+	attrs := FilterAttrs{
+		LinkIndex: index,
+		Parent:    qdiscHandle,
+		Priority:  1,
+		Protocol:  unix.ETH_P_IP,
+	}
+	hashTable := &U32{FilterAttrs: attrs, Divisor: 256}
+	hashTable.Handle = ht
+	if err := FilterAdd(hashTable); err != nil {
+		t.Fatal(err)
+	}
+
+	linkFilter := &U32{FilterAttrs: attrs, Link: ht}
+	linkFilter.SetHashKey(0xff, 16)
+	if err := FilterAdd(linkFilter); err != nil {
+		t.Fatal(err)
+	}
+
+	bucket := U32HashBucket(0x0a000005, 0xff, hashTable.Divisor)
+	entry := &U32{
+		FilterAttrs: attrs,
+		Hash:        U32HashHandle(ht, bucket),
+		ClassId:     MakeHandle(1, 1),
+		Sel: &TcU32Sel{
+			Flags: TC_U32_TERMINAL,
+			Keys:  []TcU32Key{{Mask: 0xffffffff, Val: 0x0a000005, Off: 16}},
+		},
+	}
+	if err := FilterAdd(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	filters, err := FilterList(link, qdiscHandle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var foundLink, foundEntry bool
+	for _, f := range filters {
+		u32 := f.(*U32)
+		switch {
+		case u32.Link == ht:
+			foundLink = true
+			if u32.Sel.Hmask != 0xff || u32.Sel.Hoff != 16 {
+				t.Fatalf("Hash key mask %#x at %d doesn't match", u32.Sel.Hmask, u32.Sel.Hoff)
+			}
+			if u32.Sel.Flags&TC_U32_TERMINAL != 0 {
+				t.Fatal("Link filter should not be terminal")
+			}
+		case u32.ClassId == entry.ClassId:
+			foundEntry = true
+			if u32.Handle&0xfffff000 != U32HashHandle(ht, 5) {
+				t.Fatalf("Filter in bucket %#x, expected bucket 5 of %#x", u32.Handle, ht)
+			}
+		}
+	}
+	if !foundLink || !foundEntry {
+		t.Fatalf("Filters not found: %v", filters)
+	}
+}
+
+func TestFilterMatchAllActionOnly(t *testing.T) {
+	minKernelRequired(t, 4, 7)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(0xffff, 0),
+			Parent:    HANDLE_CLSACT,
+		},
+		QdiscType: "clsact",
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	// no classid, the filter only runs its actions
+	skbedit := NewSkbEditAction()
+	priority := uint32(MakeHandle(1, 2))
+	skbedit.Priority = &priority
+	filter := &MatchAll{
+		FilterAttrs: FilterAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_CLSACT_EGRESS,
+			Priority:  1,
+			Protocol:  unix.ETH_P_ALL,
+		},
+		Actions: []Action{skbedit},
+	}
+	if err := FilterAdd(filter); err != nil {
+		t.Fatal(err)
+	}
+	filters, err := FilterList(link, HANDLE_CLSACT_EGRESS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filters) != 1 {
+		t.Fatal("Failed to add filter")
+	}
+	matchall, ok := filters[0].(*MatchAll)
+	if !ok {
+		t.Fatal("Filter is the wrong type")
+	}
+	if matchall.ClassId != 0 {
+		t.Fatalf("Expected no classid, got %s", HandleStr(matchall.ClassId))
+	}
+	if len(matchall.Actions) != 1 {
+		t.Fatalf("Expected 1 action, got %d", len(matchall.Actions))
+	}
+	action, ok := matchall.Actions[0].(*SkbEditAction)
+	if !ok {
+		t.Fatalf("Action is the wrong type %T", matchall.Actions[0])
+	}
+	if action.Priority == nil || *action.Priority != priority {
+		t.Fatal("Action priority doesn't match")
+	}
+	if err := FilterDel(filter); err != nil {
+		t.Fatal(err)
+	}
+}
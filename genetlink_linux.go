@@ -126,6 +126,8 @@ func parseFamilies(msgs [][]byte) ([]*GenlFamily, error) {
 	return families, nil
 }
 
+// GenlFamilyList lists the generic netlink families registered in the
+// kernel.
 func (h *Handle) GenlFamilyList() ([]*GenlFamily, error) {
 	msg := &nl.Genlmsg{
 		Command: nl.GENL_CTRL_CMD_GETFAMILY,
@@ -140,10 +142,15 @@ func (h *Handle) GenlFamilyList() ([]*GenlFamily, error) {
 	return parseFamilies(msgs)
 }
 
+// GenlFamilyList lists the generic netlink families registered in the
+// kernel.
 func GenlFamilyList() ([]*GenlFamily, error) {
 	return pkgHandle.GenlFamilyList()
 }
 
+// GenlFamilyGet resolves a generic netlink family by name. The returned
+// family holds the id to send requests to and the multicast groups that
+// can be subscribed to.
 func (h *Handle) GenlFamilyGet(name string) (*GenlFamily, error) {
 	msg := &nl.Genlmsg{
 		Command: nl.GENL_CTRL_CMD_GETFAMILY,
@@ -166,6 +173,47 @@ func (h *Handle) GenlFamilyGet(name string) (*GenlFamily, error) {
 	return families[0], nil
 }
 
+// GenlFamilyGet resolves a generic netlink family by name. The returned
+// family holds the id to send requests to and the multicast groups that
+// can be subscribed to.
 func GenlFamilyGet(name string) (*GenlFamily, error) {
 	return pkgHandle.GenlFamilyGet(name)
 }
+
+// GenlRequest sends the command cmd to a generic netlink family and returns
+// the replies with the generic netlink header stripped. data is appended
+// after the header, so a family with a header of its own (HdrSize) expects
+// that header first, followed by its attributes. flags are netlink flags
+// such as unix.NLM_F_DUMP or unix.NLM_F_ACK.
+func GenlRequest(family *GenlFamily, cmd uint8, flags int, data ...nl.NetlinkRequestData) ([][]byte, error) {
+	return pkgHandle.GenlRequest(family, cmd, flags, data...)
+}
+
+// GenlRequest sends the command cmd to a generic netlink family and returns
+// the replies with the generic netlink header stripped. data is appended
+// after the header, so a family with a header of its own (HdrSize) expects
+// that header first, followed by its attributes. flags are netlink flags
+// such as unix.NLM_F_DUMP or unix.NLM_F_ACK.
+func (h *Handle) GenlRequest(family *GenlFamily, cmd uint8, flags int, data ...nl.NetlinkRequestData) ([][]byte, error) {
+	msg := &nl.Genlmsg{
+		Command: cmd,
+		Version: uint8(family.Version),
+	}
+	req := h.newNetlinkRequest(int(family.ID), flags)
+	req.AddData(msg)
+	for _, d := range data {
+		req.AddData(d)
+	}
+	msgs, err := req.Execute(unix.NETLINK_GENERIC, family.ID)
+	if err != nil {
+		return nil, err
+	}
+	res := make([][]byte, 0, len(msgs))
+	for _, m := range msgs {
+		if len(m) < nl.SizeofGenlmsg {
+			return nil, fmt.Errorf("generic netlink reply too short: %d bytes", len(m))
+		}
+		res = append(res, m[nl.SizeofGenlmsg:])
+	}
+	return res, nil
+}
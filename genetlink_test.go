@@ -0,0 +1,73 @@
+// +build linux
+
+package netlink
+
+import (
+	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+func TestGenlFamilyGet(t *testing.T) {
+	family, err := GenlFamilyGet(nl.GENL_CTRL_NAME)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if family.ID != nl.GENL_ID_CTRL {
+		t.Fatalf("Expected id %d for %s, got %d", nl.GENL_ID_CTRL, nl.GENL_CTRL_NAME, family.ID)
+	}
+	found := false
+	for _, g := range family.Groups {
+		if g.Name == "notify" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Multicast group notify not found in %v", family.Groups)
+	}
+
+	if _, err := GenlFamilyGet("does-not-exist"); err == nil {
+		t.Fatal("Expected an error for an unknown family")
+	}
+}
+
+func TestGenlRequest(t *testing.T) {
+	ctrl, err := GenlFamilyGet(nl.GENL_CTRL_NAME)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ask the controller for its own family, like GenlFamilyGet does
+	msgs, err := GenlRequest(ctrl, nl.GENL_CTRL_CMD_GETFAMILY, 0,
+		nl.NewRtAttr(nl.GENL_CTRL_ATTR_FAMILY_NAME, nl.ZeroTerminated(nl.GENL_CTRL_NAME)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("Expected 1 reply, got %d", len(msgs))
+	}
+	attrs, err := nl.ParseRouteAttr(msgs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	family := &GenlFamily{}
+	if err := family.parseAttributes(attrs); err != nil {
+		t.Fatal(err)
+	}
+	if family.Name != nl.GENL_CTRL_NAME || family.ID != ctrl.ID {
+		t.Fatalf("Unexpected family %s id %d", family.Name, family.ID)
+	}
+
+	msgs, err = GenlRequest(ctrl, nl.GENL_CTRL_CMD_GETFAMILY, unix.NLM_F_DUMP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	families, err := GenlFamilyList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != len(families) {
+		t.Fatalf("Expected %d families in the dump, got %d", len(families), len(msgs))
+	}
+}
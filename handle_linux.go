@@ -19,6 +19,7 @@ var pkgHandle = &Handle{}
 type Handle struct {
 	sockets      map[int]*nl.SocketHandle
 	lookupByDump bool
+	requestHook  nl.MessageHook
 }
 
 // SupportsNetlinkFamily reports whether the passed netlink family is supported by this Handle
@@ -73,6 +74,32 @@ func (h *Handle) SetSocketReceiveBufferSize(size int, force bool) error {
 	return nil
 }
 
+// SetRequestHook sets a function that is called with the type and the raw
+// bytes of every netlink message the handle sends and receives, which is
+// useful to compare the requests with the ones of iproute2. Outgoing
+// messages have the NLM_F_REQUEST flag set in their header. A nil hook
+// removes it. The hook must be set before the handle is used concurrently.
+func (h *Handle) SetRequestHook(hook func(msgType int, data []byte)) {
+	h.requestHook = hook
+}
+
+// SetStrictCheck enables or disables strict checking of get and dump
+// requests on the NETLINK_ROUTE socket of the handle. With strict checking
+// the kernel rejects malformed requests instead of ignoring the unknown
+// parts and applies the filters of dump requests itself. It requires
+// kernel 4.20 or newer, older kernels return ENOPROTOOPT.
+func (h *Handle) SetStrictCheck(state bool) error {
+	sh, ok := h.sockets[unix.NETLINK_ROUTE]
+	if !ok {
+		return fmt.Errorf("handle does not support the NETLINK_ROUTE family")
+	}
+	value := 0
+	if state {
+		value = 1
+	}
+	return unix.SetsockoptInt(sh.Socket.GetFd(), unix.SOL_NETLINK, unix.NETLINK_GET_STRICT_CHK, value)
+}
+
 // GetSocketReceiveBufferSize gets the receiver buffer size for each
 // socket in the netlink handle. The retrieved value should be the
 // double to the one set for SetSocketReceiveBufferSize.
@@ -131,7 +158,9 @@ func (h *Handle) Delete() {
 func (h *Handle) newNetlinkRequest(proto, flags int) *nl.NetlinkRequest {
 	// Do this so that package API still use nl package variable nextSeqNr
 	if h.sockets == nil {
-		return nl.NewNetlinkRequest(proto, flags)
+		req := nl.NewNetlinkRequest(proto, flags)
+		req.Hook = h.requestHook
+		return req
 	}
 	return &nl.NetlinkRequest{
 		NlMsghdr: unix.NlMsghdr{
@@ -140,5 +169,6 @@ func (h *Handle) newNetlinkRequest(proto, flags int) *nl.NetlinkRequest {
 			Flags: unix.NLM_F_REQUEST | uint16(flags),
 		},
 		Sockets: h.sockets,
+		Hook:    h.requestHook,
 	}
 }
@@ -366,3 +366,105 @@ func TestHandleParallel3(t *testing.T) {
 func TestHandleParallel4(t *testing.T) {
 	runParallelTests(t, 4)
 }
+
+func TestHandleStrictCheckLinkByName(t *testing.T) {
+	minKernelRequired(t, 4, 20)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	h, err := NewHandle(unix.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+	if err := h.SetStrictCheck(true); err != nil {
+		t.Fatal(err)
+	}
+	value, err := unix.GetsockoptInt(h.sockets[unix.NETLINK_ROUTE].Socket.GetFd(), unix.SOL_NETLINK, unix.NETLINK_GET_STRICT_CHK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != 1 {
+		t.Fatal("Strict checking not enabled on the socket")
+	}
+
+	if err := h.LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.LinkSetAlias(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}, "bar"); err != nil {
+		t.Fatal(err)
+	}
+	link, err := h.LinkByAlias("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Name != "foo" {
+		t.Fatalf("Expected link foo, got %s", link.Attrs().Name)
+	}
+	link, err = h.LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Alias != "bar" {
+		t.Fatalf("Expected alias bar, got %s", link.Attrs().Alias)
+	}
+	if h.lookupByDump {
+		t.Fatal("LinkByName fell back to dumping all links")
+	}
+	if _, err := h.LinkByName("missing"); err == nil {
+		t.Fatal("Expected an error for a missing link")
+	} else if _, ok := err.(LinkNotFoundError); !ok {
+		t.Fatalf("Expected LinkNotFoundError, got %v", err)
+	}
+}
+
+func TestHandleRequestHook(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	h, err := NewHandle(unix.NETLINK_ROUTE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Delete()
+
+	type message struct {
+		msgType int
+		data    []byte
+	}
+	var msgs []message
+	h.SetRequestHook(func(msgType int, data []byte) {
+		msgs = append(msgs, message{msgType, data})
+	})
+	if _, err := h.LinkByName("lo"); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("Expected a request and a response, got %d messages", len(msgs))
+	}
+	native := nl.NativeEndian()
+	req, resp := msgs[0], msgs[1]
+	if req.msgType != unix.RTM_GETLINK || native.Uint16(req.data[6:8])&unix.NLM_F_REQUEST == 0 {
+		t.Fatalf("Unexpected request of type %d", req.msgType)
+	}
+	if resp.msgType != unix.RTM_NEWLINK || native.Uint16(resp.data[6:8])&unix.NLM_F_REQUEST != 0 {
+		t.Fatalf("Unexpected response of type %d", resp.msgType)
+	}
+	for _, m := range msgs {
+		if int(native.Uint32(m.data[0:4])) != len(m.data) {
+			t.Fatalf("Message length %d doesn't match the header", len(m.data))
+		}
+	}
+	if native.Uint32(req.data[8:12]) != native.Uint32(resp.data[8:12]) {
+		t.Fatal("Response sequence number doesn't match the request")
+	}
+
+	h.SetRequestHook(nil)
+	msgs = nil
+	if _, err := h.LinkByName("lo"); err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 0 {
+		t.Fatal("Hook called after it was removed")
+	}
+}
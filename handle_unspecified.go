@@ -1,8 +1,10 @@
+//go:build !linux
 // +build !linux
 
 package netlink
 
 import (
+	"context"
 	"net"
 	"time"
 
@@ -33,6 +35,12 @@ func (h *Handle) SetSocketTimeout(to time.Duration) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) SetStrictCheck(state bool) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) SetRequestHook(hook func(msgType int, data []byte)) {}
+
 func (h *Handle) SetPromiscOn(link Link) error {
 	return ErrNotImplemented
 }
@@ -45,6 +53,10 @@ func (h *Handle) LinkSetUp(link Link) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetUpAndWait(link Link, timeout time.Duration) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetDown(link Link) error {
 	return ErrNotImplemented
 }
@@ -57,10 +69,26 @@ func (h *Handle) LinkSetName(link Link, name string) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkRenameSwap(a, b Link) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetLinkMode(link Link, mode LinkMode) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetAlias(link Link, name string) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkAddAltName(link Link, name string) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkDelAltName(link Link, name string) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetHardwareAddr(link Link, hwaddr net.HardwareAddr) error {
 	return ErrNotImplemented
 }
@@ -77,6 +105,10 @@ func (h *Handle) LinkSetVfVlanQos(link Link, vf, vlan, qos int) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetVfVlanQosProto(link Link, vf, vlan, qos int, proto uint16) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetVfTxRate(link Link, vf, rate int) error {
 	return ErrNotImplemented
 }
@@ -85,6 +117,10 @@ func (h *Handle) LinkSetVfRate(link Link, vf, minRate, maxRate int) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetVfRssQuery(link Link, vf int, state bool) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetMaster(link Link, master *Bridge) error {
 	return ErrNotImplemented
 }
@@ -105,6 +141,14 @@ func (h *Handle) LinkSetNsFd(link Link, fd int) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetXdpFd(link Link, fd int) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetXdpFdWithFlags(link Link, fd, flags int) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkAdd(link Link) error {
 	return ErrNotImplemented
 }
@@ -121,6 +165,10 @@ func (h *Handle) LinkByAlias(alias string) (Link, error) {
 	return nil, ErrNotImplemented
 }
 
+func (h *Handle) LinkByAltName(name string) (Link, error) {
+	return nil, ErrNotImplemented
+}
+
 func (h *Handle) LinkByIndex(index int) (Link, error) {
 	return nil, ErrNotImplemented
 }
@@ -129,6 +177,18 @@ func (h *Handle) LinkList() ([]Link, error) {
 	return nil, ErrNotImplemented
 }
 
+func (h *Handle) LinkListContext(ctx context.Context) ([]Link, error) {
+	return nil, ErrNotImplemented
+}
+
+func (h *Handle) LinkStatsPoll(ctx context.Context, interval time.Duration, ifindices []int) (<-chan LinkStatsSample, error) {
+	return nil, ErrNotImplemented
+}
+
+func (h *Handle) LinkXStats(link Link) (*LinkXStatistics, error) {
+	return nil, ErrNotImplemented
+}
+
 func (h *Handle) LinkSetHairpin(link Link, mode bool) error {
 	return ErrNotImplemented
 }
@@ -153,6 +213,22 @@ func (h *Handle) LinkSetFlood(link Link, mode bool) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetBrNeighSuppress(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetMcastFlood(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetMcastToUcast(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetMulticastRouter(link Link, router uint8) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) LinkSetTxQLen(link Link, qlen int) error {
 	return ErrNotImplemented
 }
@@ -161,6 +237,14 @@ func (h *Handle) LinkSetGroup(link Link, group int) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) LinkSetIPv4Forwarding(link Link, enable bool) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) LinkSetIPv4RpFilter(link Link, mode int) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) setProtinfoAttr(link Link, mode bool, attr int) error {
 	return ErrNotImplemented
 }
@@ -173,6 +257,10 @@ func (h *Handle) AddrDel(link Link, addr *Addr) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) AddrReplaceAll(link Link, addrs []*Addr) error {
+	return ErrNotImplemented
+}
+
 func (h *Handle) AddrList(link Link, family int) ([]Addr, error) {
 	return nil, ErrNotImplemented
 }
@@ -229,6 +317,14 @@ func (h *Handle) NeighList(linkIndex, family int) ([]Neigh, error) {
 	return nil, ErrNotImplemented
 }
 
+func (h *Handle) NeighListFiltered(filter *NeighFilter) ([]Neigh, error) {
+	return nil, ErrNotImplemented
+}
+
+func (h *Handle) NeighFlush(linkIndex, family int) (int, error) {
+	return 0, ErrNotImplemented
+}
+
 func (h *Handle) NeighProxyList(linkIndex, family int) ([]Neigh, error) {
 	return nil, ErrNotImplemented
 }
@@ -253,10 +349,30 @@ func (h *Handle) RouteListFiltered(family int, filter *Route, filterMask uint64)
 	return nil, ErrNotImplemented
 }
 
+func (h *Handle) RouteTableList(family int) ([]int, error) {
+	return nil, ErrNotImplemented
+}
+
 func (h *Handle) RouteReplace(route *Route) error {
 	return ErrNotImplemented
 }
 
+func (h *Handle) NexthopAdd(nh *Nexthop) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) NexthopReplace(nh *Nexthop) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) NexthopDel(nh *Nexthop) error {
+	return ErrNotImplemented
+}
+
+func (h *Handle) NexthopList(family int) ([]Nexthop, error) {
+	return nil, ErrNotImplemented
+}
+
 func (h *Handle) RuleAdd(rule *Rule) error {
 	return ErrNotImplemented
 }
@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"strconv"
+	"time"
 )
 
 // Link represents a link device from netlink. Shared link attributes
@@ -22,31 +23,46 @@ type (
 
 // LinkAttrs represents data shared by most link types
 type LinkAttrs struct {
-	Index        int
-	MTU          int
-	TxQLen       int // Transmit Queue Length
-	Name         string
-	HardwareAddr net.HardwareAddr
-	Flags        net.Flags
-	RawFlags     uint32
-	ParentIndex  int         // index of the parent link device
-	MasterIndex  int         // must be the index of a bridge
-	Namespace    interface{} // nil | NsPid | NsFd
-	Alias        string
-	Statistics   *LinkStatistics
-	Promisc      int
-	Xdp          *LinkXdp
-	EncapType    string
-	Protinfo     *Protinfo
-	OperState    LinkOperState
-	NetNsID      int
-	NumTxQueues  int
-	NumRxQueues  int
-	GSOMaxSize   uint32
-	GSOMaxSegs   uint32
-	Vfs          []VfInfo // virtual functions available on link
-	Group        uint32
-	Slave        LinkSlave
+	Index          int
+	MTU            int
+	TxQLen         int // Transmit Queue Length
+	Name           string
+	HardwareAddr   net.HardwareAddr
+	PermHWAddr     net.HardwareAddr // read only, the permanent (burned-in) address
+	Flags          net.Flags
+	RawFlags       uint32
+	ParentIndex    int         // index of the parent link device
+	MasterIndex    int         // must be the index of a bridge
+	Namespace      interface{} // nil | NsPid | NsFd
+	Alias          string
+	Statistics     *LinkStatistics
+	Promisc        int
+	Xdp            *LinkXdp
+	EncapType      string
+	Protinfo       *Protinfo
+	OperState      LinkOperState
+	LinkMode       LinkMode // read only, use LinkSetLinkMode to change it
+	NetNsID        int
+	NewNetNsID     int // read only, nsid of the target namespace when the link moved, valid if NewIndex is set
+	NewIndex       int // read only, index in the target namespace, only set in the RTM_DELLINK of a moved link
+	NumTxQueues    int // set on create only, a veth peer gets the same, tuntaps use Queues
+	NumRxQueues    int // set on create only, a veth peer gets the same, tuntaps use Queues
+	GSOMaxSize     uint32
+	GSOMaxSegs     uint32
+	Vfs            []VfInfo // virtual functions available on link
+	Group          uint32
+	Slave          LinkSlave
+	IPv4DevConf    *IPv4DevConf // read only, nil if the link has no IPv4 config
+	IPv6DevConf    *IPv6DevConf // read only, nil if the link has no IPv6 config
+	PhysPortName   string       // read only, name of the physical port of switchdev ports
+	PhysSwitchID   []byte       // read only, id of the switch the port belongs to
+	CarrierChanges uint32       // read only, number of carrier up and down events
+	AltNames       []string     // read only, use LinkAddAltName and LinkDelAltName to change them
+	Kind           string       // read only, the kind reported by the kernel, empty for hardware devices
+	SlaveKind      string       // read only, the kind of the master when the link is enslaved
+	// RawAttributes holds the link attributes not decoded above. They are
+	// sent back unchanged when the link is added.
+	RawAttributes []RawAttribute
 }
 
 // LinkSlave represents a slave device.
@@ -60,11 +76,16 @@ type VfInfo struct {
 	Mac       net.HardwareAddr
 	Vlan      int
 	Qos       int
+	VlanProto int // IFLA_VF_VLAN_LIST, 0 if the driver does not report it
 	TxRate    int // IFLA_VF_TX_RATE  Max TxRate
 	Spoofchk  bool
-	LinkState uint32
-	MaxTxRate uint32 // IFLA_VF_RATE Max TxRate
-	MinTxRate uint32 // IFLA_VF_RATE Min TxRate
+	LinkState uint32           // IFLA_VF_LINK_STATE, one of VF_LINK_STATE_*
+	MaxTxRate uint32           // IFLA_VF_RATE Max TxRate
+	MinTxRate uint32           // IFLA_VF_RATE Min TxRate
+	RssQuery  bool             // IFLA_VF_RSS_QUERY_EN
+	Trust     bool             // IFLA_VF_TRUST
+	NodeGUID  net.HardwareAddr // IFLA_VF_IB_NODE_GUID, Infiniband only
+	PortGUID  net.HardwareAddr // IFLA_VF_IB_PORT_GUID, Infiniband only
 }
 
 // LinkOperState represents the values of the IFLA_OPERSTATE link
@@ -100,6 +121,30 @@ func (s LinkOperState) String() string {
 	}
 }
 
+// LinkMode represents the values of the IFLA_LINKMODE link attribute,
+// which controls how the operational state of the interface follows its
+// carrier.
+type LinkMode uint8
+
+const (
+	LinkModeDefault LinkMode = iota // Up as soon as the carrier is.
+	LinkModeDormant                 // Dormant until userspace sets it up, like an 802.1X supplicant.
+	LinkModeTesting                 // In testing until userspace sets it up.
+)
+
+func (m LinkMode) String() string {
+	switch m {
+	case LinkModeDefault:
+		return "default"
+	case LinkModeDormant:
+		return "dormant"
+	case LinkModeTesting:
+		return "testing"
+	default:
+		return strconv.Itoa(int(m))
+	}
+}
+
 // NewLinkAttrs returns LinkAttrs structure filled with default values
 func NewLinkAttrs() LinkAttrs {
 	return LinkAttrs{
@@ -195,6 +240,76 @@ type LinkStatistics64 struct {
 	TxCompressed      uint64
 }
 
+// LinkStatsSample is a snapshot of the counters of a single link as
+// returned by LinkStatsPoll. Delta holds the change of every counter since
+// the previous sample of the same link and Interval the time elapsed
+// between both samples. The first sample of a link has a zero Delta,
+// Interval and rates. Err is only set on the last sample sent before the
+// poller stops because a dump failed.
+type LinkStatsSample struct {
+	Index    int
+	Name     string
+	Time     time.Time
+	Interval time.Duration
+	Stats    LinkStatistics
+	Delta    LinkStatistics
+
+	// Per second rates computed from Delta and Interval.
+	RxBytesRate   float64
+	TxBytesRate   float64
+	RxPacketsRate float64
+	TxPacketsRate float64
+
+	Err error
+}
+
+// LinkXStatistics are the link type specific extended statistics returned by
+// LinkXStats. Only the member matching the type of the link is set.
+type LinkXStatistics struct {
+	Bridge *BridgeXStats
+	Bond   *Bond3adStats
+}
+
+// BridgeXStats are the extended statistics of a bridge.
+type BridgeXStats struct {
+	Mcast *BridgeMcastStats
+}
+
+// BridgeMcastStats are the IGMP/MLD counters of a bridge. Counters with
+// two elements are indexed by direction: 0 for rx and 1 for tx.
+type BridgeMcastStats struct {
+	IgmpV1Queries   [2]uint64
+	IgmpV2Queries   [2]uint64
+	IgmpV3Queries   [2]uint64
+	IgmpLeaves      [2]uint64
+	IgmpV1Reports   [2]uint64
+	IgmpV2Reports   [2]uint64
+	IgmpV3Reports   [2]uint64
+	IgmpParseErrors uint64
+	MldV1Queries    [2]uint64
+	MldV2Queries    [2]uint64
+	MldLeaves       [2]uint64
+	MldV1Reports    [2]uint64
+	MldV2Reports    [2]uint64
+	MldParseErrors  uint64
+	McastBytes      [2]uint64
+	McastPackets    [2]uint64
+}
+
+// Bond3adStats are the LACPDU and marker counters of an 802.3ad bond,
+// summed over its slaves.
+type Bond3adStats struct {
+	LacpduRx        uint64
+	LacpduTx        uint64
+	LacpduUnknownRx uint64
+	LacpduIllegalRx uint64
+	MarkerRx        uint64
+	MarkerTx        uint64
+	MarkerRespRx    uint64
+	MarkerRespTx    uint64
+	MarkerUnknownRx uint64
+}
+
 type LinkXdp struct {
 	Fd       int
 	Attached bool
@@ -202,6 +317,31 @@ type LinkXdp struct {
 	ProgId   uint32
 }
 
+// IPv4DevConf is a subset of the per link IPv4 configuration found in
+// /proc/sys/net/ipv4/conf/$link, as reported by IFLA_INET_CONF.
+type IPv4DevConf struct {
+	Forwarding      int32
+	McForwarding    int32
+	ProxyArp        int32
+	AcceptRedirects int32
+	SendRedirects   int32
+	RpFilter        int32
+	AcceptLocal     int32
+}
+
+// IPv6DevConf is a subset of the per link IPv6 configuration found in
+// /proc/sys/net/ipv6/conf/$link, as reported by IFLA_INET6_CONF.
+type IPv6DevConf struct {
+	Forwarding      int32
+	HopLimit        int32
+	MTU             int32
+	AcceptRA        int32
+	AcceptRedirects int32
+	Autoconf        int32
+	DisableIPv6     int32
+	AcceptDAD       int32
+}
+
 // Device links cannot be created via netlink. These links
 // are links created by udev like 'lo' and 'etho0'
 type Device struct {
@@ -221,6 +361,13 @@ type Dummy struct {
 	LinkAttrs
 }
 
+// NewDummy returns a dummy link named name with default link attributes.
+func NewDummy(name string) *Dummy {
+	attrs := NewLinkAttrs()
+	attrs.Name = name
+	return &Dummy{LinkAttrs: attrs}
+}
+
 func (dummy *Dummy) Attrs() *LinkAttrs {
 	return &dummy.LinkAttrs
 }
@@ -234,6 +381,13 @@ type Ifb struct {
 	LinkAttrs
 }
 
+// NewIfb returns an ifb link named name with default link attributes.
+func NewIfb(name string) *Ifb {
+	attrs := NewLinkAttrs()
+	attrs.Name = name
+	return &Ifb{LinkAttrs: attrs}
+}
+
 func (ifb *Ifb) Attrs() *LinkAttrs {
 	return &ifb.LinkAttrs
 }
@@ -242,11 +396,47 @@ func (ifb *Ifb) Type() string {
 	return "ifb"
 }
 
-// Bridge links are simple linux bridges
+// Team links aggregate ports like bonds do, but the runner and port
+// configuration is done from userspace by teamd. Ports are added with
+// LinkSetMaster.
+type Team struct {
+	LinkAttrs
+}
+
+// NewTeam returns a team link named name with default link attributes.
+func NewTeam(name string) *Team {
+	attrs := NewLinkAttrs()
+	attrs.Name = name
+	return &Team{LinkAttrs: attrs}
+}
+
+func (team *Team) Attrs() *LinkAttrs {
+	return &team.LinkAttrs
+}
+
+func (team *Team) Type() string {
+	return "team"
+}
+
+// TeamSlave is the Slave of a port of a Team. The kernel reports no
+// port data, the port settings live in teamd.
+type TeamSlave struct{}
+
+func (t *TeamSlave) SlaveType() string {
+	return "team"
+}
+
+// Bridge links simulate an ethernet bridge. Timers are expressed in
+// hundredths of a second, like the kernel reports them.
 type Bridge struct {
 	LinkAttrs
 	MulticastSnooping *bool
 	HelloTime         *uint32
+	MaxAge            *uint32
+	ForwardDelay      *uint32
+	AgeingTime        *uint32
+	StpState          *uint32
+	Priority          *uint16
 	VlanFiltering     *bool
 }
 
@@ -338,6 +528,8 @@ type Veth struct {
 	LinkAttrs
 	PeerName         string // veth on create only
 	PeerHardwareAddr net.HardwareAddr
+	PeerMTU          int         // on create only, MTU is used when 0
+	PeerNamespace    interface{} // on create only, nil | NsPid | NsFd
 }
 
 func (veth *Veth) Attrs() *LinkAttrs {
@@ -349,7 +541,9 @@ func (veth *Veth) Type() string {
 }
 
 // GenericLink links represent types that are not currently understood
-// by this netlink library.
+// by this netlink library, like wireguard or can. LinkType holds the kind
+// reported by the kernel and the link data is ignored, the generic link
+// operations (LinkSetUp, LinkSetMTU, ...) work on them as on any link.
 type GenericLink struct {
 	LinkAttrs
 	LinkType string
@@ -363,6 +557,20 @@ func (generic *GenericLink) Type() string {
 	return generic.LinkType
 }
 
+// TUNNEL_TOS_INHERIT as the TOS of a vxlan or gre tunnel copies the TOS of
+// the inner packet to the outer header.
+const TUNNEL_TOS_INHERIT = 1
+
+// VxlanDf is the setting of the DF bit in the outer IPv4 header of vxlan
+// packets.
+type VxlanDf uint8
+
+const (
+	VXLAN_DF_UNSET   VxlanDf = iota // DF is never set (default)
+	VXLAN_DF_SET                    // DF is always set
+	VXLAN_DF_INHERIT                // DF is copied from the inner IPv4 header
+)
+
 type Vxlan struct {
 	LinkAttrs
 	VxlanId        int
@@ -370,7 +578,8 @@ type Vxlan struct {
 	SrcAddr        net.IP
 	Group          net.IP
 	TTL            int
-	TOS            int
+	TOS            int // TUNNEL_TOS_INHERIT copies the inner TOS
+	Df             VxlanDf
 	Learning       bool
 	Proxy          bool
 	RSC            bool
@@ -828,13 +1037,14 @@ type Gretap struct {
 	Remote     net.IP
 	IFlags     uint16
 	OFlags     uint16
-	PMtuDisc   uint8
+	PMtuDisc   uint8 // sets DF, without it DF is copied from the inner IPv4 header
 	Ttl        uint8
-	Tos        uint8
+	Tos        uint8 // TUNNEL_TOS_INHERIT copies the inner TOS
 	EncapType  uint16
 	EncapFlags uint16
 	Link       uint32
 	FlowBased  bool
+	IgnoreDf   bool // never set DF when PMtuDisc is off, IPv4 only
 }
 
 func (gretap *Gretap) Attrs() *LinkAttrs {
@@ -882,6 +1092,10 @@ type Ip6tnl struct {
 	Flags      uint32
 	Proto      uint8
 	FlowInfo   uint32
+	EncapSport uint16
+	EncapDport uint16
+	EncapType  uint16
+	EncapFlags uint16
 }
 
 func (ip6tnl *Ip6tnl) Attrs() *LinkAttrs {
@@ -944,12 +1158,13 @@ type Gretun struct {
 	Local      net.IP
 	Remote     net.IP
 	Ttl        uint8
-	Tos        uint8
-	PMtuDisc   uint8
+	Tos        uint8 // TUNNEL_TOS_INHERIT copies the inner TOS
+	PMtuDisc   uint8 // sets DF, without it DF is copied from the inner IPv4 header
 	EncapType  uint16
 	EncapFlags uint16
 	EncapSport uint16
 	EncapDport uint16
+	IgnoreDf   bool // never set DF when PMtuDisc is off, IPv4 only
 }
 
 func (gretun *Gretun) Attrs() *LinkAttrs {
@@ -976,6 +1191,9 @@ func (vrf *Vrf) Type() string {
 	return "vrf"
 }
 
+// GTP links are GTP-U tunnel endpoints. FD0 and FD1 are the UDP sockets
+// created by the GTP daemon for GTPv0 and GTPv1. PDPHashsize defaults to
+// 131072 when it is not set.
 type GTP struct {
 	LinkAttrs
 	FD0         int
@@ -992,6 +1210,26 @@ func (gtp *GTP) Type() string {
 	return "gtp"
 }
 
+// BareUDP links encapsulate packets of a single EtherType (e.g. MPLS) in
+// UDP without any additional tunnel header. Port is the UDP destination
+// port. With MultiProto set, an MPLS or IP device also handles the
+// multicast MPLS or IPv6 EtherTypes respectively.
+type BareUDP struct {
+	LinkAttrs
+	Port       uint16
+	EtherType  uint16
+	SrcPortMin uint16
+	MultiProto bool
+}
+
+func (bareudp *BareUDP) Attrs() *LinkAttrs {
+	return &bareudp.LinkAttrs
+}
+
+func (bareudp *BareUDP) Type() string {
+	return "bareudp"
+}
+
 // Virtual XFRM Interfaces
 //	Named "xfrmi" to prevent confusion with XFRM objects
 type Xfrmi struct {
@@ -1053,7 +1291,7 @@ func (ipoib *IPoIB) Type() string {
 // vlan | veth | vcan | dummy | ifb | macvlan | macvtap |
 // bridge | bond | ipoib | ip6tnl | ipip | sit | vxlan |
 // gre | gretap | ip6gre | ip6gretap | vti | vti6 | nlmon |
-// bond_slave | ipvlan | xfrm
+// bond_slave | ipvlan | xfrm | bareudp
 
 // LinkNotFoundError wraps the various not found errors when
 // getting/reading links. This is intended for better error
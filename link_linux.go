@@ -2,14 +2,17 @@ package netlink
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"os"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 	"unsafe"
 
 	"github.com/vishvananda/netlink/nl"
@@ -237,6 +240,53 @@ func (h *Handle) macvlanMACAddrChange(link Link, addrs []net.HardwareAddr, mode
 	return err
 }
 
+// LinkSetMacvlanMode changes the mode of an existing macvlan or macvtap
+// device and updates the Mode of link on success. The kernel refuses
+// changes from or to MACVLAN_MODE_PASSTHRU.
+// Equivalent to: `ip link set $link type macvlan mode $mode`
+func LinkSetMacvlanMode(link Link, mode MacvlanMode) error {
+	return pkgHandle.LinkSetMacvlanMode(link, mode)
+}
+
+// LinkSetMacvlanMode changes the mode of an existing macvlan or macvtap
+// device and updates the Mode of link on success. The kernel refuses
+// changes from or to MACVLAN_MODE_PASSTHRU.
+// Equivalent to: `ip link set $link type macvlan mode $mode`
+func (h *Handle) LinkSetMacvlanMode(link Link, mode MacvlanMode) error {
+	var macvlan *Macvlan
+	switch link := link.(type) {
+	case *Macvlan:
+		macvlan = link
+	case *Macvtap:
+		macvlan = &link.Macvlan
+	default:
+		return fmt.Errorf("%s is not a macvlan or macvtap device", link.Attrs().Name)
+	}
+	if mode == MACVLAN_MODE_DEFAULT || int(mode) >= len(macvlanModes) {
+		return fmt.Errorf("invalid macvlan mode %d", mode)
+	}
+	base := link.Attrs()
+	h.ensureIndex(base)
+	// link data can only be changed with RTM_NEWLINK, RTM_SETLINK ignores it
+	req := h.newNetlinkRequest(unix.RTM_NEWLINK, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	linkInfo := nl.NewRtAttr(unix.IFLA_LINKINFO, nil)
+	linkInfo.AddRtAttr(nl.IFLA_INFO_KIND, nl.NonZeroTerminated(link.Type()))
+	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
+	data.AddRtAttr(nl.IFLA_MACVLAN_MODE, nl.Uint32Attr(macvlanModes[mode]))
+	req.AddData(linkInfo)
+
+	if _, err := req.Execute(unix.NETLINK_ROUTE, 0); err != nil {
+		return err
+	}
+	macvlan.Mode = mode
+	return nil
+}
+
 func BridgeSetMcastSnoop(link Link, on bool) error {
 	return pkgHandle.BridgeSetMcastSnoop(link, on)
 }
@@ -244,7 +294,8 @@ func BridgeSetMcastSnoop(link Link, on bool) error {
 func (h *Handle) BridgeSetMcastSnoop(link Link, on bool) error {
 	bridge := link.(*Bridge)
 	bridge.MulticastSnooping = &on
-	return h.linkModify(bridge, unix.NLM_F_ACK)
+	_, _, err := h.linkModify(bridge, unix.NLM_F_ACK)
+	return err
 }
 
 func SetPromiscOn(link Link) error {
@@ -292,6 +343,46 @@ func (h *Handle) LinkSetUp(link Link) error {
 	return err
 }
 
+// linkWaitInterval is how often LinkSetUpAndWait polls the link.
+const linkWaitInterval = 10 * time.Millisecond
+
+// LinkSetUpAndWait enables the link device and waits until it is
+// operationally up, so it has carrier and can pass traffic, or returns an
+// error when that takes longer than timeout. Links that don't report an
+// operational state, like loopback, are up when they are running.
+// Equivalent to: `ip link set $link up`
+func LinkSetUpAndWait(link Link, timeout time.Duration) error {
+	return pkgHandle.LinkSetUpAndWait(link, timeout)
+}
+
+// LinkSetUpAndWait enables the link device and waits until it is
+// operationally up, so it has carrier and can pass traffic, or returns an
+// error when that takes longer than timeout. Links that don't report an
+// operational state, like loopback, are up when they are running.
+// Equivalent to: `ip link set $link up`
+func (h *Handle) LinkSetUpAndWait(link Link, timeout time.Duration) error {
+	if err := h.LinkSetUp(link); err != nil {
+		return err
+	}
+	base := link.Attrs()
+	deadline := time.Now().Add(timeout)
+	for {
+		l, err := h.LinkByIndex(base.Index)
+		if err != nil {
+			return err
+		}
+		attrs := l.Attrs()
+		if attrs.OperState == OperUp ||
+			attrs.OperState == OperUnknown && attrs.RawFlags&unix.IFF_RUNNING != 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Link %s is not up after %v, operstate is %s", attrs.Name, timeout, attrs.OperState)
+		}
+		time.Sleep(linkWaitInterval)
+	}
+}
+
 // LinkSetDown disables link device.
 // Equivalent to: `ip link set $link down`
 func LinkSetDown(link Link) error {
@@ -341,13 +432,15 @@ func (h *Handle) LinkSetMTU(link Link, mtu int) error {
 	return err
 }
 
-// LinkSetName sets the name of the link device.
+// LinkSetName sets the name of the link device. It fails with
+// unix.EEXIST if another link already has the name.
 // Equivalent to: `ip link set $link name $name`
 func LinkSetName(link Link, name string) error {
 	return pkgHandle.LinkSetName(link, name)
 }
 
-// LinkSetName sets the name of the link device.
+// LinkSetName sets the name of the link device. It fails with
+// unix.EEXIST if another link already has the name.
 // Equivalent to: `ip link set $link name $name`
 func (h *Handle) LinkSetName(link Link, name string) error {
 	base := link.Attrs()
@@ -365,13 +458,82 @@ func (h *Handle) LinkSetName(link Link, name string) error {
 	return err
 }
 
-// LinkSetAlias sets the alias of the link device.
+// LinkRenameSwap swaps the names of two link devices by moving the first
+// one to a temporary name. The kernel has no atomic swap, so for a short
+// time the name of the first link is not used by either link. A failed
+// rename is rolled back. Both links must exist, a LinkNotFoundError is
+// returned otherwise, and most drivers require them to be down.
+func LinkRenameSwap(a, b Link) error {
+	return pkgHandle.LinkRenameSwap(a, b)
+}
+
+// LinkRenameSwap swaps the names of two link devices by moving the first
+// one to a temporary name. The kernel has no atomic swap, so for a short
+// time the name of the first link is not used by either link. A failed
+// rename is rolled back. Both links must exist, a LinkNotFoundError is
+// returned otherwise, and most drivers require them to be down.
+func (h *Handle) LinkRenameSwap(a, b Link) error {
+	// use the current names, the ones in the attributes may be stale
+	linkA, err := h.linkByIndexOrName(a.Attrs())
+	if err != nil {
+		return err
+	}
+	linkB, err := h.linkByIndexOrName(b.Attrs())
+	if err != nil {
+		return err
+	}
+	nameA, nameB := linkA.Attrs().Name, linkB.Attrs().Name
+	if linkA.Attrs().Index == linkB.Attrs().Index {
+		return fmt.Errorf("can not swap the name of link %s with itself", nameA)
+	}
+
+	// the temporary name may be taken, retry with random ones
+	tmp := fmt.Sprintf("swap%x", linkA.Attrs().Index)
+	for i := 0; ; i++ {
+		err := h.LinkSetName(linkA, tmp)
+		if err == nil {
+			break
+		}
+		if err != unix.EEXIST || i == linkRenameSwapRetries {
+			return err
+		}
+		tmp = fmt.Sprintf("swap%x", rand.Uint32())
+	}
+	if err := h.LinkSetName(linkB, nameA); err != nil {
+		h.LinkSetName(linkA, nameA)
+		return err
+	}
+	if err := h.LinkSetName(linkA, nameB); err != nil {
+		h.LinkSetName(linkB, nameB)
+		h.LinkSetName(linkA, nameA)
+		return err
+	}
+	a.Attrs().Name, b.Attrs().Name = nameB, nameA
+	return nil
+}
+
+// linkRenameSwapRetries is the number of random temporary names
+// LinkRenameSwap tries when its temporary name is taken.
+const linkRenameSwapRetries = 8
+
+// linkByIndexOrName looks up a link by the index in base, or by its name
+// when the index is not set.
+func (h *Handle) linkByIndexOrName(base *LinkAttrs) (Link, error) {
+	if base.Index != 0 {
+		return h.LinkByIndex(base.Index)
+	}
+	return h.LinkByName(base.Name)
+}
+
+// LinkSetAlias sets the alias of the link device. An empty name clears
+// the alias.
 // Equivalent to: `ip link set dev $link alias $name`
 func LinkSetAlias(link Link, name string) error {
 	return pkgHandle.LinkSetAlias(link, name)
 }
 
-// LinkSetAlias sets the alias of the link device.
+// LinkSetAlias sets the alias of the link device. An empty name clears
+// the alias.
 // Equivalent to: `ip link set dev $link alias $name`
 func (h *Handle) LinkSetAlias(link Link, name string) error {
 	base := link.Attrs()
@@ -389,6 +551,49 @@ func (h *Handle) LinkSetAlias(link Link, name string) error {
 	return err
 }
 
+// LinkAddAltName adds an alternative name to the link device, which can
+// be longer than the 15 characters of an interface name.
+// Equivalent to: `ip link property add dev $link altname $name`
+func LinkAddAltName(link Link, name string) error {
+	return pkgHandle.LinkAddAltName(link, name)
+}
+
+// LinkAddAltName adds an alternative name to the link device, which can
+// be longer than the 15 characters of an interface name.
+// Equivalent to: `ip link property add dev $link altname $name`
+func (h *Handle) LinkAddAltName(link Link, name string) error {
+	return h.linkModifyAltName(link, name, nl.RTM_NEWLINKPROP)
+}
+
+// LinkDelAltName deletes an alternative name of the link device.
+// Equivalent to: `ip link property del dev $link altname $name`
+func LinkDelAltName(link Link, name string) error {
+	return pkgHandle.LinkDelAltName(link, name)
+}
+
+// LinkDelAltName deletes an alternative name of the link device.
+// Equivalent to: `ip link property del dev $link altname $name`
+func (h *Handle) LinkDelAltName(link Link, name string) error {
+	return h.linkModifyAltName(link, name, nl.RTM_DELLINKPROP)
+}
+
+func (h *Handle) linkModifyAltName(link Link, name string, proto int) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(proto, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	props := nl.NewRtAttr(nl.IFLA_PROP_LIST|unix.NLA_F_NESTED, nil)
+	props.AddRtAttr(nl.IFLA_ALT_IFNAME, nl.ZeroTerminated(name))
+	req.AddData(props)
+
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
 // LinkSetHardwareAddr sets the hardware address of the link device.
 // Equivalent to: `ip link set $link address $hwaddr`
 func LinkSetHardwareAddr(link Link, hwaddr net.HardwareAddr) error {
@@ -504,6 +709,43 @@ func (h *Handle) LinkSetVfVlanQos(link Link, vf, vlan, qos int) error {
 	return err
 }
 
+// LinkSetVfVlanQosProto sets the vlan, qos priority and vlan protocol of a
+// vf for the link. proto is VLAN_PROTOCOL_8021Q or VLAN_PROTOCOL_8021AD.
+// Equivalent to: `ip link set $link vf $vf vlan $vlan qos $qos proto $proto`
+func LinkSetVfVlanQosProto(link Link, vf, vlan, qos int, proto uint16) error {
+	return pkgHandle.LinkSetVfVlanQosProto(link, vf, vlan, qos, proto)
+}
+
+// LinkSetVfVlanQosProto sets the vlan, qos priority and vlan protocol of a
+// vf for the link. proto is VLAN_PROTOCOL_8021Q or VLAN_PROTOCOL_8021AD.
+// Equivalent to: `ip link set $link vf $vf vlan $vlan qos $qos proto $proto`
+func (h *Handle) LinkSetVfVlanQosProto(link Link, vf, vlan, qos int, proto uint16) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	data := nl.NewRtAttr(unix.IFLA_VFINFO_LIST, nil)
+	info := data.AddRtAttr(nl.IFLA_VF_INFO, nil)
+	vlanList := info.AddRtAttr(nl.IFLA_VF_VLAN_LIST, nil)
+	vfmsg := nl.VfVlanInfo{
+		VfVlan: nl.VfVlan{
+			Vf:   uint32(vf),
+			Vlan: uint32(vlan),
+			Qos:  uint32(qos),
+		},
+		VlanProto: proto,
+	}
+	vlanList.AddRtAttr(nl.IFLA_VF_VLAN_INFO, vfmsg.Serialize())
+	req.AddData(data)
+
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
 // LinkSetVfTxRate sets the tx rate of a vf for the link.
 // Equivalent to: `ip link set $link vf $vf rate $rate`
 func LinkSetVfTxRate(link Link, vf, rate int) error {
@@ -565,15 +807,22 @@ func (h *Handle) LinkSetVfRate(link Link, vf, minRate, maxRate int) error {
 	return err
 }
 
-// LinkSetVfState enables/disables virtual link state on a vf.
+// LinkSetVfState sets the link state of a vf to one of VF_LINK_STATE_AUTO,
+// which follows the link of the PF, VF_LINK_STATE_ENABLE or
+// VF_LINK_STATE_DISABLE, which force the link of the vf up or down.
 // Equivalent to: `ip link set $link vf $vf state $state`
 func LinkSetVfState(link Link, vf int, state uint32) error {
 	return pkgHandle.LinkSetVfState(link, vf, state)
 }
 
-// LinkSetVfState enables/disables virtual link state on a vf.
+// LinkSetVfState sets the link state of a vf to one of VF_LINK_STATE_AUTO,
+// which follows the link of the PF, VF_LINK_STATE_ENABLE or
+// VF_LINK_STATE_DISABLE, which force the link of the vf up or down.
 // Equivalent to: `ip link set $link vf $vf state $state`
 func (h *Handle) LinkSetVfState(link Link, vf int, state uint32) error {
+	if state > VF_LINK_STATE_DISABLE {
+		return fmt.Errorf("invalid vf link state %d", state)
+	}
 	base := link.Attrs()
 	h.ensureIndex(base)
 	req := h.newNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
@@ -663,6 +912,42 @@ func (h *Handle) LinkSetVfTrust(link Link, vf int, state bool) error {
 	return err
 }
 
+// LinkSetVfRssQuery enables/disables querying the RSS redirection table
+// and hash key of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf query_rss $state`
+func LinkSetVfRssQuery(link Link, vf int, state bool) error {
+	return pkgHandle.LinkSetVfRssQuery(link, vf, state)
+}
+
+// LinkSetVfRssQuery enables/disables querying the RSS redirection table
+// and hash key of a vf for the link.
+// Equivalent to: `ip link set $link vf $vf query_rss $state`
+func (h *Handle) LinkSetVfRssQuery(link Link, vf int, state bool) error {
+	var setting uint32
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	data := nl.NewRtAttr(unix.IFLA_VFINFO_LIST, nil)
+	info := data.AddRtAttr(nl.IFLA_VF_INFO, nil)
+	if state {
+		setting = 1
+	}
+	vfmsg := nl.VfRssQueryEn{
+		Vf:      uint32(vf),
+		Setting: setting,
+	}
+	info.AddRtAttr(nl.IFLA_VF_RSS_QUERY_EN, vfmsg.Serialize())
+	req.AddData(data)
+
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
 // LinkSetVfNodeGUID sets the node GUID of a vf for the link.
 // Equivalent to: `ip link set dev $link vf $vf node_guid $nodeguid`
 func LinkSetVfNodeGUID(link Link, vf int, nodeguid net.HardwareAddr) error {
@@ -826,17 +1111,38 @@ func (h *Handle) LinkSetNsFd(link Link, fd int) error {
 }
 
 // LinkSetXdpFd adds a bpf function to the driver. The fd must be a bpf
-// program loaded with bpf(type=BPF_PROG_TYPE_XDP)
+// program loaded with bpf(type=BPF_PROG_TYPE_XDP). An fd of -1 detaches
+// the current program.
 func LinkSetXdpFd(link Link, fd int) error {
-	return LinkSetXdpFdWithFlags(link, fd, 0)
+	return pkgHandle.LinkSetXdpFd(link, fd)
+}
+
+// LinkSetXdpFd adds a bpf function to the driver. The fd must be a bpf
+// program loaded with bpf(type=BPF_PROG_TYPE_XDP). An fd of -1 detaches
+// the current program.
+func (h *Handle) LinkSetXdpFd(link Link, fd int) error {
+	return h.LinkSetXdpFdWithFlags(link, fd, 0)
 }
 
 // LinkSetXdpFdWithFlags adds a bpf function to the driver with the given
-// options. The fd must be a bpf program loaded with bpf(type=BPF_PROG_TYPE_XDP)
+// options. The fd must be a bpf program loaded with bpf(type=BPF_PROG_TYPE_XDP).
+// The flags are a combination of the nl.XDP_FLAGS_* values, e.g. to select
+// the generic (skb), driver or hardware mode.
 func LinkSetXdpFdWithFlags(link Link, fd, flags int) error {
+	return pkgHandle.LinkSetXdpFdWithFlags(link, fd, flags)
+}
+
+// LinkSetXdpFdWithFlags adds a bpf function to the driver with the given
+// options. The fd must be a bpf program loaded with bpf(type=BPF_PROG_TYPE_XDP).
+// The flags are a combination of the nl.XDP_FLAGS_* values, e.g. to select
+// the generic (skb), driver or hardware mode.
+func (h *Handle) LinkSetXdpFdWithFlags(link Link, fd, flags int) error {
+	if flags&^nl.XDP_FLAGS_MASK != 0 {
+		return fmt.Errorf("invalid xdp flags 0x%x", flags)
+	}
 	base := link.Attrs()
-	ensureIndex(base)
-	req := nl.NewNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
 
 	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
 	msg.Index = int32(base.Index)
@@ -897,6 +1203,9 @@ func addVxlanAttrs(vxlan *Vxlan, linkInfo *nl.RtAttr) {
 
 	data.AddRtAttr(nl.IFLA_VXLAN_TTL, nl.Uint8Attr(uint8(vxlan.TTL)))
 	data.AddRtAttr(nl.IFLA_VXLAN_TOS, nl.Uint8Attr(uint8(vxlan.TOS)))
+	if vxlan.Df != VXLAN_DF_UNSET {
+		data.AddRtAttr(nl.IFLA_VXLAN_DF, nl.Uint8Attr(uint8(vxlan.Df)))
+	}
 	data.AddRtAttr(nl.IFLA_VXLAN_LEARNING, boolAttr(vxlan.Learning))
 	data.AddRtAttr(nl.IFLA_VXLAN_PROXY, boolAttr(vxlan.Proxy))
 	data.AddRtAttr(nl.IFLA_VXLAN_RSC, boolAttr(vxlan.RSC))
@@ -1035,20 +1344,66 @@ func cleanupFds(fds []*os.File) {
 }
 
 // LinkAdd adds a new link device. The type and features of the device
-// are taken from the parameters in the link object.
+// are taken from the parameters in the link object. On success the index
+// assigned by the kernel is set in the attributes of the link.
 // Equivalent to: `ip link add $link`
 func LinkAdd(link Link) error {
 	return pkgHandle.LinkAdd(link)
 }
 
 // LinkAdd adds a new link device. The type and features of the device
-// are taken from the parameters in the link object.
+// are taken from the parameters in the link object. On success the index
+// assigned by the kernel is set in the attributes of the link.
 // Equivalent to: `ip link add $link`
 func (h *Handle) LinkAdd(link Link) error {
-	return h.linkModify(link, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ACK)
+	_, _, err := h.linkModify(link, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ACK)
+	return err
+}
+
+// LinkAddAndGet adds a new link device like LinkAdd and returns the link as
+// reported by the kernel, including its assigned index. The index is also
+// set in the attributes of the passed link.
+func LinkAddAndGet(link Link) (Link, error) {
+	return pkgHandle.LinkAddAndGet(link)
+}
+
+// LinkAddAndGet adds a new link device like LinkAdd and returns the link as
+// reported by the kernel, including its assigned index. The index is also
+// set in the attributes of the passed link.
+func (h *Handle) LinkAddAndGet(link Link) (Link, error) {
+	newlink, lookupErr, err := h.linkModify(link, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ACK)
+	if err != nil {
+		return nil, err
+	}
+	if lookupErr != nil {
+		return nil, lookupErr
+	}
+	if newlink == nil {
+		// the index was given, so the link has not been looked up yet
+		return h.LinkByIndex(link.Attrs().Index)
+	}
+	return newlink, nil
 }
 
-func (h *Handle) linkModify(link Link, flags int) error {
+// lookupIndex looks up a link added without an index and sets the index
+// assigned by the kernel in its attributes. It returns the link as
+// reported by the kernel, or nil when the index was already set.
+func (h *Handle) lookupIndex(base *LinkAttrs) (Link, error) {
+	if base.Index != 0 {
+		return nil, nil
+	}
+	newlink, err := h.LinkByName(base.Name)
+	if err != nil {
+		return nil, err
+	}
+	base.Index = newlink.Attrs().Index
+	return newlink, nil
+}
+
+// linkModify sends the link and returns it as looked up to set the index
+// assigned by the kernel, nil when the index was already set. A failed
+// lookup doesn't fail the change, its error is returned on its own.
+func (h *Handle) linkModify(link Link, flags int) (Link, error, error) {
 	// TODO: support extra data for macvlan
 	base := link.Attrs()
 
@@ -1056,14 +1411,14 @@ func (h *Handle) linkModify(link Link, flags int) error {
 	tuntap, isTuntap := link.(*Tuntap)
 
 	if base.Name == "" && !isTuntap {
-		return fmt.Errorf("LinkAttrs.Name cannot be empty")
+		return nil, nil, fmt.Errorf("LinkAttrs.Name cannot be empty")
 	}
 
 	if isTuntap {
 		// TODO: support user
 		// TODO: support group
 		if tuntap.Mode < unix.IFF_TUN || tuntap.Mode > unix.IFF_TAP {
-			return fmt.Errorf("Tuntap.Mode %v unknown", tuntap.Mode)
+			return nil, nil, fmt.Errorf("Tuntap.Mode %v unknown", tuntap.Mode)
 		}
 
 		queues := tuntap.Queues
@@ -1095,14 +1450,14 @@ func (h *Handle) linkModify(link Link, flags int) error {
 			file, err := os.OpenFile("/dev/net/tun", os.O_RDWR, 0)
 			if err != nil {
 				cleanupFds(fds)
-				return err
+				return nil, nil, err
 			}
 
 			fds = append(fds, file)
 			_, _, errno := unix.Syscall(unix.SYS_IOCTL, file.Fd(), uintptr(unix.TUNSETIFF), uintptr(unsafe.Pointer(&localReq)))
 			if errno != 0 {
 				cleanupFds(fds)
-				return fmt.Errorf("Tuntap IOCTL TUNSETIFF failed [%d], errno %v", i, errno)
+				return nil, nil, fmt.Errorf("Tuntap IOCTL TUNSETIFF failed [%d], errno %v", i, errno)
 			}
 			// 1) we only care for the name of the first tap in the multi queue set
 			// 2) if the original name was empty, the localReq has now the actual name
@@ -1121,11 +1476,11 @@ func (h *Handle) linkModify(link Link, flags int) error {
 			_, _, errno := unix.Syscall(unix.SYS_IOCTL, fds[0].Fd(), uintptr(unix.TUNSETPERSIST), 1)
 			if errno != 0 {
 				cleanupFds(fds)
-				return fmt.Errorf("Tuntap IOCTL TUNSETPERSIST failed, errno %v", errno)
+				return nil, nil, fmt.Errorf("Tuntap IOCTL TUNSETPERSIST failed, errno %v", errno)
 			}
 		}
 
-		h.ensureIndex(base)
+		newlink, lookupErr := h.lookupIndex(base)
 
 		// can't set master during create, so set it afterwards
 		if base.MasterIndex != 0 {
@@ -1138,7 +1493,10 @@ func (h *Handle) linkModify(link Link, flags int) error {
 					_, _, _ = unix.Syscall(unix.SYS_IOCTL, fds[0].Fd(), uintptr(unix.TUNSETPERSIST), 0)
 				}
 				cleanupFds(fds)
-				return err
+				return nil, nil, err
+			}
+			if newlink != nil {
+				newlink.Attrs().MasterIndex = base.MasterIndex
 			}
 		}
 
@@ -1148,7 +1506,7 @@ func (h *Handle) linkModify(link Link, flags int) error {
 			tuntap.Fds = fds
 		}
 
-		return nil
+		return newlink, lookupErr, nil
 	}
 
 	req := h.newNetlinkRequest(unix.RTM_NEWLINK, flags)
@@ -1187,7 +1545,7 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		data := nl.NewRtAttr(unix.IFLA_LINK, b)
 		req.AddData(data)
 	} else if link.Type() == "ipvlan" || link.Type() == "ipoib" {
-		return fmt.Errorf("Can't create %s link without ParentIndex", link.Type())
+		return nil, nil, fmt.Errorf("Can't create %s link without ParentIndex", link.Type())
 	}
 
 	nameData := nl.NewRtAttr(unix.IFLA_IFNAME, nl.ZeroTerminated(base.Name))
@@ -1251,6 +1609,10 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		addXdpAttrs(base.Xdp, req)
 	}
 
+	for _, raw := range base.RawAttributes {
+		req.AddData(nl.NewRtAttr(int(raw.Type), raw.Value))
+	}
+
 	linkInfo := nl.NewRtAttr(unix.IFLA_LINKINFO, nil)
 	linkInfo.AddRtAttr(nl.IFLA_INFO_KIND, nl.NonZeroTerminated(link.Type()))
 
@@ -1272,12 +1634,26 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		if base.TxQLen >= 0 {
 			peer.AddRtAttr(unix.IFLA_TXQLEN, nl.Uint32Attr(uint32(base.TxQLen)))
 		}
-		if base.MTU > 0 {
+		if base.NumTxQueues > 0 {
+			peer.AddRtAttr(unix.IFLA_NUM_TX_QUEUES, nl.Uint32Attr(uint32(base.NumTxQueues)))
+		}
+		if base.NumRxQueues > 0 {
+			peer.AddRtAttr(unix.IFLA_NUM_RX_QUEUES, nl.Uint32Attr(uint32(base.NumRxQueues)))
+		}
+		if link.PeerMTU > 0 {
+			peer.AddRtAttr(unix.IFLA_MTU, nl.Uint32Attr(uint32(link.PeerMTU)))
+		} else if base.MTU > 0 {
 			peer.AddRtAttr(unix.IFLA_MTU, nl.Uint32Attr(uint32(base.MTU)))
 		}
 		if link.PeerHardwareAddr != nil {
 			peer.AddRtAttr(unix.IFLA_ADDRESS, []byte(link.PeerHardwareAddr))
 		}
+		switch ns := link.PeerNamespace.(type) {
+		case NsPid:
+			peer.AddRtAttr(unix.IFLA_NET_NS_PID, nl.Uint32Attr(uint32(ns)))
+		case NsFd:
+			peer.AddRtAttr(unix.IFLA_NET_NS_FD, nl.Uint32Attr(uint32(ns)))
+		}
 	case *Vxlan:
 		addVxlanAttrs(link, linkInfo)
 	case *Bond:
@@ -1297,7 +1673,9 @@ func (h *Handle) linkModify(link Link, flags int) error {
 			data.AddRtAttr(nl.IFLA_MACVLAN_MODE, nl.Uint32Attr(macvlanModes[link.Mode]))
 		}
 	case *Gretap:
-		addGretapAttrs(link, linkInfo)
+		if err := addGretapAttrs(link, linkInfo); err != nil {
+			return nil, nil, err
+		}
 	case *Iptun:
 		addIptunAttrs(link, linkInfo)
 	case *Ip6tnl:
@@ -1305,7 +1683,9 @@ func (h *Handle) linkModify(link Link, flags int) error {
 	case *Sittun:
 		addSittunAttrs(link, linkInfo)
 	case *Gretun:
-		addGretunAttrs(link, linkInfo)
+		if err := addGretunAttrs(link, linkInfo); err != nil {
+			return nil, nil, err
+		}
 	case *Vti:
 		addVtiAttrs(link, linkInfo)
 	case *Vrf:
@@ -1316,6 +1696,8 @@ func (h *Handle) linkModify(link Link, flags int) error {
 		addGTPAttrs(link, linkInfo)
 	case *Xfrmi:
 		addXfrmiAttrs(link, linkInfo)
+	case *BareUDP:
+		addBareUDPAttrs(link, linkInfo)
 	case *IPoIB:
 		addIPoIBAttrs(link, linkInfo)
 	}
@@ -1324,17 +1706,22 @@ func (h *Handle) linkModify(link Link, flags int) error {
 
 	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	h.ensureIndex(base)
+	newlink, lookupErr := h.lookupIndex(base)
 
 	// can't set master during create, so set it afterwards
 	if base.MasterIndex != 0 {
 		// TODO: verify MasterIndex is actually a bridge?
-		return h.LinkSetMasterByIndex(link, base.MasterIndex)
+		if err := h.LinkSetMasterByIndex(link, base.MasterIndex); err != nil {
+			return nil, nil, err
+		}
+		if newlink != nil {
+			newlink.Attrs().MasterIndex = base.MasterIndex
+		}
 	}
-	return nil
+	return newlink, lookupErr, nil
 }
 
 // LinkDel deletes link device. Either Index or Name must be set in
@@ -1390,12 +1777,32 @@ func (h *Handle) linkByAliasDump(alias string) (Link, error) {
 	return nil, LinkNotFoundError{fmt.Errorf("Link alias %s not found", alias)}
 }
 
+func (h *Handle) linkByAltNameDump(name string) (Link, error) {
+	links, err := h.LinkList()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, link := range links {
+		for _, altName := range link.Attrs().AltNames {
+			if altName == name {
+				return link, nil
+			}
+		}
+	}
+	return nil, LinkNotFoundError{fmt.Errorf("Link altname %s not found", name)}
+}
+
 // LinkByName finds a link by name and returns a pointer to the object.
+// The link is requested from the kernel by name, kernels that don't
+// support this fall back to dumping all links.
 func LinkByName(name string) (Link, error) {
 	return pkgHandle.LinkByName(name)
 }
 
 // LinkByName finds a link by name and returns a pointer to the object.
+// The link is requested from the kernel by name, kernels that don't
+// support this fall back to dumping all links.
 func (h *Handle) LinkByName(name string) (Link, error) {
 	if h.lookupByDump {
 		return h.linkByNameDump(name)
@@ -1449,15 +1856,48 @@ func (h *Handle) LinkByAlias(alias string) (Link, error) {
 
 	link, err := execGetLink(req)
 	if err == unix.EINVAL {
-		// older kernels don't support looking up via IFLA_IFALIAS
-		// so fall back to dumping all links
-		h.lookupByDump = true
+		// the kernel doesn't support looking up via IFLA_IFALIAS so fall
+		// back to dumping all links, without giving up on name lookups
 		return h.linkByAliasDump(alias)
 	}
 
 	return link, err
 }
 
+// LinkByAltName finds a link by one of its alternative names and returns
+// a pointer to the object, the same as looking it up by its name.
+func LinkByAltName(name string) (Link, error) {
+	return pkgHandle.LinkByAltName(name)
+}
+
+// LinkByAltName finds a link by one of its alternative names and returns
+// a pointer to the object, the same as looking it up by its name.
+func (h *Handle) LinkByAltName(name string) (Link, error) {
+	if h.lookupByDump {
+		return h.linkByAltNameDump(name)
+	}
+
+	req := h.newNetlinkRequest(unix.RTM_GETLINK, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	req.AddData(msg)
+
+	attr := nl.NewRtAttr(unix.IFLA_EXT_MASK, nl.Uint32Attr(nl.RTEXT_FILTER_VF))
+	req.AddData(attr)
+
+	nameData := nl.NewRtAttr(nl.IFLA_ALT_IFNAME, nl.ZeroTerminated(name))
+	req.AddData(nameData)
+
+	link, err := execGetLink(req)
+	if err == unix.EINVAL {
+		// the kernel doesn't support looking up via IFLA_ALT_IFNAME so
+		// fall back to dumping all links, without giving up on name lookups
+		return h.linkByAltNameDump(name)
+	}
+
+	return link, err
+}
+
 // LinkByIndex finds a link by index and returns a pointer to the object.
 func LinkByIndex(index int) (Link, error) {
 	return pkgHandle.LinkByIndex(index)
@@ -1531,12 +1971,14 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 			for _, info := range infos {
 				switch info.Attr.Type {
 				case nl.IFLA_INFO_KIND:
-					linkType = string(info.Value[:len(info.Value)-1])
+					linkType = strings.TrimRight(string(info.Value), "\x00")
 					switch linkType {
 					case "dummy":
 						link = &Dummy{}
 					case "ifb":
 						link = &Ifb{}
+					case "team":
+						link = &Team{}
 					case "bridge":
 						link = &Bridge{}
 					case "vlan":
@@ -1575,6 +2017,8 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 						link = &GTP{}
 					case "xfrm":
 						link = &Xfrmi{}
+					case "bareudp":
+						link = &BareUDP{}
 					case "tun":
 						link = &Tuntap{}
 					case "ipoib":
@@ -1583,6 +2027,11 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 						link = &GenericLink{LinkType: linkType}
 					}
 				case nl.IFLA_INFO_DATA:
+					if _, ok := link.(*GenericLink); ok {
+						// the data of kinds that are not modeled
+						// is not necessarily made of attributes
+						continue
+					}
 					data, err := nl.ParseRouteAttr(info.Value)
 					if err != nil {
 						return nil, err
@@ -1624,16 +2073,20 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 						parseGTPData(link, data)
 					case "xfrm":
 						parseXfrmiData(link, data)
+					case "bareudp":
+						parseBareUDPData(link, data)
 					case "tun":
 						parseTuntapData(link, data)
 					case "ipoib":
 						parseIPoIBData(link, data)
 					}
 				case nl.IFLA_INFO_SLAVE_KIND:
-					slaveType = string(info.Value[:len(info.Value)-1])
+					slaveType = strings.TrimRight(string(info.Value), "\x00")
 					switch slaveType {
 					case "bond":
 						linkSlave = &BondSlave{}
+					case "team":
+						linkSlave = &TeamSlave{}
 					}
 				case nl.IFLA_INFO_SLAVE_DATA:
 					switch slaveType {
@@ -1656,8 +2109,29 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 			if nonzero {
 				base.HardwareAddr = attr.Value[:]
 			}
+		case nl.IFLA_PROP_LIST | unix.NLA_F_NESTED:
+			props, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, prop := range props {
+				if prop.Attr.Type == nl.IFLA_ALT_IFNAME {
+					base.AltNames = append(base.AltNames, strings.TrimRight(string(prop.Value), "\x00"))
+				}
+			}
+		case nl.IFLA_PERM_ADDRESS:
+			for _, b := range attr.Value {
+				if b != 0 {
+					base.PermHWAddr = attr.Value[:]
+					break
+				}
+			}
 		case unix.IFLA_IFNAME:
 			base.Name = string(attr.Value[:len(attr.Value)-1])
+		case unix.IFLA_PHYS_PORT_NAME:
+			base.PhysPortName = nl.BytesToString(attr.Value)
+		case unix.IFLA_PHYS_SWITCH_ID:
+			base.PhysSwitchID = append([]byte(nil), attr.Value...)
 		case unix.IFLA_MTU:
 			base.MTU = int(native.Uint32(attr.Value[0:4]))
 		case unix.IFLA_LINK:
@@ -1667,7 +2141,7 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 		case unix.IFLA_TXQLEN:
 			base.TxQLen = int(native.Uint32(attr.Value[0:4]))
 		case unix.IFLA_IFALIAS:
-			base.Alias = string(attr.Value[:len(attr.Value)-1])
+			base.Alias = string(bytes.TrimRight(attr.Value, "\x00"))
 		case unix.IFLA_STATS:
 			stats32 = new(LinkStatistics32)
 			if err := binary.Read(bytes.NewBuffer(attr.Value[:]), nl.NativeEndian(), stats32); err != nil {
@@ -1694,10 +2168,18 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 				protinfo := parseProtinfo(attrs)
 				base.Protinfo = &protinfo
 			}
+		case unix.IFLA_LINKMODE:
+			base.LinkMode = LinkMode(attr.Value[0])
 		case unix.IFLA_OPERSTATE:
 			base.OperState = LinkOperState(uint8(attr.Value[0]))
 		case unix.IFLA_LINK_NETNSID:
 			base.NetNsID = int(native.Uint32(attr.Value[0:4]))
+		case unix.IFLA_NEW_NETNSID:
+			base.NewNetNsID = int(int32(native.Uint32(attr.Value[0:4])))
+		case unix.IFLA_NEW_IFINDEX:
+			base.NewIndex = int(int32(native.Uint32(attr.Value[0:4])))
+		case unix.IFLA_CARRIER_CHANGES:
+			base.CarrierChanges = native.Uint32(attr.Value[0:4])
 		case unix.IFLA_GSO_MAX_SIZE:
 			base.GSOMaxSize = native.Uint32(attr.Value[0:4])
 		case unix.IFLA_GSO_MAX_SEGS:
@@ -1718,6 +2200,22 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 			base.NumRxQueues = int(native.Uint32(attr.Value[0:4]))
 		case unix.IFLA_GROUP:
 			base.Group = native.Uint32(attr.Value[0:4])
+		case unix.IFLA_AF_SPEC:
+			// bridge messages use IFLA_AF_SPEC for vlan information
+			if msg.Family == unix.AF_BRIDGE {
+				break
+			}
+			if err := parseAfSpec(&base, attr.Value); err != nil {
+				return nil, err
+			}
+		case unix.IFLA_MAP, nl.IFLA_TSO_MAX_SIZE, nl.IFLA_TSO_MAX_SEGS, nl.IFLA_ALLMULTI,
+			nl.IFLA_DEVLINK_PORT | unix.NLA_F_NESTED, nl.IFLA_DPLL_PIN | unix.NLA_F_NESTED,
+			nl.IFLA_MAX_PACING_OFFLOAD_HORIZON, nl.IFLA_NETNS_IMMUTABLE, nl.IFLA_HEADROOM, nl.IFLA_TAILROOM:
+			// Read only, rejected when sent back
+		default:
+			value := make([]byte, len(attr.Value))
+			copy(value, attr.Value)
+			base.RawAttributes = append(base.RawAttributes, RawAttribute{Type: attr.Attr.Type, Value: value})
 		}
 	}
 
@@ -1731,6 +2229,8 @@ func LinkDeserialize(hdr *unix.NlMsghdr, m []byte) (Link, error) {
 	if link == nil {
 		link = &Device{}
 	}
+	base.Kind = linkType
+	base.SlaveKind = slaveType
 	*link.Attrs() = base
 	link.Attrs().Slave = linkSlave
 
@@ -1794,6 +2294,18 @@ func LinkList() ([]Link, error) {
 // LinkList gets a list of link devices.
 // Equivalent to: `ip link show`
 func (h *Handle) LinkList() ([]Link, error) {
+	return h.LinkListContext(context.Background())
+}
+
+// LinkListContext works like LinkList, but gives up waiting for the kernel
+// and returns ctx.Err() once ctx is done.
+func LinkListContext(ctx context.Context) ([]Link, error) {
+	return pkgHandle.LinkListContext(ctx)
+}
+
+// LinkListContext works like LinkList, but gives up waiting for the kernel
+// and returns ctx.Err() once ctx is done.
+func (h *Handle) LinkListContext(ctx context.Context) ([]Link, error) {
 	// NOTE(vish): This duplicates functionality in net/iface_linux.go, but we need
 	//             to get the message ourselves to parse link type.
 	req := h.newNetlinkRequest(unix.RTM_GETLINK, unix.NLM_F_DUMP)
@@ -1803,7 +2315,7 @@ func (h *Handle) LinkList() ([]Link, error) {
 	attr := nl.NewRtAttr(unix.IFLA_EXT_MASK, nl.Uint32Attr(nl.RTEXT_FILTER_VF))
 	req.AddData(attr)
 
-	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWLINK)
+	msgs, err := req.ExecuteContext(ctx, unix.NETLINK_ROUTE, unix.RTM_NEWLINK)
 	if err != nil {
 		return nil, err
 	}
@@ -1821,6 +2333,11 @@ func (h *Handle) LinkList() ([]Link, error) {
 }
 
 // LinkUpdate is used to pass information back from LinkSubscribe()
+//
+// The kernel has no generation counter for links, an index may be reused
+// by a new link once the old one is deleted. A RTM_DELLINK for an index
+// means any later RTM_NEWLINK for it is a different link, unless NewIndex
+// is set in which case the link itself moved to another namespace.
 type LinkUpdate struct {
 	nl.IfInfomsg
 	Header unix.NlMsghdr
@@ -1950,10 +2467,16 @@ func (h *Handle) LinkSetFastLeave(link Link, mode bool) error {
 	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_FAST_LEAVE)
 }
 
+// LinkSetLearning sets whether the bridge learns the source addresses
+// of frames received on the bridge port.
+// Equivalent to: `bridge link set dev $link learning on|off`
 func LinkSetLearning(link Link, mode bool) error {
 	return pkgHandle.LinkSetLearning(link, mode)
 }
 
+// LinkSetLearning sets whether the bridge learns the source addresses
+// of frames received on the bridge port.
+// Equivalent to: `bridge link set dev $link learning on|off`
 func (h *Handle) LinkSetLearning(link Link, mode bool) error {
 	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_LEARNING)
 }
@@ -1966,10 +2489,16 @@ func (h *Handle) LinkSetRootBlock(link Link, mode bool) error {
 	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_PROTECT)
 }
 
+// LinkSetFlood sets whether unicast traffic to unknown destinations is
+// flooded to the bridge port.
+// Equivalent to: `bridge link set dev $link flood on|off`
 func LinkSetFlood(link Link, mode bool) error {
 	return pkgHandle.LinkSetFlood(link, mode)
 }
 
+// LinkSetFlood sets whether unicast traffic to unknown destinations is
+// flooded to the bridge port.
+// Equivalent to: `bridge link set dev $link flood on|off`
 func (h *Handle) LinkSetFlood(link Link, mode bool) error {
 	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_UNICAST_FLOOD)
 }
@@ -1990,7 +2519,69 @@ func (h *Handle) LinkSetBrProxyArpWiFi(link Link, mode bool) error {
 	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_PROXYARP_WIFI)
 }
 
+// LinkSetBrNeighSuppress sets whether ARP and ND requests for addresses
+// known to the bridge are answered locally instead of being flooded to
+// the bridge port. This is mostly used on VXLAN bridge ports.
+// Equivalent to: `bridge link set dev $link neigh_suppress on|off`
+func LinkSetBrNeighSuppress(link Link, mode bool) error {
+	return pkgHandle.LinkSetBrNeighSuppress(link, mode)
+}
+
+// LinkSetBrNeighSuppress sets whether ARP and ND requests for addresses
+// known to the bridge are answered locally instead of being flooded to
+// the bridge port. This is mostly used on VXLAN bridge ports.
+// Equivalent to: `bridge link set dev $link neigh_suppress on|off`
+func (h *Handle) LinkSetBrNeighSuppress(link Link, mode bool) error {
+	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_NEIGH_SUPPRESS)
+}
+
+// LinkSetMcastFlood sets whether multicast traffic with no known
+// subscriber is flooded to the bridge port.
+// Equivalent to: `bridge link set dev $link mcast_flood on|off`
+func LinkSetMcastFlood(link Link, mode bool) error {
+	return pkgHandle.LinkSetMcastFlood(link, mode)
+}
+
+// LinkSetMcastFlood sets whether multicast traffic with no known
+// subscriber is flooded to the bridge port.
+// Equivalent to: `bridge link set dev $link mcast_flood on|off`
+func (h *Handle) LinkSetMcastFlood(link Link, mode bool) error {
+	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_MCAST_FLOOD)
+}
+
+// LinkSetMcastToUcast sets whether multicast traffic is sent to the
+// bridge port as unicast.
+// Equivalent to: `bridge link set dev $link mcast_to_unicast on|off`
+func LinkSetMcastToUcast(link Link, mode bool) error {
+	return pkgHandle.LinkSetMcastToUcast(link, mode)
+}
+
+// LinkSetMcastToUcast sets whether multicast traffic is sent to the
+// bridge port as unicast.
+// Equivalent to: `bridge link set dev $link mcast_to_unicast on|off`
+func (h *Handle) LinkSetMcastToUcast(link Link, mode bool) error {
+	return h.setProtinfoAttr(link, mode, nl.IFLA_BRPORT_MCAST_TO_UCAST)
+}
+
+// LinkSetMulticastRouter sets the multicast router mode of the bridge port
+// to one of the MDB_RTR_TYPE_* constants.
+// Equivalent to: `bridge link set dev $link mcast_router $router`
+func LinkSetMulticastRouter(link Link, router uint8) error {
+	return pkgHandle.LinkSetMulticastRouter(link, router)
+}
+
+// LinkSetMulticastRouter sets the multicast router mode of the bridge port
+// to one of the MDB_RTR_TYPE_* constants.
+// Equivalent to: `bridge link set dev $link mcast_router $router`
+func (h *Handle) LinkSetMulticastRouter(link Link, router uint8) error {
+	return h.setProtinfoAttrValue(link, nl.IFLA_BRPORT_MULTICAST_ROUTER, nl.Uint8Attr(router))
+}
+
 func (h *Handle) setProtinfoAttr(link Link, mode bool, attr int) error {
+	return h.setProtinfoAttrValue(link, attr, boolToByte(mode))
+}
+
+func (h *Handle) setProtinfoAttrValue(link Link, attr int, value []byte) error {
 	base := link.Attrs()
 	h.ensureIndex(base)
 	req := h.newNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
@@ -2000,7 +2591,7 @@ func (h *Handle) setProtinfoAttr(link Link, mode bool, attr int) error {
 	req.AddData(msg)
 
 	br := nl.NewRtAttr(unix.IFLA_PROTINFO|unix.NLA_F_NESTED, nil)
-	br.AddRtAttr(attr, boolToByte(mode))
+	br.AddRtAttr(attr, value)
 	req.AddData(br)
 	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
 	if err != nil {
@@ -2065,6 +2656,142 @@ func (h *Handle) LinkSetGroup(link Link, group int) error {
 	return err
 }
 
+// LinkSetLinkMode sets the link mode, which decides if the operational
+// state of the link follows the carrier or waits for userspace.
+// Equivalent to: `ip link set $link mode $mode`
+func LinkSetLinkMode(link Link, mode LinkMode) error {
+	return pkgHandle.LinkSetLinkMode(link, mode)
+}
+
+// LinkSetLinkMode sets the link mode, which decides if the operational
+// state of the link follows the carrier or waits for userspace.
+// Equivalent to: `ip link set $link mode $mode`
+func (h *Handle) LinkSetLinkMode(link Link, mode LinkMode) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	data := nl.NewRtAttr(unix.IFLA_LINKMODE, nl.Uint8Attr(uint8(mode)))
+	req.AddData(data)
+
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
+// LinkSetIPv4Forwarding enables or disables IPv4 forwarding on the link.
+// The kernel only supports changing IPv4 devconf entries through netlink,
+// IPv6 settings such as accept_ra must still be changed through sysctl.
+// Equivalent to: `sysctl net.ipv4.conf.$link.forwarding=$value`
+func LinkSetIPv4Forwarding(link Link, enable bool) error {
+	return pkgHandle.LinkSetIPv4Forwarding(link, enable)
+}
+
+// LinkSetIPv4Forwarding enables or disables IPv4 forwarding on the link.
+// The kernel only supports changing IPv4 devconf entries through netlink,
+// IPv6 settings such as accept_ra must still be changed through sysctl.
+// Equivalent to: `sysctl net.ipv4.conf.$link.forwarding=$value`
+func (h *Handle) LinkSetIPv4Forwarding(link Link, enable bool) error {
+	var value uint32
+	if enable {
+		value = 1
+	}
+	return h.linkSetIPv4DevConf(link, nl.IPV4_DEVCONF_FORWARDING, value)
+}
+
+// LinkSetIPv4RpFilter sets the reverse path filter mode of the link, 0 for
+// none, 1 for strict and 2 for loose mode.
+// Equivalent to: `sysctl net.ipv4.conf.$link.rp_filter=$mode`
+func LinkSetIPv4RpFilter(link Link, mode int) error {
+	return pkgHandle.LinkSetIPv4RpFilter(link, mode)
+}
+
+// LinkSetIPv4RpFilter sets the reverse path filter mode of the link, 0 for
+// none, 1 for strict and 2 for loose mode.
+// Equivalent to: `sysctl net.ipv4.conf.$link.rp_filter=$mode`
+func (h *Handle) LinkSetIPv4RpFilter(link Link, mode int) error {
+	if mode < 0 || mode > 2 {
+		return fmt.Errorf("invalid rp_filter mode %d", mode)
+	}
+	return h.linkSetIPv4DevConf(link, nl.IPV4_DEVCONF_RP_FILTER, uint32(mode))
+}
+
+func (h *Handle) linkSetIPv4DevConf(link Link, conf int, value uint32) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	req := h.newNetlinkRequest(unix.RTM_SETLINK, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	afSpec := nl.NewRtAttr(unix.IFLA_AF_SPEC, nil)
+	inet := afSpec.AddRtAttr(unix.AF_INET, nil)
+	inetConf := inet.AddRtAttr(nl.IFLA_INET_CONF, nil)
+	inetConf.AddRtAttr(conf, nl.Uint32Attr(value))
+	req.AddData(afSpec)
+
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
+func parseAfSpec(base *LinkAttrs, data []byte) error {
+	families, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return err
+	}
+	for _, family := range families {
+		attrs, err := nl.ParseRouteAttr(family.Value)
+		if err != nil {
+			return err
+		}
+		for _, attr := range attrs {
+			switch {
+			case family.Attr.Type == unix.AF_INET && attr.Attr.Type == nl.IFLA_INET_CONF:
+				conf := devConfValues(attr.Value)
+				// IPv4 devconf entries are 1 based
+				get := func(i int) int32 { return conf(i - 1) }
+				base.IPv4DevConf = &IPv4DevConf{
+					Forwarding:      get(nl.IPV4_DEVCONF_FORWARDING),
+					McForwarding:    get(nl.IPV4_DEVCONF_MC_FORWARDING),
+					ProxyArp:        get(nl.IPV4_DEVCONF_PROXY_ARP),
+					AcceptRedirects: get(nl.IPV4_DEVCONF_ACCEPT_REDIRECTS),
+					SendRedirects:   get(nl.IPV4_DEVCONF_SEND_REDIRECTS),
+					RpFilter:        get(nl.IPV4_DEVCONF_RP_FILTER),
+					AcceptLocal:     get(nl.IPV4_DEVCONF_ACCEPT_LOCAL),
+				}
+			case family.Attr.Type == unix.AF_INET6 && attr.Attr.Type == nl.IFLA_INET6_CONF:
+				get := devConfValues(attr.Value)
+				base.IPv6DevConf = &IPv6DevConf{
+					Forwarding:      get(nl.DEVCONF_FORWARDING),
+					HopLimit:        get(nl.DEVCONF_HOPLIMIT),
+					MTU:             get(nl.DEVCONF_MTU6),
+					AcceptRA:        get(nl.DEVCONF_ACCEPT_RA),
+					AcceptRedirects: get(nl.DEVCONF_ACCEPT_REDIRECTS),
+					Autoconf:        get(nl.DEVCONF_AUTOCONF),
+					DisableIPv6:     get(nl.DEVCONF_DISABLE_IPV6),
+					AcceptDAD:       get(nl.DEVCONF_ACCEPT_DAD),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// devConfValues returns a lookup into an array of 32 bit devconf values,
+// entries missing from older kernels read as 0.
+func devConfValues(data []byte) func(int) int32 {
+	return func(i int) int32 {
+		if i < 0 || 4*i+4 > len(data) {
+			return 0
+		}
+		return int32(native.Uint32(data[4*i : 4*i+4]))
+	}
+}
+
 func parseVlanData(link Link, data []syscall.NetlinkRouteAttr) {
 	vlan := link.(*Vlan)
 	for _, datum := range data {
@@ -2097,6 +2824,8 @@ func parseVxlanData(link Link, data []syscall.NetlinkRouteAttr) {
 			vxlan.TTL = int(datum.Value[0])
 		case nl.IFLA_VXLAN_TOS:
 			vxlan.TOS = int(datum.Value[0])
+		case nl.IFLA_VXLAN_DF:
+			vxlan.Df = VxlanDf(datum.Value[0])
 		case nl.IFLA_VXLAN_LEARNING:
 			vxlan.Learning = int8(datum.Value[0]) != 0
 		case nl.IFLA_VXLAN_PROXY:
@@ -2184,7 +2913,7 @@ func parseBondData(link Link, data []syscall.NetlinkRouteAttr) {
 		case nl.IFLA_BOND_AD_SELECT:
 			bond.AdSelect = BondAdSelect(data[i].Value[0])
 		case nl.IFLA_BOND_AD_INFO:
-			// TODO: implement
+			bond.AdInfo = parseBondAdInfo(data[i].Value)
 		case nl.IFLA_BOND_AD_ACTOR_SYS_PRIO:
 			bond.AdActorSysPrio = int(native.Uint16(data[i].Value[0:2]))
 		case nl.IFLA_BOND_AD_USER_PORT_KEY:
@@ -2218,6 +2947,30 @@ func parseBondArpIpTargets(value []byte) []net.IP {
 	return targets
 }
 
+func parseBondAdInfo(value []byte) *BondAdInfo {
+	data, err := nl.ParseRouteAttr(value)
+	if err != nil {
+		return nil
+	}
+
+	adInfo := &BondAdInfo{}
+	for i := range data {
+		switch data[i].Attr.Type {
+		case nl.IFLA_BOND_AD_INFO_AGGREGATOR:
+			adInfo.AggregatorId = int(native.Uint16(data[i].Value[0:2]))
+		case nl.IFLA_BOND_AD_INFO_NUM_PORTS:
+			adInfo.NumPorts = int(native.Uint16(data[i].Value[0:2]))
+		case nl.IFLA_BOND_AD_INFO_ACTOR_KEY:
+			adInfo.ActorKey = int(native.Uint16(data[i].Value[0:2]))
+		case nl.IFLA_BOND_AD_INFO_PARTNER_KEY:
+			adInfo.PartnerKey = int(native.Uint16(data[i].Value[0:2]))
+		case nl.IFLA_BOND_AD_INFO_PARTNER_MAC:
+			adInfo.PartnerMac = net.HardwareAddr(data[i].Value[0:6])
+		}
+	}
+	return adInfo
+}
+
 func addBondSlaveAttrs(bondSlave *BondSlave, linkInfo *nl.RtAttr) {
 	data := linkInfo.AddRtAttr(nl.IFLA_INFO_SLAVE_DATA, nil)
 
@@ -2327,13 +3080,18 @@ func linkFlags(rawFlags uint32) net.Flags {
 	return f
 }
 
-func addGretapAttrs(gretap *Gretap, linkInfo *nl.RtAttr) {
+func addGretapAttrs(gretap *Gretap, linkInfo *nl.RtAttr) error {
 	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
 
 	if gretap.FlowBased {
 		// In flow based mode, no other attributes need to be configured
 		data.AddRtAttr(nl.IFLA_GRE_COLLECT_METADATA, boolAttr(gretap.FlowBased))
-		return
+		return nil
+	}
+
+	// the kernel can't ignore the DF bit it sets for path MTU discovery
+	if gretap.IgnoreDf && gretap.PMtuDisc != 0 {
+		return fmt.Errorf("gretap: IgnoreDf can't be combined with PMtuDisc")
 	}
 
 	if ip := gretap.Local; ip != nil {
@@ -2368,12 +3126,16 @@ func addGretapAttrs(gretap *Gretap, linkInfo *nl.RtAttr) {
 	}
 
 	data.AddRtAttr(nl.IFLA_GRE_PMTUDISC, nl.Uint8Attr(gretap.PMtuDisc))
+	if gretap.IgnoreDf {
+		data.AddRtAttr(nl.IFLA_GRE_IGNORE_DF, boolAttr(gretap.IgnoreDf))
+	}
 	data.AddRtAttr(nl.IFLA_GRE_TTL, nl.Uint8Attr(gretap.Ttl))
 	data.AddRtAttr(nl.IFLA_GRE_TOS, nl.Uint8Attr(gretap.Tos))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_TYPE, nl.Uint16Attr(gretap.EncapType))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_FLAGS, nl.Uint16Attr(gretap.EncapFlags))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_SPORT, htons(gretap.EncapSport))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_DPORT, htons(gretap.EncapDport))
+	return nil
 }
 
 func parseGretapData(link Link, data []syscall.NetlinkRouteAttr) {
@@ -2402,6 +3164,8 @@ func parseGretapData(link Link, data []syscall.NetlinkRouteAttr) {
 			gre.Tos = uint8(datum.Value[0])
 		case nl.IFLA_GRE_PMTUDISC:
 			gre.PMtuDisc = uint8(datum.Value[0])
+		case nl.IFLA_GRE_IGNORE_DF:
+			gre.IgnoreDf = datum.Value[0] != 0
 		case nl.IFLA_GRE_ENCAP_TYPE:
 			gre.EncapType = native.Uint16(datum.Value[0:2])
 		case nl.IFLA_GRE_ENCAP_FLAGS:
@@ -2412,7 +3176,12 @@ func parseGretapData(link Link, data []syscall.NetlinkRouteAttr) {
 	}
 }
 
-func addGretunAttrs(gre *Gretun, linkInfo *nl.RtAttr) {
+func addGretunAttrs(gre *Gretun, linkInfo *nl.RtAttr) error {
+	// the kernel can't ignore the DF bit it sets for path MTU discovery
+	if gre.IgnoreDf && gre.PMtuDisc != 0 {
+		return fmt.Errorf("gre: IgnoreDf can't be combined with PMtuDisc")
+	}
+
 	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
 
 	if ip := gre.Local; ip != nil {
@@ -2447,12 +3216,16 @@ func addGretunAttrs(gre *Gretun, linkInfo *nl.RtAttr) {
 	}
 
 	data.AddRtAttr(nl.IFLA_GRE_PMTUDISC, nl.Uint8Attr(gre.PMtuDisc))
+	if gre.IgnoreDf {
+		data.AddRtAttr(nl.IFLA_GRE_IGNORE_DF, boolAttr(gre.IgnoreDf))
+	}
 	data.AddRtAttr(nl.IFLA_GRE_TTL, nl.Uint8Attr(gre.Ttl))
 	data.AddRtAttr(nl.IFLA_GRE_TOS, nl.Uint8Attr(gre.Tos))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_TYPE, nl.Uint16Attr(gre.EncapType))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_FLAGS, nl.Uint16Attr(gre.EncapFlags))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_SPORT, htons(gre.EncapSport))
 	data.AddRtAttr(nl.IFLA_GRE_ENCAP_DPORT, htons(gre.EncapDport))
+	return nil
 }
 
 func parseGretunData(link Link, data []syscall.NetlinkRouteAttr) {
@@ -2477,6 +3250,8 @@ func parseGretunData(link Link, data []syscall.NetlinkRouteAttr) {
 			gre.Tos = uint8(datum.Value[0])
 		case nl.IFLA_GRE_PMTUDISC:
 			gre.PMtuDisc = uint8(datum.Value[0])
+		case nl.IFLA_GRE_IGNORE_DF:
+			gre.IgnoreDf = datum.Value[0] != 0
 		case nl.IFLA_GRE_ENCAP_TYPE:
 			gre.EncapType = native.Uint16(datum.Value[0:2])
 		case nl.IFLA_GRE_ENCAP_FLAGS:
@@ -2605,6 +3380,10 @@ func addIp6tnlAttrs(ip6tnl *Ip6tnl, linkInfo *nl.RtAttr) {
 	data.AddRtAttr(nl.IFLA_IPTUN_FLAGS, nl.Uint32Attr(ip6tnl.Flags))
 	data.AddRtAttr(nl.IFLA_IPTUN_PROTO, nl.Uint8Attr(ip6tnl.Proto))
 	data.AddRtAttr(nl.IFLA_IPTUN_FLOWINFO, nl.Uint32Attr(ip6tnl.FlowInfo))
+	data.AddRtAttr(nl.IFLA_IPTUN_ENCAP_TYPE, nl.Uint16Attr(ip6tnl.EncapType))
+	data.AddRtAttr(nl.IFLA_IPTUN_ENCAP_FLAGS, nl.Uint16Attr(ip6tnl.EncapFlags))
+	data.AddRtAttr(nl.IFLA_IPTUN_ENCAP_SPORT, htons(ip6tnl.EncapSport))
+	data.AddRtAttr(nl.IFLA_IPTUN_ENCAP_DPORT, htons(ip6tnl.EncapDport))
 }
 
 func parseIp6tnlData(link Link, data []syscall.NetlinkRouteAttr) {
@@ -2627,6 +3406,14 @@ func parseIp6tnlData(link Link, data []syscall.NetlinkRouteAttr) {
 			ip6tnl.Proto = uint8(datum.Value[0])
 		case nl.IFLA_IPTUN_FLOWINFO:
 			ip6tnl.FlowInfo = native.Uint32(datum.Value[:4])
+		case nl.IFLA_IPTUN_ENCAP_SPORT:
+			ip6tnl.EncapSport = ntohs(datum.Value[0:2])
+		case nl.IFLA_IPTUN_ENCAP_DPORT:
+			ip6tnl.EncapDport = ntohs(datum.Value[0:2])
+		case nl.IFLA_IPTUN_ENCAP_TYPE:
+			ip6tnl.EncapType = native.Uint16(datum.Value[0:2])
+		case nl.IFLA_IPTUN_ENCAP_FLAGS:
+			ip6tnl.EncapFlags = native.Uint16(datum.Value[0:2])
 		}
 	}
 }
@@ -2764,6 +3551,21 @@ func addBridgeAttrs(bridge *Bridge, linkInfo *nl.RtAttr) {
 	if bridge.HelloTime != nil {
 		data.AddRtAttr(nl.IFLA_BR_HELLO_TIME, nl.Uint32Attr(*bridge.HelloTime))
 	}
+	if bridge.MaxAge != nil {
+		data.AddRtAttr(nl.IFLA_BR_MAX_AGE, nl.Uint32Attr(*bridge.MaxAge))
+	}
+	if bridge.ForwardDelay != nil {
+		data.AddRtAttr(nl.IFLA_BR_FORWARD_DELAY, nl.Uint32Attr(*bridge.ForwardDelay))
+	}
+	if bridge.AgeingTime != nil {
+		data.AddRtAttr(nl.IFLA_BR_AGEING_TIME, nl.Uint32Attr(*bridge.AgeingTime))
+	}
+	if bridge.StpState != nil {
+		data.AddRtAttr(nl.IFLA_BR_STP_STATE, nl.Uint32Attr(*bridge.StpState))
+	}
+	if bridge.Priority != nil {
+		data.AddRtAttr(nl.IFLA_BR_PRIORITY, nl.Uint16Attr(*bridge.Priority))
+	}
 	if bridge.VlanFiltering != nil {
 		data.AddRtAttr(nl.IFLA_BR_VLAN_FILTERING, boolToByte(*bridge.VlanFiltering))
 	}
@@ -2776,6 +3578,21 @@ func parseBridgeData(bridge Link, data []syscall.NetlinkRouteAttr) {
 		case nl.IFLA_BR_HELLO_TIME:
 			helloTime := native.Uint32(datum.Value[0:4])
 			br.HelloTime = &helloTime
+		case nl.IFLA_BR_MAX_AGE:
+			maxAge := native.Uint32(datum.Value[0:4])
+			br.MaxAge = &maxAge
+		case nl.IFLA_BR_FORWARD_DELAY:
+			forwardDelay := native.Uint32(datum.Value[0:4])
+			br.ForwardDelay = &forwardDelay
+		case nl.IFLA_BR_AGEING_TIME:
+			ageingTime := native.Uint32(datum.Value[0:4])
+			br.AgeingTime = &ageingTime
+		case nl.IFLA_BR_STP_STATE:
+			stpState := native.Uint32(datum.Value[0:4])
+			br.StpState = &stpState
+		case nl.IFLA_BR_PRIORITY:
+			priority := native.Uint16(datum.Value[0:2])
+			br.Priority = &priority
 		case nl.IFLA_BR_MCAST_SNOOPING:
 			mcastSnooping := datum.Value[0] == 1
 			br.MulticastSnooping = &mcastSnooping
@@ -2790,7 +3607,11 @@ func addGTPAttrs(gtp *GTP, linkInfo *nl.RtAttr) {
 	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
 	data.AddRtAttr(nl.IFLA_GTP_FD0, nl.Uint32Attr(uint32(gtp.FD0)))
 	data.AddRtAttr(nl.IFLA_GTP_FD1, nl.Uint32Attr(uint32(gtp.FD1)))
-	data.AddRtAttr(nl.IFLA_GTP_PDP_HASHSIZE, nl.Uint32Attr(131072))
+	hashsize := gtp.PDPHashsize
+	if hashsize <= 0 {
+		hashsize = 131072
+	}
+	data.AddRtAttr(nl.IFLA_GTP_PDP_HASHSIZE, nl.Uint32Attr(uint32(hashsize)))
 	if gtp.Role != nl.GTP_ROLE_GGSN {
 		data.AddRtAttr(nl.IFLA_GTP_ROLE, nl.Uint32Attr(uint32(gtp.Role)))
 	}
@@ -2839,6 +3660,17 @@ func parseVfInfo(data []syscall.NetlinkRouteAttr, id int) VfInfo {
 			vl := nl.DeserializeVfVlan(element.Value[:])
 			vf.Vlan = int(vl.Vlan)
 			vf.Qos = int(vl.Qos)
+		case nl.IFLA_VF_VLAN_LIST:
+			vlans, err := nl.ParseRouteAttr(element.Value[:])
+			if err != nil {
+				continue
+			}
+			for _, vlan := range vlans {
+				if vlan.Attr.Type == nl.IFLA_VF_VLAN_INFO && len(vlan.Value) >= nl.SizeofVfVlanInfo {
+					vf.VlanProto = int(nl.DeserializeVfVlanInfo(vlan.Value).VlanProto)
+					break
+				}
+			}
 		case nl.IFLA_VF_TX_RATE:
 			txr := nl.DeserializeVfTxRate(element.Value[:])
 			vf.TxRate = int(txr.Rate)
@@ -2852,11 +3684,28 @@ func parseVfInfo(data []syscall.NetlinkRouteAttr, id int) VfInfo {
 			vfr := nl.DeserializeVfRate(element.Value[:])
 			vf.MaxTxRate = vfr.MaxTxRate
 			vf.MinTxRate = vfr.MinTxRate
+		case nl.IFLA_VF_RSS_QUERY_EN:
+			rss := nl.DeserializeVfRssQueryEn(element.Value[:])
+			vf.RssQuery = rss.Setting != 0
+		case nl.IFLA_VF_TRUST:
+			tr := nl.DeserializeVfTrust(element.Value[:])
+			vf.Trust = tr.Setting != 0
+		case nl.IFLA_VF_IB_NODE_GUID:
+			vf.NodeGUID = parseVfGUID(element.Value)
+		case nl.IFLA_VF_IB_PORT_GUID:
+			vf.PortGUID = parseVfGUID(element.Value)
 		}
 	}
 	return vf
 }
 
+// parseVfGUID returns the GUID in the byte order LinkSetVfGUID takes it.
+func parseVfGUID(b []byte) net.HardwareAddr {
+	guid := make(net.HardwareAddr, 8)
+	binary.BigEndian.PutUint64(guid, nl.DeserializeVfGUID(b).GUID)
+	return guid
+}
+
 func addXfrmiAttrs(xfrmi *Xfrmi, linkInfo *nl.RtAttr) {
 	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
 	data.AddRtAttr(nl.IFLA_XFRM_LINK, nl.Uint32Attr(uint32(xfrmi.ParentIndex)))
@@ -2876,6 +3725,34 @@ func parseXfrmiData(link Link, data []syscall.NetlinkRouteAttr) {
 	}
 }
 
+func addBareUDPAttrs(bareudp *BareUDP, linkInfo *nl.RtAttr) {
+	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
+	data.AddRtAttr(nl.IFLA_BAREUDP_PORT, htons(bareudp.Port))
+	data.AddRtAttr(nl.IFLA_BAREUDP_ETHERTYPE, htons(bareudp.EtherType))
+	if bareudp.SrcPortMin != 0 {
+		data.AddRtAttr(nl.IFLA_BAREUDP_SRCPORT_MIN, nl.Uint16Attr(bareudp.SrcPortMin))
+	}
+	if bareudp.MultiProto {
+		data.AddRtAttr(nl.IFLA_BAREUDP_MULTIPROTO_MODE, []byte{})
+	}
+}
+
+func parseBareUDPData(link Link, data []syscall.NetlinkRouteAttr) {
+	bareudp := link.(*BareUDP)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.IFLA_BAREUDP_PORT:
+			bareudp.Port = ntohs(datum.Value[0:2])
+		case nl.IFLA_BAREUDP_ETHERTYPE:
+			bareudp.EtherType = ntohs(datum.Value[0:2])
+		case nl.IFLA_BAREUDP_SRCPORT_MIN:
+			bareudp.SrcPortMin = native.Uint16(datum.Value[0:2])
+		case nl.IFLA_BAREUDP_MULTIPROTO_MODE:
+			bareudp.MultiProto = true
+		}
+	}
+}
+
 // LinkSetBondSlave add slave to bond link via ioctl interface.
 func LinkSetBondSlave(link Link, master *Bond) error {
 	fd, err := getSocketUDP()
@@ -2917,6 +3794,44 @@ func LinkSetBondSlaveQueueId(link Link, queueId uint16) error {
 	return pkgHandle.LinkSetBondSlaveQueueId(link, queueId)
 }
 
+// BondSetActiveSlave makes slave the active slave of the bond, which only
+// applies to the active-backup, balance-alb and balance-tlb modes. A nil
+// slave clears the active slave.
+// Equivalent to: `ip link set $bond type bond active_slave $slave`
+func BondSetActiveSlave(bond *Bond, slave Link) error {
+	return pkgHandle.BondSetActiveSlave(bond, slave)
+}
+
+// BondSetActiveSlave makes slave the active slave of the bond, which only
+// applies to the active-backup, balance-alb and balance-tlb modes. A nil
+// slave clears the active slave.
+// Equivalent to: `ip link set $bond type bond active_slave $slave`
+func (h *Handle) BondSetActiveSlave(bond *Bond, slave Link) error {
+	base := bond.Attrs()
+	h.ensureIndex(base)
+	index := 0
+	if slave != nil {
+		slaveBase := slave.Attrs()
+		h.ensureIndex(slaveBase)
+		index = slaveBase.Index
+	}
+	// link data can only be changed with RTM_NEWLINK, RTM_SETLINK ignores it
+	req := h.newNetlinkRequest(unix.RTM_NEWLINK, unix.NLM_F_ACK)
+
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Index = int32(base.Index)
+	req.AddData(msg)
+
+	linkInfo := nl.NewRtAttr(unix.IFLA_LINKINFO, nil)
+	linkInfo.AddRtAttr(nl.IFLA_INFO_KIND, nl.NonZeroTerminated(bond.Type()))
+	data := linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, nil)
+	data.AddRtAttr(nl.IFLA_BOND_ACTIVE_SLAVE, nl.Uint32Attr(uint32(index)))
+	req.AddData(linkInfo)
+
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
 func vethStatsSerialize(stats ethtoolStats) ([]byte, error) {
 	statsSize := int(unsafe.Sizeof(stats)) + int(stats.nStats)*int(unsafe.Sizeof(uint64(0)))
 	b := make([]byte, 0, statsSize)
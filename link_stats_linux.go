@@ -0,0 +1,313 @@
+package netlink
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// LinkStatsPoll dumps the statistics of all links every interval and sends
+// a sample for each link in ifindices on the returned channel. An empty
+// ifindices selects all links. A single netlink socket is used for the
+// lifetime of the poller and each interval costs one RTM_GETLINK dump.
+// Polling stops and the channel is closed when ctx is done or a dump fails,
+// in which case a last sample carrying only the error in Err is sent first.
+func LinkStatsPoll(ctx context.Context, interval time.Duration, ifindices []int) (<-chan LinkStatsSample, error) {
+	h, err := NewHandle(unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := h.linkStatsPoll(ctx, interval, ifindices, h.Delete)
+	if err != nil {
+		h.Delete()
+		return nil, err
+	}
+	return ch, nil
+}
+
+// LinkStatsPoll dumps the statistics of all links every interval and sends
+// a sample for each link in ifindices on the returned channel. An empty
+// ifindices selects all links. The sockets of the handle are used for the
+// dumps and each interval costs one RTM_GETLINK dump.
+// Polling stops and the channel is closed when ctx is done or a dump fails,
+// in which case a last sample carrying only the error in Err is sent first.
+func (h *Handle) LinkStatsPoll(ctx context.Context, interval time.Duration, ifindices []int) (<-chan LinkStatsSample, error) {
+	return h.linkStatsPoll(ctx, interval, ifindices, func() {})
+}
+
+func (h *Handle) linkStatsPoll(ctx context.Context, interval time.Duration, ifindices []int, done func()) (<-chan LinkStatsSample, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid poll interval %s", interval)
+	}
+	var wanted map[int]bool
+	if len(ifindices) > 0 {
+		wanted = make(map[int]bool, len(ifindices))
+		for _, index := range ifindices {
+			wanted[index] = true
+		}
+	}
+
+	ch := make(chan LinkStatsSample)
+	go func() {
+		defer close(ch)
+		defer done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		prev := make(map[int]LinkStatsSample)
+		for {
+			samples, err := h.linkStatsDump(ctx, wanted)
+			if err != nil {
+				if ctx.Err() == nil {
+					select {
+					case ch <- LinkStatsSample{Err: err}:
+					case <-ctx.Done():
+					}
+				}
+				return
+			}
+			now := time.Now()
+			for i := range samples {
+				sample := &samples[i]
+				sample.Time = now
+				if last, ok := prev[sample.Index]; ok {
+					sample.setDelta(&last)
+				}
+				prev[sample.Index] = *sample
+				select {
+				case ch <- *sample:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// linkStatsDump requests all links and only decodes the name and counters
+// of the ones selected by wanted, which is nil to select all links.
+func (h *Handle) linkStatsDump(ctx context.Context, wanted map[int]bool) ([]LinkStatsSample, error) {
+	req := h.newNetlinkRequest(unix.RTM_GETLINK, unix.NLM_F_DUMP)
+	req.AddData(nl.NewIfInfomsg(unix.AF_UNSPEC))
+
+	msgs, err := req.ExecuteContext(ctx, unix.NETLINK_ROUTE, unix.RTM_NEWLINK)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []LinkStatsSample
+	for _, m := range msgs {
+		msg := nl.DeserializeIfInfomsg(m)
+		index := int(msg.Index)
+		if wanted != nil && !wanted[index] {
+			continue
+		}
+		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+		if err != nil {
+			return nil, err
+		}
+		sample := LinkStatsSample{Index: index}
+		var stats32 *LinkStatistics32
+		var stats64 *LinkStatistics64
+		for _, attr := range attrs {
+			switch attr.Attr.Type {
+			case unix.IFLA_IFNAME:
+				sample.Name = string(attr.Value[:len(attr.Value)-1])
+			case unix.IFLA_STATS:
+				stats32 = new(LinkStatistics32)
+				if err := binary.Read(bytes.NewBuffer(attr.Value[:]), nl.NativeEndian(), stats32); err != nil {
+					return nil, err
+				}
+			case unix.IFLA_STATS64:
+				stats64 = new(LinkStatistics64)
+				if err := binary.Read(bytes.NewBuffer(attr.Value[:]), nl.NativeEndian(), stats64); err != nil {
+					return nil, err
+				}
+			}
+		}
+		if stats64 != nil {
+			sample.Stats = LinkStatistics(*stats64)
+		} else if stats32 != nil {
+			sample.Stats = LinkStatistics(*stats32.to64())
+		}
+		res = append(res, sample)
+	}
+	return res, nil
+}
+
+// setDelta computes the counter deltas and rates of s relative to the
+// earlier sample prev. A counter that went backwards is assumed to have
+// been reset and its current value is used as the delta.
+func (s *LinkStatsSample) setDelta(prev *LinkStatsSample) {
+	s.Interval = s.Time.Sub(prev.Time)
+	cur, old := &s.Stats, &prev.Stats
+	s.Delta = LinkStatistics{
+		RxPackets:         counterDelta(cur.RxPackets, old.RxPackets),
+		TxPackets:         counterDelta(cur.TxPackets, old.TxPackets),
+		RxBytes:           counterDelta(cur.RxBytes, old.RxBytes),
+		TxBytes:           counterDelta(cur.TxBytes, old.TxBytes),
+		RxErrors:          counterDelta(cur.RxErrors, old.RxErrors),
+		TxErrors:          counterDelta(cur.TxErrors, old.TxErrors),
+		RxDropped:         counterDelta(cur.RxDropped, old.RxDropped),
+		TxDropped:         counterDelta(cur.TxDropped, old.TxDropped),
+		Multicast:         counterDelta(cur.Multicast, old.Multicast),
+		Collisions:        counterDelta(cur.Collisions, old.Collisions),
+		RxLengthErrors:    counterDelta(cur.RxLengthErrors, old.RxLengthErrors),
+		RxOverErrors:      counterDelta(cur.RxOverErrors, old.RxOverErrors),
+		RxCrcErrors:       counterDelta(cur.RxCrcErrors, old.RxCrcErrors),
+		RxFrameErrors:     counterDelta(cur.RxFrameErrors, old.RxFrameErrors),
+		RxFifoErrors:      counterDelta(cur.RxFifoErrors, old.RxFifoErrors),
+		RxMissedErrors:    counterDelta(cur.RxMissedErrors, old.RxMissedErrors),
+		TxAbortedErrors:   counterDelta(cur.TxAbortedErrors, old.TxAbortedErrors),
+		TxCarrierErrors:   counterDelta(cur.TxCarrierErrors, old.TxCarrierErrors),
+		TxFifoErrors:      counterDelta(cur.TxFifoErrors, old.TxFifoErrors),
+		TxHeartbeatErrors: counterDelta(cur.TxHeartbeatErrors, old.TxHeartbeatErrors),
+		TxWindowErrors:    counterDelta(cur.TxWindowErrors, old.TxWindowErrors),
+		RxCompressed:      counterDelta(cur.RxCompressed, old.RxCompressed),
+		TxCompressed:      counterDelta(cur.TxCompressed, old.TxCompressed),
+	}
+	if secs := s.Interval.Seconds(); secs > 0 {
+		s.RxBytesRate = float64(s.Delta.RxBytes) / secs
+		s.TxBytesRate = float64(s.Delta.TxBytes) / secs
+		s.RxPacketsRate = float64(s.Delta.RxPackets) / secs
+		s.TxPacketsRate = float64(s.Delta.TxPackets) / secs
+	}
+}
+
+func counterDelta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
+
+// LinkXStats gets the link type specific extended statistics of a link,
+// such as the multicast counters of a bridge or the 802.3ad counters of
+// a bond.
+// Equivalent to: `ip stats show dev $link group xstats`
+func LinkXStats(link Link) (*LinkXStatistics, error) {
+	return pkgHandle.LinkXStats(link)
+}
+
+// LinkXStats gets the link type specific extended statistics of a link,
+// such as the multicast counters of a bridge or the 802.3ad counters of
+// a bond.
+// Equivalent to: `ip stats show dev $link group xstats`
+func (h *Handle) LinkXStats(link Link) (*LinkXStatistics, error) {
+	base := link.Attrs()
+	h.ensureIndex(base)
+
+	req := h.newNetlinkRequest(unix.RTM_GETSTATS, 0)
+	msg := &nl.IfStatsMsg{
+		Family:     unix.AF_UNSPEC,
+		Ifindex:    uint32(base.Index),
+		FilterMask: nl.IflaStatsFilterBit(nl.IFLA_STATS_LINK_XSTATS),
+	}
+	req.AddData(msg)
+
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWSTATS)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("no stats returned for link %d", base.Index)
+	}
+	return parseLinkXStats(msgs[0][nl.SizeofIfStatsMsg:])
+}
+
+func parseLinkXStats(data []byte) (*LinkXStatistics, error) {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return nil, err
+	}
+	xstats := &LinkXStatistics{}
+	for _, attr := range attrs {
+		if attr.Attr.Type != nl.IFLA_STATS_LINK_XSTATS {
+			continue
+		}
+		types, err := nl.ParseRouteAttr(attr.Value)
+		if err != nil {
+			return nil, err
+		}
+		for _, typ := range types {
+			stats, err := nl.ParseRouteAttr(typ.Value)
+			if err != nil {
+				return nil, err
+			}
+			switch typ.Attr.Type {
+			case nl.LINK_XSTATS_TYPE_BRIDGE:
+				xstats.Bridge = &BridgeXStats{}
+				for _, stat := range stats {
+					switch stat.Attr.Type {
+					case nl.BRIDGE_XSTATS_MCAST:
+						mcast := &BridgeMcastStats{}
+						if err := binary.Read(bytes.NewBuffer(stat.Value), nl.NativeEndian(), mcast); err != nil {
+							return nil, err
+						}
+						xstats.Bridge.Mcast = mcast
+					}
+				}
+			case nl.LINK_XSTATS_TYPE_BOND:
+				for _, stat := range stats {
+					switch stat.Attr.Type {
+					case nl.BOND_XSTATS_3AD:
+						ad, err := parseBond3adStats(stat.Value)
+						if err != nil {
+							return nil, err
+						}
+						xstats.Bond = ad
+					}
+				}
+			}
+		}
+	}
+	return xstats, nil
+}
+
+func parseBond3adStats(data []byte) (*Bond3adStats, error) {
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return nil, err
+	}
+	native := nl.NativeEndian()
+	stats := &Bond3adStats{}
+	for _, attr := range attrs {
+		if len(attr.Value) < 8 {
+			continue
+		}
+		value := native.Uint64(attr.Value[0:8])
+		switch attr.Attr.Type {
+		case nl.BOND_3AD_STAT_LACPDU_RX:
+			stats.LacpduRx = value
+		case nl.BOND_3AD_STAT_LACPDU_TX:
+			stats.LacpduTx = value
+		case nl.BOND_3AD_STAT_LACPDU_UNKNOWN_RX:
+			stats.LacpduUnknownRx = value
+		case nl.BOND_3AD_STAT_LACPDU_ILLEGAL_RX:
+			stats.LacpduIllegalRx = value
+		case nl.BOND_3AD_STAT_MARKER_RX:
+			stats.MarkerRx = value
+		case nl.BOND_3AD_STAT_MARKER_TX:
+			stats.MarkerTx = value
+		case nl.BOND_3AD_STAT_MARKER_RESP_RX:
+			stats.MarkerRespRx = value
+		case nl.BOND_3AD_STAT_MARKER_RESP_TX:
+			stats.MarkerRespTx = value
+		case nl.BOND_3AD_STAT_MARKER_UNKNOWN_RX:
+			stats.MarkerUnknownRx = value
+		}
+	}
+	return stats, nil
+}
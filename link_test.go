@@ -4,8 +4,13 @@ package netlink
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"os"
+	"reflect"
 	"syscall"
 	"testing"
 	"time"
@@ -211,18 +216,30 @@ func testLinkAddDel(t *testing.T, link Link) {
 		}
 	}
 
-	if _, ok := link.(*Iptun); ok {
-		_, ok := result.(*Iptun)
+	if iptun, ok := link.(*Iptun); ok {
+		other, ok := result.(*Iptun)
 		if !ok {
 			t.Fatal("Result of create is not a iptun")
 		}
+		if iptun.EncapType != other.EncapType || iptun.EncapSport != other.EncapSport ||
+			iptun.EncapDport != other.EncapDport {
+			t.Fatalf("Got unexpected encap %d %d:%d, expected %d %d:%d",
+				other.EncapType, other.EncapSport, other.EncapDport,
+				iptun.EncapType, iptun.EncapSport, iptun.EncapDport)
+		}
 	}
 
-	if _, ok := link.(*Ip6tnl); ok {
-		_, ok := result.(*Ip6tnl)
+	if ip6tnl, ok := link.(*Ip6tnl); ok {
+		other, ok := result.(*Ip6tnl)
 		if !ok {
 			t.Fatal("Result of create is not a ip6tnl")
 		}
+		if ip6tnl.EncapType != other.EncapType || ip6tnl.EncapSport != other.EncapSport ||
+			ip6tnl.EncapDport != other.EncapDport {
+			t.Fatalf("Got unexpected encap %d %d:%d, expected %d %d:%d",
+				other.EncapType, other.EncapSport, other.EncapDport,
+				ip6tnl.EncapType, ip6tnl.EncapSport, ip6tnl.EncapDport)
+		}
 	}
 
 	if _, ok := link.(*Sittun); ok {
@@ -256,6 +273,14 @@ func testLinkAddDel(t *testing.T, link Link) {
 		compareXfrmi(t, xfrmi, other)
 	}
 
+	if bareudp, ok := link.(*BareUDP); ok {
+		other, ok := result.(*BareUDP)
+		if !ok {
+			t.Fatal("Result of create is not a bareudp")
+		}
+		compareBareUDP(t, bareudp, other)
+	}
+
 	if tuntap, ok := link.(*Tuntap); ok {
 		other, ok := result.(*Tuntap)
 		if !ok {
@@ -317,6 +342,10 @@ func compareGretap(t *testing.T, expected, actual *Gretap) {
 		t.Fatal("Gretap.PMtuDisc doesn't match")
 	}
 
+	if actual.IgnoreDf != expected.IgnoreDf {
+		t.Fatal("Gretap.IgnoreDf doesn't match")
+	}
+
 	if actual.Ttl != expected.Ttl {
 		t.Fatal("Gretap.Ttl doesn't match")
 	}
@@ -388,6 +417,10 @@ func compareGretun(t *testing.T, expected, actual *Gretun) {
 		t.Fatal("Gretun.PMtuDisc doesn't match")
 	}
 
+	if actual.IgnoreDf != expected.IgnoreDf {
+		t.Fatal("Gretun.IgnoreDf doesn't match")
+	}
+
 	if actual.EncapType != expected.EncapType {
 		t.Fatal("Gretun.EncapType doesn't match")
 	}
@@ -422,6 +455,9 @@ func compareVxlan(t *testing.T, expected, actual *Vxlan) {
 	if expected.TOS != -1 && actual.TOS != expected.TOS {
 		t.Fatal("Vxlan.TOS doesn't match")
 	}
+	if actual.Df != expected.Df {
+		t.Fatal("Vxlan.Df doesn't match")
+	}
 	if actual.Learning != expected.Learning {
 		t.Fatal("Vxlan.Learning doesn't match")
 	}
@@ -478,6 +514,21 @@ func compareXfrmi(t *testing.T, expected, actual *Xfrmi) {
 	}
 }
 
+func compareBareUDP(t *testing.T, expected, actual *BareUDP) {
+	if expected.Port != actual.Port {
+		t.Fatalf("BareUDP.Port doesn't match: %d %d", expected.Port, actual.Port)
+	}
+	if expected.EtherType != actual.EtherType {
+		t.Fatalf("BareUDP.EtherType doesn't match: %x %x", expected.EtherType, actual.EtherType)
+	}
+	if expected.SrcPortMin != actual.SrcPortMin {
+		t.Fatalf("BareUDP.SrcPortMin doesn't match: %d %d", expected.SrcPortMin, actual.SrcPortMin)
+	}
+	if expected.MultiProto != actual.MultiProto {
+		t.Fatal("BareUDP.MultiProto doesn't match")
+	}
+}
+
 func compareTuntap(t *testing.T, expected, actual *Tuntap) {
 	if expected.Mode != actual.Mode {
 		t.Fatalf("Tuntap.Mode doesn't match: expected : %+v, got %+v", expected.Mode, actual.Mode)
@@ -524,6 +575,48 @@ func TestLinkAddDelIfb(t *testing.T) {
 	testLinkAddDel(t, &Ifb{LinkAttrs{Name: "foo"}})
 }
 
+func TestLinkAddDelTeam(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	team := NewTeam("foo")
+	if err := LinkAdd(team); err != nil {
+		t.Fatal(err)
+	}
+	port := &Dummy{LinkAttrs{Name: "fooFoo"}}
+	if err := LinkAdd(port); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMaster(port, team); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := link.(*Team); !ok {
+		t.Fatalf("unexpected link type: %T", link)
+	}
+	link, err = LinkByName("fooFoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().MasterIndex != team.Index {
+		t.Fatalf("Expected master %d, got %d", team.Index, link.Attrs().MasterIndex)
+	}
+	if _, ok := link.Attrs().Slave.(*TeamSlave); !ok {
+		t.Fatalf("unexpected slave type: %T", link.Attrs().Slave)
+	}
+
+	if err := LinkDel(team); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkDel(port); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestLinkAddDelBridge(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -531,6 +624,45 @@ func TestLinkAddDelBridge(t *testing.T) {
 	testLinkAddDel(t, &Bridge{LinkAttrs: LinkAttrs{Name: "foo", MTU: 1400}})
 }
 
+func TestLinkRawAttributes(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	broadcastOf := func(link Link) net.HardwareAddr {
+		for _, raw := range link.Attrs().RawAttributes {
+			if raw.Type == unix.IFLA_BROADCAST {
+				return net.HardwareAddr(raw.Value)
+			}
+		}
+		return nil
+	}
+
+	// IFLA_BROADCAST is not modelled by LinkAttrs
+	broadcast := net.HardwareAddr{0x02, 0xff, 0xff, 0xff, 0xff, 0xff}
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{
+		Name:          "foo",
+		RawAttributes: []RawAttribute{{Type: unix.IFLA_BROADCAST, Value: broadcast}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := broadcastOf(link); !bytes.Equal(b, broadcast) {
+		t.Fatalf("IFLA_BROADCAST not preserved: %v", link.Attrs().RawAttributes)
+	}
+
+	// all the attributes listed are accepted when sent back
+	link, err = LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{
+		Name:          "bar",
+		RawAttributes: link.Attrs().RawAttributes,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := broadcastOf(link); !bytes.Equal(b, broadcast) {
+		t.Fatalf("IFLA_BROADCAST lost on re-add: %v", link.Attrs().RawAttributes)
+	}
+}
+
 func TestLinkAddDelGretap(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -566,6 +698,44 @@ func TestLinkAddDelGretun(t *testing.T) {
 		Remote:    net.ParseIP("2001:db8:ef33::2")})
 }
 
+func TestLinkAddDelGretunInheritTosDf(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testLinkAddDel(t, &Gretun{
+		LinkAttrs: LinkAttrs{Name: "foo"},
+		Local:     net.IPv4(127, 0, 0, 1),
+		Remote:    net.IPv4(127, 0, 0, 2),
+		Tos:       TUNNEL_TOS_INHERIT,
+		IgnoreDf:  true})
+}
+
+func TestLinkAddGreIgnoreDfPMtuDisc(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	gretun := &Gretun{
+		LinkAttrs: LinkAttrs{Name: "foo"},
+		Local:     net.IPv4(127, 0, 0, 1),
+		Remote:    net.IPv4(127, 0, 0, 2),
+		PMtuDisc:  1,
+		IgnoreDf:  true,
+	}
+	if err := LinkAdd(gretun); err == nil {
+		t.Fatal("Expected an error for a gre tunnel ignoring DF with PMtuDisc")
+	}
+	gretap := &Gretap{
+		LinkAttrs: LinkAttrs{Name: "bar"},
+		Local:     net.IPv4(127, 0, 0, 1),
+		Remote:    net.IPv4(127, 0, 0, 2),
+		PMtuDisc:  1,
+		IgnoreDf:  true,
+	}
+	if err := LinkAdd(gretap); err == nil {
+		t.Fatal("Expected an error for a gretap ignoring DF with PMtuDisc")
+	}
+}
+
 func TestLinkAddDelGretunPointToMultiPoint(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -639,6 +809,66 @@ func TestLinkAddDelMacvlan(t *testing.T) {
 	}
 }
 
+func TestLinkSetMacvlanMode(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	parent := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(parent); err != nil {
+		t.Fatal(err)
+	}
+
+	macvlan := &Macvlan{
+		LinkAttrs: LinkAttrs{Name: "bar", ParentIndex: parent.Attrs().Index},
+		Mode:      MACVLAN_MODE_PRIVATE,
+	}
+	if err := LinkAdd(macvlan); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkSetMacvlanMode(macvlan, MACVLAN_MODE_BRIDGE); err != nil {
+		t.Fatal(err)
+	}
+	if macvlan.Mode != MACVLAN_MODE_BRIDGE {
+		t.Fatalf("Expected the link mode to be updated, got %d", macvlan.Mode)
+	}
+	link, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := link.(*Macvlan).Mode; mode != MACVLAN_MODE_BRIDGE {
+		t.Fatalf("Expected mode %d, got %d", MACVLAN_MODE_BRIDGE, mode)
+	}
+
+	if err := LinkSetMacvlanMode(macvlan, MACVLAN_MODE_DEFAULT); err == nil {
+		t.Fatal("Expected an error for an invalid macvlan mode")
+	}
+	if err := LinkSetMacvlanMode(parent, MACVLAN_MODE_BRIDGE); err == nil {
+		t.Fatal("Expected an error for a link that is not a macvlan")
+	}
+
+	macvtap := &Macvtap{Macvlan: Macvlan{
+		LinkAttrs: LinkAttrs{Name: "baz", ParentIndex: parent.Attrs().Index},
+		Mode:      MACVLAN_MODE_PRIVATE,
+	}}
+	if err := LinkAdd(macvtap); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMacvlanMode(macvtap, MACVLAN_MODE_VEPA); err != nil {
+		t.Fatal(err)
+	}
+	if macvtap.Mode != MACVLAN_MODE_VEPA {
+		t.Fatalf("Expected the link mode to be updated, got %d", macvtap.Mode)
+	}
+	link, err = LinkByName("baz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := link.(*Macvtap).Mode; mode != MACVLAN_MODE_VEPA {
+		t.Fatalf("Expected mode %d, got %d", MACVLAN_MODE_VEPA, mode)
+	}
+}
+
 func TestLinkAddDelMacvtap(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -694,6 +924,69 @@ func TestLinkAddDelVeth(t *testing.T) {
 	testLinkAddDel(t, veth)
 }
 
+func TestLinkAddVethPeerAttrs(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	basens, err := netns.Get()
+	if err != nil {
+		t.Fatal("Failed to get basens")
+	}
+	defer basens.Close()
+
+	newns, err := netns.New()
+	if err != nil {
+		t.Fatal("Failed to create newns")
+	}
+	defer newns.Close()
+	if err := netns.Set(basens); err != nil {
+		t.Fatal("Failed to set basens")
+	}
+
+	peerMAC, _ := net.ParseMAC("00:12:34:56:78:02")
+	veth := &Veth{
+		LinkAttrs:        LinkAttrs{Name: "foo", MTU: 1400, NumTxQueues: 8, NumRxQueues: 8},
+		PeerName:         "bar",
+		PeerHardwareAddr: peerMAC,
+		PeerMTU:          1300,
+		PeerNamespace:    NsFd(newns),
+	}
+	if err := LinkAdd(veth); err != nil {
+		t.Fatal(err)
+	}
+	defer LinkDel(veth)
+	if _, err := LinkByName("bar"); err == nil {
+		t.Fatal("Peer bar was not created in newns")
+	}
+
+	nh, err := NewHandleAt(newns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nh.Delete()
+	peer, err := nh.LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peer.Attrs().MTU != 1300 {
+		t.Fatalf("Expected peer mtu 1300, got %d", peer.Attrs().MTU)
+	}
+	if !bytes.Equal(peer.Attrs().HardwareAddr, peerMAC) {
+		t.Fatalf("Expected peer address %s, got %s", peerMAC, peer.Attrs().HardwareAddr)
+	}
+	if peer.Attrs().NumTxQueues != 8 || peer.Attrs().NumRxQueues != 8 {
+		t.Fatalf("Expected 8 peer queues, got %d tx and %d rx", peer.Attrs().NumTxQueues, peer.Attrs().NumRxQueues)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().NumTxQueues != 8 || link.Attrs().NumRxQueues != 8 {
+		t.Fatalf("Expected 8 queues, got %d tx and %d rx", link.Attrs().NumTxQueues, link.Attrs().NumRxQueues)
+	}
+}
+
 func TestLinkAddDelBond(t *testing.T) {
 	minKernelRequired(t, 3, 13)
 
@@ -980,7 +1273,7 @@ func TestLinkSetNs(t *testing.T) {
 	}
 	defer newns.Close()
 
-	link := &Veth{LinkAttrs{Name: "foo"}, "bar", nil}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}
 	if err := LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -1054,6 +1347,20 @@ func TestLinkAddDelVxlan(t *testing.T) {
 	}
 }
 
+func TestLinkAddDelVxlanInheritTosDf(t *testing.T) {
+	minKernelRequired(t, 5, 2)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testLinkAddDel(t, &Vxlan{
+		LinkAttrs: LinkAttrs{Name: "bar"},
+		VxlanId:   10,
+		Port:      4789,
+		TOS:       TUNNEL_TOS_INHERIT,
+		Df:        VXLAN_DF_INHERIT,
+	})
+}
+
 func TestLinkAddDelVxlanUdpCSum6(t *testing.T) {
 	minKernelRequired(t, 3, 16)
 	tearDown := setUpNetlinkTest(t)
@@ -1242,122 +1549,280 @@ func TestLinkByIndex(t *testing.T) {
 	}
 }
 
-func TestLinkSet(t *testing.T) {
+func TestLinkSetLinkMode(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	iface := &Dummy{LinkAttrs{Name: "foo"}}
-	if err := LinkAdd(iface); err != nil {
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
 		t.Fatal(err)
 	}
-
 	link, err := LinkByName("foo")
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	err = LinkSetName(link, "bar")
-	if err != nil {
-		t.Fatalf("Could not change interface name: %v", err)
-	}
-
-	link, err = LinkByName("bar")
-	if err != nil {
-		t.Fatalf("Interface name not changed: %v", err)
+	if link.Attrs().LinkMode != LinkModeDefault {
+		t.Fatalf("Expected link mode default, got %s", link.Attrs().LinkMode)
 	}
 
-	err = LinkSetMTU(link, 1400)
-	if err != nil {
-		t.Fatalf("Could not set MTU: %v", err)
+	if err := LinkSetLinkMode(link, LinkModeDormant); err != nil {
+		t.Fatal(err)
 	}
-
-	link, err = LinkByName("bar")
+	link, err = LinkByName("foo")
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	if link.Attrs().MTU != 1400 {
-		t.Fatal("MTU not changed")
+	if link.Attrs().LinkMode != LinkModeDormant {
+		t.Fatalf("Expected link mode dormant, got %s", link.Attrs().LinkMode)
 	}
+}
 
-	err = LinkSetTxQLen(link, 500)
-	if err != nil {
-		t.Fatalf("Could not set txqlen: %v", err)
-	}
+func TestLinkByAltName(t *testing.T) {
+	minKernelRequired(t, 5, 5)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
 
-	link, err = LinkByName("bar")
-	if err != nil {
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
 		t.Fatal(err)
 	}
-
-	if link.Attrs().TxQLen != 500 {
-		t.Fatal("txqlen not changed")
-	}
-
-	addr, err := net.ParseMAC("00:12:34:56:78:AB")
+	link, err := LinkByName("foo")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = LinkSetHardwareAddr(link, addr)
-	if err != nil {
+	const altName = "a-bridge-name-longer-than-ifnamsiz"
+	if err := LinkAddAltName(link, altName); err != nil {
 		t.Fatal(err)
 	}
-
-	link, err = LinkByName("bar")
+	other, err := LinkByAltName(altName)
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	if !bytes.Equal(link.Attrs().HardwareAddr, addr) {
-		t.Fatalf("hardware address not changed")
+	if other.Attrs().Index != link.Attrs().Index || other.Attrs().Name != "foo" {
+		t.Fatalf("Expected link foo, got %s", other.Attrs().Name)
 	}
-
-	err = LinkSetAlias(link, "barAlias")
-	if err != nil {
-		t.Fatalf("Could not set alias: %v", err)
+	if _, ok := other.(*Bridge); !ok {
+		t.Fatalf("unexpected link type: %T", other)
+	}
+	if !reflect.DeepEqual(other.Attrs().AltNames, []string{altName}) {
+		t.Fatalf("Expected altnames [%s], got %v", altName, other.Attrs().AltNames)
 	}
 
-	link, err = LinkByName("bar")
-	if err != nil {
+	if err := LinkDelAltName(link, altName); err != nil {
 		t.Fatal(err)
 	}
-
-	if link.Attrs().Alias != "barAlias" {
-		t.Fatalf("alias not changed")
+	if _, err := LinkByAltName(altName); err == nil {
+		t.Fatal("Link still found by its deleted altname")
 	}
+}
 
-	link, err = LinkByAlias("barAlias")
-	if err != nil {
+func TestLinkKind(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(bridge); err != nil {
 		t.Fatal(err)
 	}
-
-	err = LinkSetGroup(link, 42)
-	if err != nil {
-		t.Fatalf("Could not set group: %v", err)
+	vxlan := &Vxlan{LinkAttrs: LinkAttrs{Name: "bar", MasterIndex: bridge.Index}, VxlanId: 10, Port: 4789}
+	if err := LinkAdd(vxlan); err != nil {
+		t.Fatal(err)
 	}
 
-	link, err = LinkByName("bar")
+	links, err := LinkList()
 	if err != nil {
 		t.Fatal(err)
 	}
-
-	if link.Attrs().Group != 42 {
-		t.Fatal("Link group not changed")
+	kinds := map[string][2]string{}
+	for _, link := range links {
+		kinds[link.Attrs().Name] = [2]string{link.Attrs().Kind, link.Attrs().SlaveKind}
+	}
+	expected := map[string][2]string{
+		"lo":  {"", ""},
+		"foo": {"bridge", ""},
+		"bar": {"vxlan", "bridge"},
+	}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Fatalf("Expected kinds %v, got %v", expected, kinds)
 	}
 }
 
-func TestLinkSetARP(t *testing.T) {
+func TestLinkSetUpAndWait(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
 
-	iface := &Veth{LinkAttrs: LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1500}, PeerName: "banana"}
-	if err := LinkAdd(iface); err != nil {
+	lo, err := LinkByName("lo")
+	if err != nil {
 		t.Fatal(err)
 	}
-
-	link, err := LinkByName("foo")
-	if err != nil {
+	if err := LinkSetUpAndWait(lo, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// a tap without a process attached to it has no carrier
+	tap := &Tuntap{LinkAttrs: LinkAttrs{Name: "foo"}, Mode: TUNTAP_MODE_TAP}
+	if err := LinkAdd(tap); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUpAndWait(tap, 100*time.Millisecond); err == nil {
+		t.Fatal("Expected a timeout for a link without carrier")
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Flags&net.FlagUp == 0 {
+		t.Fatal("Link was not set up")
+	}
+}
+
+func TestLinkSetAliasClear(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkSetAlias(link, "uplink-to-core"); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Alias != "uplink-to-core" {
+		t.Fatalf("Alias is %q, expected %q", link.Attrs().Alias, "uplink-to-core")
+	}
+
+	if err := LinkSetAlias(link, ""); err != nil {
+		t.Fatal(err)
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().Alias != "" {
+		t.Fatalf("Alias not cleared: %q", link.Attrs().Alias)
+	}
+}
+
+func TestLinkSet(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	iface := &Dummy{LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(iface); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = LinkSetName(link, "bar")
+	if err != nil {
+		t.Fatalf("Could not change interface name: %v", err)
+	}
+
+	link, err = LinkByName("bar")
+	if err != nil {
+		t.Fatalf("Interface name not changed: %v", err)
+	}
+
+	err = LinkSetMTU(link, 1400)
+	if err != nil {
+		t.Fatalf("Could not set MTU: %v", err)
+	}
+
+	link, err = LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if link.Attrs().MTU != 1400 {
+		t.Fatal("MTU not changed")
+	}
+
+	err = LinkSetTxQLen(link, 500)
+	if err != nil {
+		t.Fatalf("Could not set txqlen: %v", err)
+	}
+
+	link, err = LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if link.Attrs().TxQLen != 500 {
+		t.Fatal("txqlen not changed")
+	}
+
+	addr, err := net.ParseMAC("00:12:34:56:78:AB")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = LinkSetHardwareAddr(link, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	link, err = LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(link.Attrs().HardwareAddr, addr) {
+		t.Fatalf("hardware address not changed")
+	}
+
+	err = LinkSetAlias(link, "barAlias")
+	if err != nil {
+		t.Fatalf("Could not set alias: %v", err)
+	}
+
+	link, err = LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if link.Attrs().Alias != "barAlias" {
+		t.Fatalf("alias not changed")
+	}
+
+	link, err = LinkByAlias("barAlias")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = LinkSetGroup(link, 42)
+	if err != nil {
+		t.Fatalf("Could not set group: %v", err)
+	}
+
+	link, err = LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if link.Attrs().Group != 42 {
+		t.Fatal("Link group not changed")
+	}
+}
+
+func TestLinkSetARP(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	iface := &Veth{LinkAttrs: LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1500}, PeerName: "banana"}
+	if err := LinkAdd(iface); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName("foo")
+	if err != nil {
 		t.Fatal(err)
 	}
 
@@ -1415,7 +1880,7 @@ func TestLinkSubscribe(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	link := &Veth{LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1400}, "bar", nil}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1400}, PeerName: "bar"}
 	if err := LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -1462,7 +1927,7 @@ func TestLinkSubscribeWithOptions(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	link := &Veth{LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1400}, "bar", nil}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "foo", TxQLen: testTxQLen, MTU: 1400}, PeerName: "bar"}
 	if err := LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -1496,7 +1961,7 @@ func TestLinkSubscribeAt(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	link := &Veth{LinkAttrs{Name: "test", TxQLen: testTxQLen, MTU: 1400}, "bar", nil}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "test", TxQLen: testTxQLen, MTU: 1400}, PeerName: "bar"}
 	if err := nh.LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -1522,6 +1987,70 @@ func TestLinkSubscribeAt(t *testing.T) {
 	}
 }
 
+func TestLinkSubscribeNewIndex(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	basens, err := netns.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer basens.Close()
+
+	newNs, err := netns.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer newNs.Close()
+	// netns.New switched the thread to the new namespace
+	if err := netns.Set(basens); err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan LinkUpdate)
+	done := make(chan struct{})
+	defer close(done)
+	if err := LinkSubscribe(ch, done); err != nil {
+		t.Fatal(err)
+	}
+
+	link := &Vxlan{LinkAttrs: LinkAttrs{Name: "foo"}, VxlanId: 10, Port: 4789}
+	if err := LinkAdd(link); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetNsFd(link, int(newNs)); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(time.Minute)
+	for {
+		select {
+		case update := <-ch:
+			if update.Header.Type != unix.RTM_DELLINK || update.Attrs().Name != "foo" {
+				continue
+			}
+			if update.Attrs().NewIndex == 0 {
+				t.Fatalf("Expected the index in the new namespace, got %+v", update.Attrs())
+			}
+			nh, err := NewHandleAt(newNs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer nh.Delete()
+			moved, err := nh.LinkByName("foo")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if moved.Attrs().Index != update.Attrs().NewIndex {
+				t.Fatalf("Expected index %d, got %d", update.Attrs().NewIndex, moved.Attrs().Index)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Move update not received as expected")
+		}
+	}
+}
+
 func TestLinkSubscribeListExisting(t *testing.T) {
 	skipUnlessRoot(t)
 
@@ -1538,7 +2067,7 @@ func TestLinkSubscribeListExisting(t *testing.T) {
 	}
 	defer nh.Delete()
 
-	link := &Veth{LinkAttrs{Name: "test", TxQLen: testTxQLen, MTU: 1400}, "bar", nil}
+	link := &Veth{LinkAttrs: LinkAttrs{Name: "test", TxQLen: testTxQLen, MTU: 1400}, PeerName: "bar"}
 	if err := nh.LinkAdd(link); err != nil {
 		t.Fatal(err)
 	}
@@ -1623,6 +2152,264 @@ func TestLinkStats(t *testing.T) {
 	}
 }
 
+func TestLinkListContext(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	links, err := LinkListContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(links) == 0 {
+		t.Fatal("expected at least the loopback link")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LinkListContext(ctx); err != context.Canceled {
+		t.Fatalf("expected %v, got %v", context.Canceled, err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	if _, err := LinkListContext(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestLinkStatsPoll(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	lo, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(lo); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples, err := LinkStatsPoll(ctx, 10*time.Millisecond, []int{lo.Attrs().Index})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := <-samples
+	if first.Err != nil {
+		t.Fatal(first.Err)
+	}
+	if first.Index != lo.Attrs().Index || first.Name != "lo" {
+		t.Fatalf("unexpected sample %+v", first)
+	}
+	if first.Interval != 0 {
+		t.Fatalf("first sample should not have an interval, got %s", first.Interval)
+	}
+	second := <-samples
+	if second.Err != nil {
+		t.Fatal(second.Err)
+	}
+	if second.Index != lo.Attrs().Index {
+		t.Fatalf("unexpected sample %+v", second)
+	}
+	if second.Interval <= 0 || !second.Time.After(first.Time) {
+		t.Fatalf("second sample has invalid interval %s", second.Interval)
+	}
+	if second.Delta.RxPackets != second.Stats.RxPackets-first.Stats.RxPackets {
+		t.Fatalf("unexpected delta %d", second.Delta.RxPackets)
+	}
+
+	cancel()
+	for sample := range samples {
+		if sample.Err != nil {
+			t.Fatalf("unexpected error after cancel: %v", sample.Err)
+		}
+	}
+}
+
+func TestLinkXStatsBridge(t *testing.T) {
+	minKernelRequired(t, 4, 7)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	xstats, err := LinkXStats(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if xstats.Bridge == nil || xstats.Bridge.Mcast == nil {
+		t.Fatalf("expected bridge multicast stats, got %+v", xstats)
+	}
+	if xstats.Bond != nil {
+		t.Fatal("unexpected bond stats for a bridge")
+	}
+}
+
+func TestLinkStatsSampleDelta(t *testing.T) {
+	now := time.Now()
+	prev := &LinkStatsSample{
+		Time:  now,
+		Stats: LinkStatistics{RxBytes: 1000, TxBytes: 500, RxPackets: 10, TxErrors: 7},
+	}
+	sample := &LinkStatsSample{
+		Time:  now.Add(2 * time.Second),
+		Stats: LinkStatistics{RxBytes: 3000, TxBytes: 500, RxPackets: 30, TxErrors: 2},
+	}
+	sample.setDelta(prev)
+
+	if sample.Interval != 2*time.Second {
+		t.Fatalf("expected interval 2s, got %s", sample.Interval)
+	}
+	if sample.Delta.RxBytes != 2000 || sample.Delta.TxBytes != 0 || sample.Delta.RxPackets != 20 {
+		t.Fatalf("unexpected delta %+v", sample.Delta)
+	}
+	// counter reset
+	if sample.Delta.TxErrors != 2 {
+		t.Fatalf("expected reset counter delta 2, got %d", sample.Delta.TxErrors)
+	}
+	if sample.RxBytesRate != 1000 || sample.RxPacketsRate != 10 || sample.TxBytesRate != 0 {
+		t.Fatalf("unexpected rates %+v", sample)
+	}
+}
+
+func TestLinkDeserializeUnknownKind(t *testing.T) {
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Type = unix.ARPHRD_NONE
+	msg.Index = 42
+	b := msg.Serialize()
+	b = append(b, nl.NewRtAttr(unix.IFLA_IFNAME, nl.ZeroTerminated("wg0")).Serialize()...)
+	b = append(b, nl.NewRtAttr(unix.IFLA_MTU, nl.Uint32Attr(1420)).Serialize()...)
+	b = append(b, nl.NewRtAttr(unix.IFLA_CARRIER_CHANGES, nl.Uint32Attr(3)).Serialize()...)
+	linkInfo := nl.NewRtAttr(unix.IFLA_LINKINFO, nil)
+	linkInfo.AddRtAttr(nl.IFLA_INFO_KIND, nl.ZeroTerminated("wireguard"))
+	// data that does not parse as attributes
+	linkInfo.AddRtAttr(nl.IFLA_INFO_DATA, []byte{0xff, 0xff, 0xff})
+	b = append(b, linkInfo.Serialize()...)
+
+	link, err := LinkDeserialize(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generic, ok := link.(*GenericLink)
+	if !ok {
+		t.Fatalf("Expected GenericLink, got %T", link)
+	}
+	if generic.Type() != "wireguard" {
+		t.Fatalf("Expected kind wireguard, got %s", generic.Type())
+	}
+	if generic.CarrierChanges != 3 {
+		t.Fatalf("Expected 3 carrier changes, got %d", generic.CarrierChanges)
+	}
+	if generic.Name != "wg0" || generic.MTU != 1420 {
+		t.Fatalf("Expected wg0 with mtu 1420, got %s with mtu %d", generic.Name, generic.MTU)
+	}
+}
+
+func TestLinkDeserializeInfiniband(t *testing.T) {
+	hwaddr := net.HardwareAddr{
+		0x80, 0x00, 0x02, 0x08, 0xfe, 0x80, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x02, 0xc9, 0x03, 0x00, 0x0a, 0xbc, 0xde,
+	}
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Type = unix.ARPHRD_INFINIBAND
+	msg.Index = 42
+	b := msg.Serialize()
+	b = append(b, nl.NewRtAttr(unix.IFLA_IFNAME, nl.ZeroTerminated("ibtest0")).Serialize()...)
+	b = append(b, nl.NewRtAttr(unix.IFLA_ADDRESS, []byte(hwaddr)).Serialize()...)
+
+	link, err := LinkDeserialize(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// without IFLA_INFO_KIND it is a hardware device like any other
+	if _, ok := link.(*Device); !ok {
+		t.Fatalf("Expected Device link, got %T", link)
+	}
+	if link.Attrs().EncapType != "infiniband" {
+		t.Fatalf("Expected infiniband encap type, got %s", link.Attrs().EncapType)
+	}
+	if !bytes.Equal(link.Attrs().HardwareAddr, hwaddr) {
+		t.Fatalf("Expected hardware address %s, got %s", hwaddr, link.Attrs().HardwareAddr)
+	}
+}
+
+func TestParseVfInfoRate(t *testing.T) {
+	rate := &nl.VfRate{Vf: 3, MinTxRate: 100, MaxTxRate: 1000}
+	txRate := &nl.VfTxRate{Vf: 3, Rate: 1000}
+	b := nl.NewRtAttr(nl.IFLA_VF_RATE, rate.Serialize()).Serialize()
+	b = append(b, nl.NewRtAttr(nl.IFLA_VF_TX_RATE, txRate.Serialize()).Serialize()...)
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vf := parseVfInfo(attrs, 3)
+	if vf.ID != 3 {
+		t.Fatalf("Expected vf 3, got %d", vf.ID)
+	}
+	if vf.MinTxRate != 100 || vf.MaxTxRate != 1000 {
+		t.Fatalf("Expected min/max tx rate 100/1000, got %d/%d", vf.MinTxRate, vf.MaxTxRate)
+	}
+	if vf.TxRate != 1000 {
+		t.Fatalf("Expected tx rate 1000, got %d", vf.TxRate)
+	}
+}
+
+func TestParseVfInfoLinkState(t *testing.T) {
+	for _, state := range []uint32{VF_LINK_STATE_AUTO, VF_LINK_STATE_ENABLE, VF_LINK_STATE_DISABLE} {
+		ls := &nl.VfLinkState{Vf: 1, LinkState: state}
+		attrs, err := nl.ParseRouteAttr(nl.NewRtAttr(nl.IFLA_VF_LINK_STATE, ls.Serialize()).Serialize())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if vf := parseVfInfo(attrs, 1); vf.LinkState != state {
+			t.Fatalf("Expected link state %d, got %d", state, vf.LinkState)
+		}
+	}
+
+	link := &Device{LinkAttrs{Index: 1, Name: "foo"}}
+	if err := LinkSetVfState(link, 0, VF_LINK_STATE_DISABLE+1); err == nil {
+		t.Fatal("Expected an error for an invalid vf link state")
+	}
+}
+
+func TestParseVfInfoGUIDs(t *testing.T) {
+	nodeGUID := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77}
+	portGUID := net.HardwareAddr{0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	rss := &nl.VfRssQueryEn{Vf: 2, Setting: 1}
+	trust := &nl.VfTrust{Vf: 2, Setting: 1}
+	node := &nl.VfGUID{Vf: 2, GUID: binary.BigEndian.Uint64(nodeGUID)}
+	port := &nl.VfGUID{Vf: 2, GUID: binary.BigEndian.Uint64(portGUID)}
+	b := nl.NewRtAttr(nl.IFLA_VF_RSS_QUERY_EN, rss.Serialize()).Serialize()
+	b = append(b, nl.NewRtAttr(nl.IFLA_VF_TRUST, trust.Serialize()).Serialize()...)
+	b = append(b, nl.NewRtAttr(nl.IFLA_VF_IB_NODE_GUID, node.Serialize()).Serialize()...)
+	b = append(b, nl.NewRtAttr(nl.IFLA_VF_IB_PORT_GUID, port.Serialize()).Serialize()...)
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vf := parseVfInfo(attrs, 2)
+	if !vf.RssQuery || !vf.Trust {
+		t.Fatalf("Expected rss query and trust on, got %v/%v", vf.RssQuery, vf.Trust)
+	}
+	if !bytes.Equal(vf.NodeGUID, nodeGUID) {
+		t.Fatalf("Expected node guid %s, got %s", nodeGUID, vf.NodeGUID)
+	}
+	if !bytes.Equal(vf.PortGUID, portGUID) {
+		t.Fatalf("Expected port guid %s, got %s", portGUID, vf.PortGUID)
+	}
+}
+
 func TestLinkXdp(t *testing.T) {
 	links, err := LinkList()
 	if err != nil {
@@ -1653,6 +2440,13 @@ func TestLinkXdp(t *testing.T) {
 	}
 }
 
+func TestLinkSetXdpFdInvalidFlags(t *testing.T) {
+	link := &Dummy{LinkAttrs{Name: "foo", Index: 1}}
+	if err := LinkSetXdpFdWithFlags(link, -1, 1<<10); err == nil {
+		t.Fatal("expected an error for invalid xdp flags")
+	}
+}
+
 func TestLinkAddDelIptun(t *testing.T) {
 	minKernelRequired(t, 4, 9)
 	tearDown := setUpNetlinkTest(t)
@@ -1676,6 +2470,58 @@ func TestLinkAddDelIp6tnl(t *testing.T) {
 	})
 }
 
+func TestLinkAddDelIptunFou(t *testing.T) {
+	minKernelRequired(t, 4, 9)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testLinkAddDel(t, &Iptun{
+		LinkAttrs:  LinkAttrs{Name: "iptunfoo"},
+		PMtuDisc:   1,
+		Local:      net.IPv4(127, 0, 0, 1),
+		Remote:     net.IPv4(127, 0, 0, 1),
+		EncapType:  FOU_ENCAP_DIRECT,
+		EncapSport: 5555,
+		EncapDport: 5556,
+	})
+}
+
+func TestLinkAddDelIp6tnlFou(t *testing.T) {
+	minKernelRequired(t, 4, 9)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	testLinkAddDel(t, &Ip6tnl{
+		LinkAttrs:  LinkAttrs{Name: "ip6tnltest"},
+		Local:      net.ParseIP("2001:db8::100"),
+		Remote:     net.ParseIP("2001:db8::200"),
+		EncapType:  FOU_ENCAP_GUE,
+		EncapSport: 5555,
+		EncapDport: 5556,
+	})
+}
+
+func TestIp6tnlEncapRoundTrip(t *testing.T) {
+	ip6tnl := &Ip6tnl{EncapType: FOU_ENCAP_GUE, EncapFlags: 1, EncapSport: 5555, EncapDport: 5556}
+	linkInfo := nl.NewRtAttr(unix.IFLA_LINKINFO, nil)
+	addIp6tnlAttrs(ip6tnl, linkInfo)
+	infos, err := nl.ParseRouteAttr(linkInfo.Serialize()[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := nl.ParseRouteAttr(infos[0].Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other := &Ip6tnl{}
+	parseIp6tnlData(other, data)
+	if other.EncapType != ip6tnl.EncapType || other.EncapFlags != ip6tnl.EncapFlags ||
+		other.EncapSport != ip6tnl.EncapSport || other.EncapDport != ip6tnl.EncapDport {
+		t.Fatalf("Expected encap %+v, got %+v", ip6tnl, other)
+	}
+}
+
 func TestLinkAddDelSittun(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -1876,6 +2722,55 @@ func TestBridgeCreationWithVlanFiltering(t *testing.T) {
 	}
 }
 
+func TestBridgeCreationWithStpParams(t *testing.T) {
+	minKernelRequired(t, 3, 18)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridgeName := "foo"
+	stpState := uint32(0)
+	ageingTime := uint32(60000)
+	maxAge := uint32(1000)
+	forwardDelay := uint32(500)
+	priority := uint16(4096)
+	bridge := &Bridge{
+		LinkAttrs:    LinkAttrs{Name: bridgeName},
+		StpState:     &stpState,
+		AgeingTime:   &ageingTime,
+		MaxAge:       &maxAge,
+		ForwardDelay: &forwardDelay,
+		Priority:     &priority,
+	}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+
+	link, err := LinkByName(bridgeName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retrievedBridge := link.(*Bridge)
+	if *retrievedBridge.StpState != stpState {
+		t.Fatalf("expected stp state %d got %d", stpState, *retrievedBridge.StpState)
+	}
+	if *retrievedBridge.AgeingTime != ageingTime {
+		t.Fatalf("expected ageing time %d got %d", ageingTime, *retrievedBridge.AgeingTime)
+	}
+	if *retrievedBridge.MaxAge != maxAge {
+		t.Fatalf("expected max age %d got %d", maxAge, *retrievedBridge.MaxAge)
+	}
+	if *retrievedBridge.ForwardDelay != forwardDelay {
+		t.Fatalf("expected forward delay %d got %d", forwardDelay, *retrievedBridge.ForwardDelay)
+	}
+	if *retrievedBridge.Priority != priority {
+		t.Fatalf("expected priority %d got %d", priority, *retrievedBridge.Priority)
+	}
+	if err := LinkDel(bridge); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestLinkSubscribeWithProtinfo(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -1961,6 +2856,27 @@ func TestLinkAddDelGTP(t *testing.T) {
 	testLinkAddDel(t, gtp)
 }
 
+func TestLinkAddGTPHashsize(t *testing.T) {
+	tearDown := setUpNetlinkTestWithKModule(t, "gtp")
+	defer tearDown()
+	gtp := testGTPLink(t)
+	gtp.PDPHashsize = 1024
+	if err := LinkAdd(gtp); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName(gtp.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, ok := link.(*GTP)
+	if !ok {
+		t.Fatal("Link is not a GTP link")
+	}
+	if result.PDPHashsize != gtp.PDPHashsize {
+		t.Fatalf("expected pdp hashsize %d, got %d", gtp.PDPHashsize, result.PDPHashsize)
+	}
+}
+
 func TestLinkAddDelXfrmi(t *testing.T) {
 	minKernelRequired(t, 4, 19)
 	defer setUpNetlinkTest(t)()
@@ -1982,6 +2898,19 @@ func TestLinkAddDelXfrmiNoId(t *testing.T) {
 		LinkAttrs: LinkAttrs{Name: "xfrm0", ParentIndex: lo.Attrs().Index}})
 }
 
+func TestLinkAddDelBareUDP(t *testing.T) {
+	minKernelRequired(t, 5, 8)
+	defer setUpNetlinkTest(t)()
+
+	testLinkAddDel(t, &BareUDP{
+		LinkAttrs:  LinkAttrs{Name: "bareudp0"},
+		Port:       6635,
+		EtherType:  unix.ETH_P_MPLS_UC,
+		SrcPortMin: 1000,
+		MultiProto: true,
+	})
+}
+
 func TestLinkByNameWhenLinkIsNotFound(t *testing.T) {
 	_, err := LinkByName("iammissing")
 	if err == nil {
@@ -2255,6 +3184,99 @@ func TestLinkSetBondSlave(t *testing.T) {
 	}
 }
 
+func TestBondSetActiveSlave(t *testing.T) {
+	minKernelRequired(t, 3, 13)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bond := NewLinkBond(LinkAttrs{Name: "foo"})
+	bond.Mode = BOND_MODE_ACTIVE_BACKUP
+	bond.ArpInterval = 100
+	bond.ArpIpTargets = []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}
+	if err := LinkAdd(bond); err != nil {
+		t.Fatal(err)
+	}
+	defer LinkDel(bond)
+
+	var slaves []Link
+	for _, name := range []string{"fooFoo", "fooBar"} {
+		if err := LinkAdd(&Dummy{LinkAttrs{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+		slave, err := LinkByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer LinkDel(slave)
+		if err := LinkSetBondSlave(slave, bond); err != nil {
+			t.Fatal(err)
+		}
+		if err := LinkSetUp(slave); err != nil {
+			t.Fatal(err)
+		}
+		slaves = append(slaves, slave)
+	}
+	if err := LinkSetUp(bond); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, slave := range slaves {
+		if err := BondSetActiveSlave(bond, slave); err != nil {
+			t.Fatal(err)
+		}
+		link, err := LinkByName("foo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := link.(*Bond)
+		if other.ActiveSlave != slave.Attrs().Index {
+			t.Fatalf("Expected active slave %d, got %d", slave.Attrs().Index, other.ActiveSlave)
+		}
+		if len(other.ArpIpTargets) != len(bond.ArpIpTargets) {
+			t.Fatalf("Expected arp ip targets %v, got %v", bond.ArpIpTargets, other.ArpIpTargets)
+		}
+	}
+}
+
+func TestParseBondAdInfo(t *testing.T) {
+	mac, _ := net.ParseMAC("06:aa:bb:cc:dd:ee")
+	info := nl.NewRtAttr(nl.IFLA_BOND_AD_INFO, nil)
+	info.AddRtAttr(nl.IFLA_BOND_AD_INFO_AGGREGATOR, nl.Uint16Attr(3))
+	info.AddRtAttr(nl.IFLA_BOND_AD_INFO_NUM_PORTS, nl.Uint16Attr(2))
+	info.AddRtAttr(nl.IFLA_BOND_AD_INFO_ACTOR_KEY, nl.Uint16Attr(9))
+	info.AddRtAttr(nl.IFLA_BOND_AD_INFO_PARTNER_KEY, nl.Uint16Attr(17))
+	info.AddRtAttr(nl.IFLA_BOND_AD_INFO_PARTNER_MAC, []byte(mac))
+	b := info.Serialize()
+	b = append(b, nl.NewRtAttr(nl.IFLA_BOND_ACTIVE_SLAVE, nl.Uint32Attr(7)).Serialize()...)
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bond := &Bond{}
+	parseBondData(bond, attrs)
+	if bond.ActiveSlave != 7 {
+		t.Fatalf("Expected active slave 7, got %d", bond.ActiveSlave)
+	}
+	expected := BondAdInfo{AggregatorId: 3, NumPorts: 2, ActorKey: 9, PartnerKey: 17, PartnerMac: mac}
+	if bond.AdInfo == nil || !reflect.DeepEqual(*bond.AdInfo, expected) {
+		t.Fatalf("Expected ad info %+v, got %+v", expected, bond.AdInfo)
+	}
+
+	slaveData := nl.NewRtAttr(nl.IFLA_BOND_SLAVE_MII_STATUS, nl.Uint8Attr(BondLinkDown)).Serialize()
+	slaveData = append(slaveData, nl.NewRtAttr(nl.IFLA_BOND_SLAVE_AD_AGGREGATOR_ID, nl.Uint16Attr(3)).Serialize()...)
+	attrs, err = nl.ParseRouteAttr(slaveData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slave := &BondSlave{}
+	parseBondSlaveData(slave, attrs)
+	if slave.MiiStatus != BondLinkDown || slave.AggregatorId != 3 {
+		t.Fatalf("Expected mii status DOWN and aggregator 3, got %s and %d", slave.MiiStatus, slave.AggregatorId)
+	}
+}
+
 func TestLinkSetAllmulticast(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -2311,3 +3333,185 @@ func TestLinkSetAllmulticast(t *testing.T) {
 		t.Fatalf("RawFlags start value:%d differs from end value:%d", rawFlagsStart, rawFlagsEnd)
 	}
 }
+
+func TestLinkDevConf(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(bridge); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile("/proc/sys/net/ipv6/conf/foo/disable_ipv6", []byte("1"), 0644); err != nil {
+		t.Skipf("cannot change disable_ipv6: %v", err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().IPv6DevConf == nil || link.Attrs().IPv6DevConf.DisableIPv6 != 1 {
+		t.Fatalf("expected IPv6 to be disabled, got %+v", link.Attrs().IPv6DevConf)
+	}
+
+	if err := LinkSetIPv4Forwarding(link, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetIPv4RpFilter(link, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetIPv4RpFilter(link, 3); err == nil {
+		t.Fatal("expected an error for an invalid rp_filter mode")
+	}
+	link, err = LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := link.Attrs().IPv4DevConf
+	if conf == nil || conf.Forwarding != 1 || conf.RpFilter != 2 {
+		t.Fatalf("IPv4 devconf not set, got %+v", conf)
+	}
+}
+
+func TestLinkAddAndGet(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	bridge := &Bridge{LinkAttrs: LinkAttrs{Name: "foo", MTU: 1400}}
+	link, err := LinkAddAndGet(bridge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bridge.Index == 0 || link.Attrs().Index != bridge.Index {
+		t.Fatalf("expected index %d to be set, got %d", bridge.Index, link.Attrs().Index)
+	}
+	if _, ok := link.(*Bridge); !ok || link.Attrs().Name != "foo" || link.Attrs().MTU != 1400 {
+		t.Fatalf("unexpected link returned: %+v", link)
+	}
+	if _, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err == nil {
+		t.Fatal("expected an error when adding an existing link")
+	}
+
+	dummy := NewDummy("dummy0")
+	if dummy.Name != "dummy0" || dummy.TxQLen != -1 || dummy.Type() != "dummy" {
+		t.Fatalf("unexpected dummy link %+v", dummy)
+	}
+	ifb := NewIfb("ifb0")
+	if ifb.Name != "ifb0" || ifb.TxQLen != -1 || ifb.Type() != "ifb" {
+		t.Fatalf("unexpected ifb link %+v", ifb)
+	}
+}
+
+func TestLinkDeserializePermHWAddr(t *testing.T) {
+	hwaddr := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	permaddr := net.HardwareAddr{0x00, 0x1b, 0x21, 0x3a, 0x4b, 0x5c}
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Type = unix.ARPHRD_ETHER
+	msg.Index = 42
+	b := msg.Serialize()
+	b = append(b, nl.NewRtAttr(unix.IFLA_IFNAME, nl.ZeroTerminated("eth42")).Serialize()...)
+	b = append(b, nl.NewRtAttr(unix.IFLA_ADDRESS, []byte(hwaddr)).Serialize()...)
+	b = append(b, nl.NewRtAttr(nl.IFLA_PERM_ADDRESS, []byte(permaddr)).Serialize()...)
+
+	link, err := LinkDeserialize(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(link.Attrs().HardwareAddr, hwaddr) {
+		t.Fatalf("Expected hardware address %s, got %s", hwaddr, link.Attrs().HardwareAddr)
+	}
+	if !bytes.Equal(link.Attrs().PermHWAddr, permaddr) {
+		t.Fatalf("Expected permanent address %s, got %s", permaddr, link.Attrs().PermHWAddr)
+	}
+}
+
+func TestLinkPermHWAddr(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	hwaddr := net.HardwareAddr{0x02, 0x00, 0x00, 0x00, 0x00, 0x01}
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo", HardwareAddr: hwaddr}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(link.Attrs().HardwareAddr, hwaddr) {
+		t.Fatalf("Expected hardware address %s, got %s", hwaddr, link.Attrs().HardwareAddr)
+	}
+	// software devices have no permanent address
+	if link.Attrs().PermHWAddr != nil {
+		t.Fatalf("Expected no permanent address, got %s", link.Attrs().PermHWAddr)
+	}
+}
+
+func TestLinkRenameSwap(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	blue, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "blue"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	green, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "green"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := LinkSetName(blue, "green"); err != unix.EEXIST {
+		t.Fatalf("Expected EEXIST renaming to an existing name, got %v", err)
+	}
+
+	// the first temporary name is taken
+	taken := &Bridge{LinkAttrs: LinkAttrs{Name: fmt.Sprintf("swap%x", blue.Attrs().Index)}}
+	if err := LinkAdd(taken); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkRenameSwap(blue, green); err != nil {
+		t.Fatal(err)
+	}
+	if blue.Attrs().Name != "green" || green.Attrs().Name != "blue" {
+		t.Fatalf("Link names not updated: %s, %s", blue.Attrs().Name, green.Attrs().Name)
+	}
+	for name, index := range map[string]int{"green": blue.Attrs().Index, "blue": green.Attrs().Index} {
+		link, err := LinkByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if link.Attrs().Index != index {
+			t.Fatalf("Link %s has index %d, expected %d", name, link.Attrs().Index, index)
+		}
+	}
+
+	missing := &Bridge{LinkAttrs: LinkAttrs{Name: "missing"}}
+	if err := LinkRenameSwap(blue, missing); err == nil {
+		t.Fatal("Expected an error swapping with a missing link")
+	} else if _, ok := err.(LinkNotFoundError); !ok {
+		t.Fatalf("Expected LinkNotFoundError, got %v", err)
+	}
+	if _, err := LinkByName("green"); err != nil {
+		t.Fatal("Link renamed although the swap failed")
+	}
+}
+
+func TestLinkDeserializePhysPort(t *testing.T) {
+	switchID := []byte{0x00, 0x15, 0x5d, 0x01, 0x02, 0x03}
+	msg := nl.NewIfInfomsg(unix.AF_UNSPEC)
+	msg.Type = unix.ARPHRD_ETHER
+	msg.Index = 42
+	b := msg.Serialize()
+	b = append(b, nl.NewRtAttr(unix.IFLA_IFNAME, nl.ZeroTerminated("eth42")).Serialize()...)
+	b = append(b, nl.NewRtAttr(unix.IFLA_PHYS_PORT_NAME, nl.ZeroTerminated("pf0vf1")).Serialize()...)
+	b = append(b, nl.NewRtAttr(unix.IFLA_PHYS_SWITCH_ID, switchID).Serialize()...)
+
+	link, err := LinkDeserialize(nil, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Attrs().PhysPortName != "pf0vf1" {
+		t.Fatalf("Expected physical port name pf0vf1, got %q", link.Attrs().PhysPortName)
+	}
+	if !bytes.Equal(link.Attrs().PhysSwitchID, switchID) {
+		t.Fatalf("Expected switch id %x, got %x", switchID, link.Attrs().PhysSwitchID)
+	}
+}
@@ -3,6 +3,7 @@ package netlink
 import (
 	"fmt"
 	"net"
+	"strings"
 )
 
 // Neigh represents a link layer neighbor from netlink.
@@ -20,9 +21,54 @@ type Neigh struct {
 	MasterIndex  int
 }
 
-// String returns $ip/$hwaddr $label
+// neighStates names the NUD_* states in the order `ip neigh` prints them
+var neighStates = []struct {
+	state int
+	name  string
+}{
+	{0x01, "INCOMPLETE"},
+	{0x02, "REACHABLE"},
+	{0x04, "STALE"},
+	{0x08, "DELAY"},
+	{0x10, "PROBE"},
+	{0x20, "FAILED"},
+	{0x40, "NOARP"},
+	{0x80, "PERMANENT"},
+}
+
+// String formats the entry like `ip neigh`:
+// $ip dev $index lladdr $hwaddr $states
 func (neigh *Neigh) String() string {
-	return fmt.Sprintf("%s %s", neigh.IP, neigh.HardwareAddr)
+	elems := []string{}
+	if neigh.IP != nil {
+		elems = append(elems, neigh.IP.String())
+	}
+	elems = append(elems, fmt.Sprintf("dev %d", neigh.LinkIndex))
+	if neigh.HardwareAddr != nil {
+		elems = append(elems, fmt.Sprintf("lladdr %s", neigh.HardwareAddr))
+	}
+	if neigh.Vlan > 0 {
+		elems = append(elems, fmt.Sprintf("vlan %d", neigh.Vlan))
+	}
+	for _, s := range neighStates {
+		if neigh.State&s.state != 0 {
+			elems = append(elems, s.name)
+		}
+	}
+	return strings.Join(elems, " ")
+}
+
+// NeighFilter selects the entries returned by NeighListFiltered. Zero
+// fields match all entries.
+type NeighFilter struct {
+	LinkIndex   int
+	MasterIndex int
+	Family      int
+	// State is a mask of NUD_* states, an entry matches if it is in any of
+	// them
+	State int
+	// Dst only matches entries whose IP is inside the prefix
+	Dst *net.IPNet
 }
 
 // NeighUpdate is sent when a neighbor changes - type is RTM_NEWNEIGH or RTM_DELNEIGH.
@@ -96,12 +96,18 @@ func (h *Handle) NeighSet(neigh *Neigh) error {
 }
 
 // NeighAppend will append an entry to FDB
+// Appending entries with the all-zeros MAC and NTF_SELF to a VXLAN device
+// adds a remote VTEP for flooding broadcast, unknown unicast and multicast
+// traffic, one entry per remote IP and VNI.
 // Equivalent to: `bridge fdb append...`
 func NeighAppend(neigh *Neigh) error {
 	return pkgHandle.NeighAppend(neigh)
 }
 
 // NeighAppend will append an entry to FDB
+// Appending entries with the all-zeros MAC and NTF_SELF to a VXLAN device
+// adds a remote VTEP for flooding broadcast, unknown unicast and multicast
+// traffic, one entry per remote IP and VNI.
 // Equivalent to: `bridge fdb append...`
 func (h *Handle) NeighAppend(neigh *Neigh) error {
 	return h.neighAdd(neigh, unix.NLM_F_CREATE|unix.NLM_F_APPEND)
@@ -221,6 +227,114 @@ func (h *Handle) NeighProxyList(linkIndex, family int) ([]Neigh, error) {
 	})
 }
 
+// NeighListFiltered returns the neighbor entries selected by filter.
+// Entries are filtered by link and master in the kernel, which avoids
+// dumping the neighbors of all links, and by state and destination
+// prefix afterwards. A nil filter returns all entries.
+// Equivalent to: `ip neighbor show [dev $link] [master $master] [nud $state] [to $prefix]`
+func NeighListFiltered(filter *NeighFilter) ([]Neigh, error) {
+	return pkgHandle.NeighListFiltered(filter)
+}
+
+// NeighListFiltered returns the neighbor entries selected by filter.
+// Entries are filtered by link and master in the kernel, which avoids
+// dumping the neighbors of all links, and by state and destination
+// prefix afterwards. A nil filter returns all entries.
+// Equivalent to: `ip neighbor show [dev $link] [master $master] [nud $state] [to $prefix]`
+func (h *Handle) NeighListFiltered(filter *NeighFilter) ([]Neigh, error) {
+	if filter == nil {
+		filter = &NeighFilter{}
+	}
+	req := h.newNetlinkRequest(unix.RTM_GETNEIGH, unix.NLM_F_DUMP)
+	req.AddData(&Ndmsg{Family: uint8(filter.Family)})
+	if filter.LinkIndex != 0 {
+		req.AddData(nl.NewRtAttr(NDA_IFINDEX, nl.Uint32Attr(uint32(filter.LinkIndex))))
+	}
+	if filter.MasterIndex != 0 {
+		req.AddData(nl.NewRtAttr(NDA_MASTER, nl.Uint32Attr(uint32(filter.MasterIndex))))
+	}
+
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWNEIGH)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Neigh
+	for _, m := range msgs {
+		neigh, err := NeighDeserialize(m)
+		if err != nil {
+			continue
+		}
+		if filter.match(neigh) {
+			res = append(res, *neigh)
+		}
+	}
+	return res, nil
+}
+
+// match reports whether neigh is selected by the filter. The link and
+// master are checked as well since older kernels ignore them in dumps.
+func (filter *NeighFilter) match(neigh *Neigh) bool {
+	if filter.LinkIndex != 0 && neigh.LinkIndex != filter.LinkIndex {
+		return false
+	}
+	if filter.MasterIndex != 0 && neigh.MasterIndex != filter.MasterIndex {
+		return false
+	}
+	if filter.Family != 0 && neigh.Family != filter.Family {
+		return false
+	}
+	if filter.State != 0 && neigh.State&filter.State == 0 {
+		return false
+	}
+	if filter.Dst != nil && (neigh.IP == nil || !filter.Dst.Contains(neigh.IP)) {
+		return false
+	}
+	return true
+}
+
+// NeighFlush deletes the dynamic ARP and NDP entries of a link and returns
+// the number of entries removed. Permanent and noarp entries are kept, as
+// with iproute2. A zero linkIndex flushes the entries of all links and a
+// zero family flushes both IPv4 and IPv6 entries.
+// Equivalent to: `ip neighbor flush dev $link`
+func NeighFlush(linkIndex, family int) (int, error) {
+	return pkgHandle.NeighFlush(linkIndex, family)
+}
+
+// NeighFlush deletes the dynamic ARP and NDP entries of a link and returns
+// the number of entries removed. Permanent and noarp entries are kept, as
+// with iproute2. A zero linkIndex flushes the entries of all links and a
+// zero family flushes both IPv4 and IPv6 entries.
+// Equivalent to: `ip neighbor flush dev $link`
+func (h *Handle) NeighFlush(linkIndex, family int) (int, error) {
+	neighs, err := h.NeighListFiltered(&NeighFilter{
+		LinkIndex: linkIndex,
+		Family:    family,
+		State:     NUD_INCOMPLETE | NUD_REACHABLE | NUD_STALE | NUD_DELAY | NUD_PROBE | NUD_FAILED,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	flushed := 0
+	for i := range neighs {
+		neigh := &neighs[i]
+		if neigh.Family != FAMILY_V4 && neigh.Family != FAMILY_V6 {
+			continue
+		}
+		if err := h.NeighDel(neigh); err != nil {
+			if err == unix.ENOENT {
+				// Already gone, e.g. garbage collected
+				continue
+			}
+			return flushed, err
+		}
+		flushed++
+	}
+	return flushed, nil
+}
+
 // NeighListExecute returns a list of neighbour entries filtered by link, ip family, flag and state.
 func NeighListExecute(msg Ndmsg) ([]Neigh, error) {
 	return pkgHandle.NeighListExecute(msg)
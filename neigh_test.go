@@ -545,3 +545,198 @@ func TestNeighSubscribeListExisting(t *testing.T) {
 		t.Fatalf("Existing add update not received as expected")
 	}
 }
+
+func TestNeighVxlanFdbAppendZeroMAC(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	vxlan := &Vxlan{LinkAttrs: LinkAttrs{Name: "vxlan0"}, VxlanId: 10, Port: 4789}
+	if err := LinkAdd(vxlan); err != nil {
+		t.Fatal(err)
+	}
+
+	// the all-zeros MAC holds the remote VTEPs used for BUM traffic
+	var entries []*Neigh
+	for i := 1; i <= 3; i++ {
+		entries = append(entries, &Neigh{
+			Family:       unix.AF_BRIDGE,
+			LinkIndex:    vxlan.Index,
+			State:        NUD_PERMANENT,
+			Flags:        NTF_SELF,
+			IP:           net.IPv4(198, 51, 100, byte(i)),
+			HardwareAddr: parseMAC("00:00:00:00:00:00"),
+			VNI:          100 + i,
+		})
+	}
+	for _, entry := range entries {
+		if err := NeighAppend(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dumpContainsFdb := func(dump []Neigh, e *Neigh) bool {
+		for _, n := range dump {
+			if n.IP.Equal(e.IP) && n.HardwareAddr.String() == e.HardwareAddr.String() && n.VNI == e.VNI {
+				return true
+			}
+		}
+		return false
+	}
+
+	dump, err := NeighList(vxlan.Index, unix.AF_BRIDGE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !dumpContainsFdb(dump, entry) {
+			t.Fatalf("FDB entry %s vni %d not found in %v", entry, entry.VNI, dump)
+		}
+	}
+
+	// deleting one remote keeps the others
+	if err := NeighDel(entries[1]); err != nil {
+		t.Fatal(err)
+	}
+	dump, err = NeighList(vxlan.Index, unix.AF_BRIDGE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dumpContainsFdb(dump, entries[1]) {
+		t.Fatalf("FDB entry %s not removed", entries[1])
+	}
+	for _, entry := range []*Neigh{entries[0], entries[2]} {
+		if !dumpContainsFdb(dump, entry) {
+			t.Fatalf("FDB entry %s vni %d removed, but shouldn't", entry, entry.VNI)
+		}
+	}
+}
+
+func TestNeighListFiltered(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	var links []Link
+	for _, name := range []string{"foo", "bar"} {
+		link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := LinkSetUp(link); err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, link)
+	}
+
+	entries := []*Neigh{
+		{LinkIndex: links[0].Attrs().Index, State: NUD_PERMANENT, IP: net.IPv4(192, 0, 2, 1), HardwareAddr: parseMAC("aa:bb:cc:dd:00:01")},
+		{LinkIndex: links[0].Attrs().Index, State: NUD_STALE, IP: net.IPv4(192, 0, 2, 2), HardwareAddr: parseMAC("aa:bb:cc:dd:00:02")},
+		{LinkIndex: links[0].Attrs().Index, State: NUD_PERMANENT, IP: net.IPv4(198, 51, 100, 1), HardwareAddr: parseMAC("aa:bb:cc:dd:00:03")},
+		{LinkIndex: links[1].Attrs().Index, State: NUD_PERMANENT, IP: net.IPv4(192, 0, 2, 3), HardwareAddr: parseMAC("aa:bb:cc:dd:00:04")},
+	}
+	for _, entry := range entries {
+		if err := NeighAdd(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, prefix, _ := net.ParseCIDR("192.0.2.0/24")
+	tests := []struct {
+		filter *NeighFilter
+		want   []*Neigh
+	}{
+		{&NeighFilter{LinkIndex: links[0].Attrs().Index, Family: FAMILY_V4}, entries[:3]},
+		{&NeighFilter{LinkIndex: links[0].Attrs().Index, State: NUD_STALE | NUD_REACHABLE}, entries[1:2]},
+		{&NeighFilter{Family: FAMILY_V4, Dst: prefix}, []*Neigh{entries[0], entries[1], entries[3]}},
+		{&NeighFilter{LinkIndex: links[1].Attrs().Index, Dst: prefix}, entries[3:]},
+	}
+	for i, test := range tests {
+		list, err := NeighListFiltered(test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []Neigh
+		for _, n := range list {
+			if n.Family == FAMILY_V4 {
+				got = append(got, n)
+			}
+		}
+		if len(got) != len(test.want) {
+			t.Fatalf("Filter %d: expected %d entries, got %v", i, len(test.want), got)
+		}
+		for _, want := range test.want {
+			if !dumpContainsNeigh(got, *want) {
+				t.Fatalf("Filter %d: entry %s not found in %v", i, want, got)
+			}
+		}
+	}
+}
+
+func TestNeighFlush(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	var links []Link
+	for _, name := range []string{"foo", "bar"} {
+		link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: name}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := LinkSetUp(link); err != nil {
+			t.Fatal(err)
+		}
+		links = append(links, link)
+	}
+
+	entries := []*Neigh{
+		{LinkIndex: links[0].Attrs().Index, State: NUD_STALE, IP: net.IPv4(192, 0, 2, 1), HardwareAddr: parseMAC("aa:bb:cc:dd:00:01")},
+		{LinkIndex: links[0].Attrs().Index, State: NUD_REACHABLE, IP: net.IPv4(192, 0, 2, 2), HardwareAddr: parseMAC("aa:bb:cc:dd:00:02")},
+		{LinkIndex: links[0].Attrs().Index, State: NUD_PERMANENT, IP: net.IPv4(192, 0, 2, 3), HardwareAddr: parseMAC("aa:bb:cc:dd:00:03")},
+		{LinkIndex: links[1].Attrs().Index, State: NUD_STALE, IP: net.IPv4(192, 0, 2, 4), HardwareAddr: parseMAC("aa:bb:cc:dd:00:04")},
+	}
+	for _, entry := range entries {
+		if err := NeighAdd(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	flushed, err := NeighFlush(links[0].Attrs().Index, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flushed != 2 {
+		t.Fatalf("Expected 2 entries flushed, got %d", flushed)
+	}
+
+	list, err := NeighList(0, FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries[:2] {
+		if dumpContainsNeigh(list, *entry) {
+			t.Fatalf("Entry %s not flushed", entry)
+		}
+	}
+	for _, entry := range entries[2:] {
+		if !dumpContainsNeigh(list, *entry) {
+			t.Fatalf("Entry %s flushed unexpectedly", entry)
+		}
+	}
+}
+
+func TestNeighString(t *testing.T) {
+	mac, _ := net.ParseMAC("aa:bb:cc:dd:ee:ff")
+	neigh := &Neigh{
+		LinkIndex:    3,
+		IP:           net.ParseIP("10.0.0.1"),
+		HardwareAddr: mac,
+		State:        NUD_REACHABLE,
+	}
+	if s := neigh.String(); s != "10.0.0.1 dev 3 lladdr aa:bb:cc:dd:ee:ff REACHABLE" {
+		t.Fatalf("Unexpected neigh string %q", s)
+	}
+
+	neigh = &Neigh{LinkIndex: 3, HardwareAddr: mac, Vlan: 10, State: NUD_NOARP | NUD_PERMANENT}
+	if s := neigh.String(); s != "dev 3 lladdr aa:bb:cc:dd:ee:ff vlan 10 NOARP PERMANENT" {
+		t.Fatalf("Unexpected neigh string %q", s)
+	}
+}
@@ -18,6 +18,14 @@ var (
 	ErrNotImplemented = errors.New("not implemented")
 )
 
+// RawAttribute is a netlink attribute this package does not decode. It is
+// kept verbatim so that an object can be listed and written back without
+// losing attributes introduced by newer kernels.
+type RawAttribute struct {
+	Type  uint16
+	Value []byte
+}
+
 // ParseIPNet parses a string in ip/net format and returns a net.IPNet.
 // This is valuable because addresses in netlink are often IPNets and
 // ParseCIDR returns an IPNet with the IP part set to the base IP of the
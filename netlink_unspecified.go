@@ -1,13 +1,22 @@
+//go:build !linux
 // +build !linux
 
 package netlink
 
-import "net"
+import (
+	"context"
+	"net"
+	"time"
+)
 
 func LinkSetUp(link Link) error {
 	return ErrNotImplemented
 }
 
+func LinkSetUpAndWait(link Link, timeout time.Duration) error {
+	return ErrNotImplemented
+}
+
 func LinkSetDown(link Link) error {
 	return ErrNotImplemented
 }
@@ -32,10 +41,26 @@ func LinkSetName(link Link, name string) error {
 	return ErrNotImplemented
 }
 
+func LinkRenameSwap(a, b Link) error {
+	return ErrNotImplemented
+}
+
+func LinkSetLinkMode(link Link, mode LinkMode) error {
+	return ErrNotImplemented
+}
+
 func LinkSetAlias(link Link, name string) error {
 	return ErrNotImplemented
 }
 
+func LinkAddAltName(link Link, name string) error {
+	return ErrNotImplemented
+}
+
+func LinkDelAltName(link Link, name string) error {
+	return ErrNotImplemented
+}
+
 func LinkSetHardwareAddr(link Link, hwaddr net.HardwareAddr) error {
 	return ErrNotImplemented
 }
@@ -52,6 +77,10 @@ func LinkSetVfVlanQos(link Link, vf, vlan, qos int) error {
 	return ErrNotImplemented
 }
 
+func LinkSetVfVlanQosProto(link Link, vf, vlan, qos int, proto uint16) error {
+	return ErrNotImplemented
+}
+
 func LinkSetVfTxRate(link Link, vf, rate int) error {
 	return ErrNotImplemented
 }
@@ -60,6 +89,10 @@ func LinkSetVfRate(link Link, vf, minRate, maxRate int) error {
 	return ErrNotImplemented
 }
 
+func LinkSetVfRssQuery(link Link, vf int, state bool) error {
+	return ErrNotImplemented
+}
+
 func LinkSetNoMaster(link Link) error {
 	return ErrNotImplemented
 }
@@ -72,6 +105,18 @@ func LinkSetXdpFd(link Link, fd int) error {
 	return ErrNotImplemented
 }
 
+func LinkSetXdpFdWithFlags(link Link, fd, flags int) error {
+	return ErrNotImplemented
+}
+
+func LinkSetIPv4Forwarding(link Link, enable bool) error {
+	return ErrNotImplemented
+}
+
+func LinkSetIPv4RpFilter(link Link, mode int) error {
+	return ErrNotImplemented
+}
+
 func LinkSetARPOff(link Link) error {
 	return ErrNotImplemented
 }
@@ -88,6 +133,10 @@ func LinkByAlias(alias string) (Link, error) {
 	return nil, ErrNotImplemented
 }
 
+func LinkByAltName(name string) (Link, error) {
+	return nil, ErrNotImplemented
+}
+
 func LinkByIndex(index int) (Link, error) {
 	return nil, ErrNotImplemented
 }
@@ -116,6 +165,22 @@ func LinkSetFlood(link Link, mode bool) error {
 	return ErrNotImplemented
 }
 
+func LinkSetBrNeighSuppress(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func LinkSetMcastFlood(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func LinkSetMcastToUcast(link Link, mode bool) error {
+	return ErrNotImplemented
+}
+
+func LinkSetMulticastRouter(link Link, router uint8) error {
+	return ErrNotImplemented
+}
+
 func LinkSetTxQLen(link Link, qlen int) error {
 	return ErrNotImplemented
 }
@@ -124,6 +189,10 @@ func LinkAdd(link Link) error {
 	return ErrNotImplemented
 }
 
+func LinkAddAndGet(link Link) (Link, error) {
+	return nil, ErrNotImplemented
+}
+
 func LinkDel(link Link) error {
 	return ErrNotImplemented
 }
@@ -156,6 +225,18 @@ func LinkList() ([]Link, error) {
 	return nil, ErrNotImplemented
 }
 
+func LinkListContext(ctx context.Context) ([]Link, error) {
+	return nil, ErrNotImplemented
+}
+
+func LinkStatsPoll(ctx context.Context, interval time.Duration, ifindices []int) (<-chan LinkStatsSample, error) {
+	return nil, ErrNotImplemented
+}
+
+func LinkXStats(link Link) (*LinkXStatistics, error) {
+	return nil, ErrNotImplemented
+}
+
 func AddrAdd(link Link, addr *Addr) error {
 	return ErrNotImplemented
 }
@@ -168,6 +249,10 @@ func AddrDel(link Link, addr *Addr) error {
 	return ErrNotImplemented
 }
 
+func AddrReplaceAll(link Link, addrs []*Addr) error {
+	return ErrNotImplemented
+}
+
 func AddrList(link Link, family int) ([]Addr, error) {
 	return nil, ErrNotImplemented
 }
@@ -184,6 +269,26 @@ func RouteList(link Link, family int) ([]Route, error) {
 	return nil, ErrNotImplemented
 }
 
+func RouteTableList(family int) ([]int, error) {
+	return nil, ErrNotImplemented
+}
+
+func NexthopAdd(nh *Nexthop) error {
+	return ErrNotImplemented
+}
+
+func NexthopReplace(nh *Nexthop) error {
+	return ErrNotImplemented
+}
+
+func NexthopDel(nh *Nexthop) error {
+	return ErrNotImplemented
+}
+
+func NexthopList(family int) ([]Nexthop, error) {
+	return nil, ErrNotImplemented
+}
+
 func XfrmPolicyAdd(policy *XfrmPolicy) error {
 	return ErrNotImplemented
 }
@@ -228,6 +333,14 @@ func NeighList(linkIndex, family int) ([]Neigh, error) {
 	return nil, ErrNotImplemented
 }
 
+func NeighListFiltered(filter *NeighFilter) ([]Neigh, error) {
+	return nil, ErrNotImplemented
+}
+
+func NeighFlush(linkIndex, family int) (int, error) {
+	return 0, ErrNotImplemented
+}
+
 func NeighDeserialize(m []byte) (*Neigh, error) {
 	return nil, ErrNotImplemented
 }
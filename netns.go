@@ -0,0 +1,15 @@
+package netlink
+
+import "github.com/vishvananda/netns"
+
+// NetNsInfo is a named network namespace as returned by ListNetNs.
+type NetNsInfo struct {
+	Name string
+	Path string
+	// Dev and Inode identify the namespace, names bound to the same
+	// namespace have the same Dev and Inode
+	Dev   uint64
+	Inode uint64
+	// Handle is open on the namespace and must be closed by the caller
+	Handle netns.NsHandle
+}
@@ -14,8 +14,12 @@ package netlink
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 
 	"github.com/vishvananda/netlink/nl"
+	"github.com/vishvananda/netns"
 	"golang.org/x/sys/unix"
 )
 
@@ -79,6 +83,104 @@ func SetNetNsIdByFd(fd, nsid int) error {
 	return pkgHandle.SetNetNsIdByFd(fd, nsid)
 }
 
+// netnsRunDir is where `ip netns` binds named network namespaces
+const netnsRunDir = "/var/run/netns"
+
+// ListNetNs returns the named network namespaces of /var/run/netns, as
+// created by `ip netns add`. The caller must close the handle of each
+// namespace. Files that are not bound to a namespace are skipped.
+func ListNetNs() ([]NetNsInfo, error) {
+	entries, err := ioutil.ReadDir(netnsRunDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var res []NetNsInfo
+	for _, entry := range entries {
+		path := filepath.Join(netnsRunDir, entry.Name())
+		ns, err := netns.GetFromPath(path)
+		if err != nil {
+			continue
+		}
+		var fs unix.Statfs_t
+		var stat unix.Stat_t
+		if unix.Fstatfs(int(ns), &fs) != nil || fs.Type != unix.NSFS_MAGIC ||
+			unix.Fstat(int(ns), &stat) != nil {
+			ns.Close()
+			continue
+		}
+		res = append(res, NetNsInfo{
+			Name:   entry.Name(),
+			Path:   path,
+			Dev:    uint64(stat.Dev),
+			Inode:  uint64(stat.Ino),
+			Handle: ns,
+		})
+	}
+	return res, nil
+}
+
+// GetNetNsHandleById finds the network namespace with the given ID, as seen
+// from the namespace of the handle, among the namespaces named in
+// /var/run/netns and those of the running processes. The caller must
+// close the returned handle.
+func (h *Handle) GetNetNsHandleById(id int) (netns.NsHandle, error) {
+	if id < 0 {
+		return netns.None(), fmt.Errorf("invalid netns id %d", id)
+	}
+	paths, err := filepath.Glob(filepath.Join(netnsRunDir, "*"))
+	if err != nil {
+		return netns.None(), err
+	}
+	if procs, err := ioutil.ReadDir("/proc"); err == nil {
+		for _, proc := range procs {
+			if proc.IsDir() && proc.Name()[0] >= '0' && proc.Name()[0] <= '9' {
+				paths = append(paths, filepath.Join("/proc", proc.Name(), "ns/net"))
+			}
+		}
+	}
+
+	type nsKey struct {
+		dev uint64
+		ino uint64
+	}
+	seen := make(map[nsKey]bool)
+	for _, path := range paths {
+		var stat unix.Stat_t
+		if err := unix.Stat(path, &stat); err != nil {
+			// processes may exit while we look
+			continue
+		}
+		key := nsKey{uint64(stat.Dev), uint64(stat.Ino)}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		ns, err := netns.GetFromPath(path)
+		if err != nil {
+			continue
+		}
+		nsid, err := h.GetNetNsIdByFd(int(ns))
+		if err == nil && nsid == id {
+			return ns, nil
+		}
+		ns.Close()
+	}
+	return netns.None(), fmt.Errorf("no network namespace with id %d found", id)
+}
+
+// GetNetNsHandleById finds the network namespace with the given ID, as seen
+// from the current namespace, among the namespaces named in /var/run/netns
+// and those of the running processes. The caller must close the returned
+// handle.
+func GetNetNsHandleById(id int) (netns.NsHandle, error) {
+	return pkgHandle.GetNetNsHandleById(id)
+}
+
 // getNetNsId requests the netnsid for a given type-val pair
 // type should be either NETNSA_PID or NETNSA_FD
 func (h *Handle) getNetNsId(attrType int, val uint32) (int, error) {
@@ -3,12 +3,15 @@
 package netlink
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"syscall"
 	"testing"
 
 	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
 )
 
 // TestNetNsIdByFd tests setting and getting the network namespace ID
@@ -75,3 +78,94 @@ func TestNetNsIdByPid(t *testing.T) {
 		t.Errorf("GetNetNsIdByPid returned %d, want %d", haveID, wantID)
 	}
 }
+
+// TestGetNetNsHandleById names a namespace the way `ip netns add` does and
+// looks it up by its ID.
+func TestGetNetNsHandleById(t *testing.T) {
+	skipUnlessRoot(t)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	origNs, err := netns.Get()
+	CheckErrorFail(t, err)
+	defer origNs.Close()
+
+	ns, err := netns.New()
+	CheckErrorFail(t, err)
+	defer ns.Close()
+	CheckErrorFail(t, netns.Set(origNs))
+
+	CheckErrorFail(t, os.MkdirAll("/var/run/netns", 0755))
+	path := filepath.Join("/var/run/netns", fmt.Sprintf("nltest%d", os.Getpid()))
+	f, err := os.Create(path)
+	CheckErrorFail(t, err)
+	f.Close()
+	defer os.Remove(path)
+	CheckErrorFail(t, unix.Mount(fmt.Sprintf("/proc/self/fd/%d", int(ns)), path, "", unix.MS_BIND, ""))
+	defer unix.Unmount(path, unix.MNT_DETACH)
+
+	wantID := os.Getpid()<<16 + 1
+	CheckErrorFail(t, SetNetNsIdByFd(int(ns), wantID))
+
+	found, err := GetNetNsHandleById(wantID)
+	CheckErrorFail(t, err)
+	defer found.Close()
+	if !found.Equal(ns) {
+		t.Fatalf("GetNetNsHandleById returned %s, want %s", found, ns)
+	}
+
+	if _, err := GetNetNsHandleById(wantID + 1); err == nil {
+		t.Fatal("Expected an error for an unknown netns id")
+	}
+}
+
+func TestListNetNs(t *testing.T) {
+	skipUnlessRoot(t)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	origNs, err := netns.Get()
+	CheckErrorFail(t, err)
+	defer origNs.Close()
+
+	ns, err := netns.New()
+	CheckErrorFail(t, err)
+	defer ns.Close()
+	CheckErrorFail(t, netns.Set(origNs))
+
+	CheckErrorFail(t, os.MkdirAll("/var/run/netns", 0755))
+	name := fmt.Sprintf("nltest%d", os.Getpid())
+	path := filepath.Join("/var/run/netns", name)
+	f, err := os.Create(path)
+	CheckErrorFail(t, err)
+	f.Close()
+	defer os.Remove(path)
+	CheckErrorFail(t, unix.Mount(fmt.Sprintf("/proc/self/fd/%d", int(ns)), path, "", unix.MS_BIND, ""))
+	defer unix.Unmount(path, unix.MNT_DETACH)
+
+	// a file that was never bound to a namespace is skipped
+	stale := path + "-stale"
+	f, err = os.Create(stale)
+	CheckErrorFail(t, err)
+	f.Close()
+	defer os.Remove(stale)
+
+	infos, err := ListNetNs()
+	CheckErrorFail(t, err)
+	found := false
+	for _, info := range infos {
+		defer info.Handle.Close()
+		switch info.Name {
+		case name:
+			found = true
+			if info.Path != path || info.Inode == 0 || !info.Handle.Equal(ns) {
+				t.Fatalf("Unexpected namespace %+v", info)
+			}
+		case name + "-stale":
+			t.Fatalf("Unbound file %s listed as a namespace", stale)
+		}
+	}
+	if !found {
+		t.Fatalf("Namespace %s not listed in %+v", name, infos)
+	}
+}
@@ -2,6 +2,8 @@
 
 package netlink
 
+import "github.com/vishvananda/netns"
+
 func GetNetNsIdByPid(pid int) (int, error) {
 	return 0, ErrNotImplemented
 }
@@ -17,3 +19,11 @@ func GetNetNsIdByFd(fd int) (int, error) {
 func SetNetNsIdByFd(fd, nsid int) error {
 	return ErrNotImplemented
 }
+
+func GetNetNsHandleById(id int) (netns.NsHandle, error) {
+	return netns.None(), ErrNotImplemented
+}
+
+func ListNetNs() ([]NetNsInfo, error) {
+	return nil, ErrNotImplemented
+}
@@ -0,0 +1,45 @@
+package netlink
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Nexthop represents a nexthop object that routes can reference by ID.
+// A nexthop either describes a single hop (LinkIndex and/or Gw, or
+// Blackhole) or a group of other nexthops (Group).
+type Nexthop struct {
+	ID        uint32
+	Family    int
+	Protocol  int
+	Flags     int
+	LinkIndex int
+	Gw        net.IP
+	Blackhole bool
+	Group     []NexthopGroupEntry
+}
+
+// NexthopGroupEntry is a member of a nexthop group. Weight is the relative
+// weight of the member in the range 1-256; 0 is treated as 1.
+type NexthopGroupEntry struct {
+	ID     uint32
+	Weight int
+}
+
+func (nh Nexthop) String() string {
+	elems := []string{fmt.Sprintf("ID: %d", nh.ID)}
+	if len(nh.Group) > 0 {
+		group := []string{}
+		for _, entry := range nh.Group {
+			group = append(group, fmt.Sprintf("%d,%d", entry.ID, entry.Weight))
+		}
+		elems = append(elems, fmt.Sprintf("Group: %s", strings.Join(group, "/")))
+	} else if nh.Blackhole {
+		elems = append(elems, "Blackhole")
+	} else {
+		elems = append(elems, fmt.Sprintf("Ifindex: %d", nh.LinkIndex))
+		elems = append(elems, fmt.Sprintf("Gw: %s", nh.Gw))
+	}
+	return fmt.Sprintf("{%s}", strings.Join(elems, " "))
+}
@@ -0,0 +1,195 @@
+package netlink
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+)
+
+// NexthopAdd will add a nexthop object to the system.
+// Equivalent to: `ip nexthop add $nexthop`
+func NexthopAdd(nh *Nexthop) error {
+	return pkgHandle.NexthopAdd(nh)
+}
+
+// NexthopAdd will add a nexthop object to the system.
+// Equivalent to: `ip nexthop add $nexthop`
+func (h *Handle) NexthopAdd(nh *Nexthop) error {
+	flags := unix.NLM_F_CREATE | unix.NLM_F_EXCL | unix.NLM_F_ACK
+	req := h.newNetlinkRequest(nl.RTM_NEWNEXTHOP, flags)
+	return nexthopHandle(nh, req)
+}
+
+// NexthopReplace will add a nexthop object to the system or replace the
+// one with the same ID. Routes referencing the nexthop are updated.
+// Equivalent to: `ip nexthop replace $nexthop`
+func NexthopReplace(nh *Nexthop) error {
+	return pkgHandle.NexthopReplace(nh)
+}
+
+// NexthopReplace will add a nexthop object to the system or replace the
+// one with the same ID. Routes referencing the nexthop are updated.
+// Equivalent to: `ip nexthop replace $nexthop`
+func (h *Handle) NexthopReplace(nh *Nexthop) error {
+	flags := unix.NLM_F_CREATE | unix.NLM_F_REPLACE | unix.NLM_F_ACK
+	req := h.newNetlinkRequest(nl.RTM_NEWNEXTHOP, flags)
+	return nexthopHandle(nh, req)
+}
+
+// NexthopDel will delete a nexthop object from the system.
+// Equivalent to: `ip nexthop del id $id`
+func NexthopDel(nh *Nexthop) error {
+	return pkgHandle.NexthopDel(nh)
+}
+
+// NexthopDel will delete a nexthop object from the system.
+// Equivalent to: `ip nexthop del id $id`
+func (h *Handle) NexthopDel(nh *Nexthop) error {
+	req := h.newNetlinkRequest(nl.RTM_DELNEXTHOP, unix.NLM_F_ACK)
+	req.AddData(&nl.NhMsg{Family: unix.AF_UNSPEC})
+	req.AddData(nl.NewRtAttr(nl.NHA_ID, nl.Uint32Attr(nh.ID)))
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
+func nexthopHandle(nh *Nexthop, req *nl.NetlinkRequest) error {
+	msg := &nl.NhMsg{
+		Family:   uint8(nh.Family),
+		Protocol: uint8(nh.Protocol),
+		Flags:    uint32(nh.Flags),
+	}
+	var attrs []*nl.RtAttr
+	if nh.ID > 0 {
+		attrs = append(attrs, nl.NewRtAttr(nl.NHA_ID, nl.Uint32Attr(nh.ID)))
+	}
+
+	switch {
+	case len(nh.Group) > 0:
+		if nh.Gw != nil || nh.LinkIndex != 0 || nh.Blackhole {
+			return fmt.Errorf("nexthop group can not have a gateway, link or blackhole")
+		}
+		// groups are always unspecified family
+		msg.Family = unix.AF_UNSPEC
+		buf := []byte{}
+		for _, entry := range nh.Group {
+			grp := nl.NexthopGrp{Id: entry.ID}
+			if entry.Weight > 0 {
+				if entry.Weight > 256 {
+					return fmt.Errorf("nexthop group weight %d is out of range 1-256", entry.Weight)
+				}
+				grp.Weight = uint8(entry.Weight - 1)
+			}
+			buf = append(buf, grp.Serialize()...)
+		}
+		attrs = append(attrs, nl.NewRtAttr(nl.NHA_GROUP, buf))
+		attrs = append(attrs, nl.NewRtAttr(nl.NHA_GROUP_TYPE, nl.Uint16Attr(nl.NEXTHOP_GRP_TYPE_MPATH)))
+	case nh.Blackhole:
+		if nh.Gw != nil || nh.LinkIndex != 0 {
+			return fmt.Errorf("blackhole nexthop can not have a gateway or link")
+		}
+		if msg.Family == unix.AF_UNSPEC {
+			msg.Family = FAMILY_V4
+		}
+		attrs = append(attrs, nl.NewRtAttr(nl.NHA_BLACKHOLE, nil))
+	default:
+		if nh.Gw == nil && nh.LinkIndex == 0 {
+			return fmt.Errorf("one of Gw, LinkIndex, Blackhole or Group must be set")
+		}
+		if nh.Gw != nil {
+			gwFamily := nl.GetIPFamily(nh.Gw)
+			if msg.Family != unix.AF_UNSPEC && int(msg.Family) != gwFamily {
+				return fmt.Errorf("gateway and nexthop are not the same IP family")
+			}
+			msg.Family = uint8(gwFamily)
+			var gwData []byte
+			if gwFamily == FAMILY_V4 {
+				gwData = nh.Gw.To4()
+			} else {
+				gwData = nh.Gw.To16()
+			}
+			attrs = append(attrs, nl.NewRtAttr(nl.NHA_GATEWAY, gwData))
+		}
+		if msg.Family == unix.AF_UNSPEC {
+			msg.Family = FAMILY_V4
+		}
+		if nh.LinkIndex != 0 {
+			attrs = append(attrs, nl.NewRtAttr(nl.NHA_OIF, nl.Uint32Attr(uint32(nh.LinkIndex))))
+		}
+	}
+
+	req.AddData(msg)
+	for _, attr := range attrs {
+		req.AddData(attr)
+	}
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
+// NexthopList gets a list of nexthop objects in the system.
+// Equivalent to: `ip nexthop show`.
+// The list can be filtered by ip family.
+func NexthopList(family int) ([]Nexthop, error) {
+	return pkgHandle.NexthopList(family)
+}
+
+// NexthopList gets a list of nexthop objects in the system.
+// Equivalent to: `ip nexthop show`.
+// The list can be filtered by ip family.
+func (h *Handle) NexthopList(family int) ([]Nexthop, error) {
+	req := h.newNetlinkRequest(nl.RTM_GETNEXTHOP, unix.NLM_F_DUMP)
+	req.AddData(&nl.NhMsg{Family: uint8(family)})
+
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, nl.RTM_NEWNEXTHOP)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []Nexthop
+	for _, m := range msgs {
+		nh, err := deserializeNexthop(m)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, nh)
+	}
+	return res, nil
+}
+
+// deserializeNexthop decodes a binary netlink message into a Nexthop struct
+func deserializeNexthop(m []byte) (Nexthop, error) {
+	msg := nl.DeserializeNhMsg(m)
+	attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+	if err != nil {
+		return Nexthop{}, err
+	}
+	nh := Nexthop{
+		Family:   int(msg.Family),
+		Protocol: int(msg.Protocol),
+		Flags:    int(msg.Flags),
+	}
+
+	native := nl.NativeEndian()
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.NHA_ID:
+			nh.ID = native.Uint32(attr.Value[0:4])
+		case nl.NHA_OIF:
+			nh.LinkIndex = int(native.Uint32(attr.Value[0:4]))
+		case nl.NHA_GATEWAY:
+			nh.Gw = net.IP(attr.Value)
+		case nl.NHA_BLACKHOLE:
+			nh.Blackhole = true
+		case nl.NHA_GROUP:
+			for i := 0; i+nl.SizeofNexthopGrp <= len(attr.Value); i += nl.SizeofNexthopGrp {
+				grp := nl.DeserializeNexthopGrp(attr.Value[i:])
+				nh.Group = append(nh.Group, NexthopGroupEntry{
+					ID:     grp.Id,
+					Weight: int(grp.Weight) + 1,
+				})
+			}
+		}
+	}
+	return nh, nil
+}
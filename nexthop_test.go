@@ -0,0 +1,125 @@
+// +build linux
+
+package netlink
+
+import (
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestNexthopAddListDel(t *testing.T) {
+	minKernelRequired(t, 5, 3)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	la := NewLinkAttrs()
+	la.Name = "dummy_nh"
+	if err := LinkAdd(&Dummy{la}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("dummy_nh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	addr := &Addr{IPNet: &net.IPNet{IP: net.IPv4(192, 168, 1, 1), Mask: net.CIDRMask(24, 32)}}
+	if err := AddrAdd(link, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	nh1 := &Nexthop{ID: 1, LinkIndex: link.Attrs().Index, Gw: net.IPv4(192, 168, 1, 2)}
+	nh2 := &Nexthop{ID: 2, LinkIndex: link.Attrs().Index, Gw: net.IPv4(192, 168, 1, 3)}
+	group := &Nexthop{ID: 10, Group: []NexthopGroupEntry{{ID: 1}, {ID: 2, Weight: 3}}}
+	for _, nh := range []*Nexthop{nh1, nh2, group} {
+		if err := NexthopAdd(nh); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nexthops, err := NexthopList(FAMILY_ALL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nexthops) != 3 {
+		t.Fatalf("Expected 3 nexthops, got %d", len(nexthops))
+	}
+	for _, nh := range nexthops {
+		switch nh.ID {
+		case 1:
+			if !nh.Gw.Equal(nh1.Gw) || nh.LinkIndex != link.Attrs().Index {
+				t.Fatalf("Nexthop %s doesn't match %s", nh, nh1)
+			}
+		case 10:
+			if len(nh.Group) != 2 || nh.Group[0].Weight != 1 || nh.Group[1].Weight != 3 {
+				t.Fatalf("Nexthop group %s doesn't match %s", nh, group)
+			}
+		}
+	}
+
+	dst := &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	route := &Route{Dst: dst, NhID: group.ID}
+	if err := RouteAdd(route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{Dst: dst}, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || routes[0].NhID != group.ID {
+		t.Fatalf("Route via nexthop not added properly: %v", routes)
+	}
+
+	// update the group in one place
+	group.Group = []NexthopGroupEntry{{ID: 2}}
+	if err := NexthopReplace(group); err != nil {
+		t.Fatal(err)
+	}
+	nexthops, err = NexthopList(FAMILY_ALL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, nh := range nexthops {
+		if nh.ID == group.ID && (len(nh.Group) != 1 || nh.Group[0].ID != 2) {
+			t.Fatalf("Nexthop group not replaced: %s", nh)
+		}
+	}
+
+	if err := RouteDel(route); err != nil {
+		t.Fatal(err)
+	}
+	for _, nh := range []*Nexthop{group, nh1, nh2} {
+		if err := NexthopDel(nh); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nexthops, err = NexthopList(FAMILY_ALL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nexthops) != 0 {
+		t.Fatal("Nexthops not removed properly")
+	}
+}
+
+func TestRouteNexthopIdExclusive(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	dst := &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	for _, route := range []*Route{
+		{Dst: dst, NhID: 1, Gw: net.IPv4(127, 0, 0, 2)},
+		{Dst: dst, NhID: 1, MultiPath: []*NexthopInfo{{LinkIndex: 1}}},
+		{Dst: dst, NhID: 1, LinkIndex: 1},
+	} {
+		err := RouteAdd(route)
+		if err == nil {
+			t.Fatalf("Expected an error for route %s with a nexthop id", route)
+		}
+		if _, ok := err.(syscall.Errno); ok {
+			t.Fatalf("Route %s rejected by the kernel instead of the client: %v", route, err)
+		}
+	}
+}
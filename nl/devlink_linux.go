@@ -10,16 +10,27 @@ const (
 
 const (
 	DEVLINK_CMD_GET         = 1
+	DEVLINK_CMD_PORT_GET    = 5
 	DEVLINK_CMD_ESWITCH_GET = 29
 	DEVLINK_CMD_ESWITCH_SET = 30
 )
 
 const (
-	DEVLINK_ATTR_BUS_NAME            = 1
-	DEVLINK_ATTR_DEV_NAME            = 2
-	DEVLINK_ATTR_ESWITCH_MODE        = 25
-	DEVLINK_ATTR_ESWITCH_INLINE_MODE = 26
-	DEVLINK_ATTR_ESWITCH_ENCAP_MODE  = 62
+	DEVLINK_ATTR_BUS_NAME                  = 1
+	DEVLINK_ATTR_DEV_NAME                  = 2
+	DEVLINK_ATTR_PORT_INDEX                = 3
+	DEVLINK_ATTR_PORT_TYPE                 = 4
+	DEVLINK_ATTR_PORT_NETDEV_IFINDEX       = 6
+	DEVLINK_ATTR_PORT_NETDEV_NAME          = 7
+	DEVLINK_ATTR_PORT_IBDEV_NAME           = 8
+	DEVLINK_ATTR_PORT_SPLIT_COUNT          = 9
+	DEVLINK_ATTR_PORT_SPLIT_GROUP          = 10
+	DEVLINK_ATTR_ESWITCH_MODE              = 25
+	DEVLINK_ATTR_ESWITCH_INLINE_MODE       = 26
+	DEVLINK_ATTR_ESWITCH_ENCAP_MODE        = 62
+	DEVLINK_ATTR_PORT_FLAVOUR              = 77
+	DEVLINK_ATTR_PORT_NUMBER               = 78
+	DEVLINK_ATTR_PORT_SPLIT_SUBPORT_NUMBER = 79
 )
 
 const (
@@ -38,3 +49,20 @@ const (
 	DEVLINK_ESWITCH_ENCAP_MODE_NONE  = 0
 	DEVLINK_ESWITCH_ENCAP_MODE_BASIC = 1
 )
+
+const (
+	DEVLINK_PORT_TYPE_NOTSET = 0
+	DEVLINK_PORT_TYPE_AUTO   = 1
+	DEVLINK_PORT_TYPE_ETH    = 2
+	DEVLINK_PORT_TYPE_IB     = 3
+)
+
+const (
+	DEVLINK_PORT_FLAVOUR_PHYSICAL = 0
+	DEVLINK_PORT_FLAVOUR_CPU      = 1
+	DEVLINK_PORT_FLAVOUR_DSA      = 2
+	DEVLINK_PORT_FLAVOUR_PCI_PF   = 3
+	DEVLINK_PORT_FLAVOUR_PCI_VF   = 4
+	DEVLINK_PORT_FLAVOUR_VIRTUAL  = 5
+	DEVLINK_PORT_FLAVOUR_UNUSED   = 6
+)
@@ -1,6 +1,7 @@
 package nl
 
 import (
+	"encoding/binary"
 	"unsafe"
 )
 
@@ -8,6 +9,30 @@ const (
 	DEFAULT_CHANGE = 0xFFFFFFFF
 )
 
+// Link attributes missing from golang.org/x/sys/unix
+const (
+	IFLA_PROP_LIST    = 0x34
+	IFLA_ALT_IFNAME   = 0x35
+	IFLA_PERM_ADDRESS = 0x36
+
+	IFLA_TSO_MAX_SIZE               = 0x3b
+	IFLA_TSO_MAX_SEGS               = 0x3c
+	IFLA_ALLMULTI                   = 0x3d
+	IFLA_DEVLINK_PORT               = 0x3e
+	IFLA_DPLL_PIN                   = 0x41
+	IFLA_MAX_PACING_OFFLOAD_HORIZON = 0x42
+	IFLA_NETNS_IMMUTABLE            = 0x43
+	IFLA_HEADROOM                   = 0x44
+	IFLA_TAILROOM                   = 0x45
+)
+
+// Link property messages missing from golang.org/x/sys/unix
+const (
+	RTM_NEWLINKPROP = 0x6c
+	RTM_DELLINKPROP = 0x6d
+	RTM_GETLINKPROP = 0x6e
+)
+
 const (
 	IFLA_INFO_UNSPEC = iota
 	IFLA_INFO_KIND
@@ -61,7 +86,11 @@ const (
 	IFLA_VXLAN_GBP
 	IFLA_VXLAN_REMCSUM_NOPARTIAL
 	IFLA_VXLAN_FLOWBASED
-	IFLA_VXLAN_MAX = IFLA_VXLAN_FLOWBASED
+	IFLA_VXLAN_LABEL
+	IFLA_VXLAN_GPE
+	IFLA_VXLAN_TTL_INHERIT
+	IFLA_VXLAN_DF
+	IFLA_VXLAN_MAX = IFLA_VXLAN_DF
 )
 
 const (
@@ -83,7 +112,28 @@ const (
 	IFLA_BRPORT_PROXYARP
 	IFLA_BRPORT_LEARNING_SYNC
 	IFLA_BRPORT_PROXYARP_WIFI
-	IFLA_BRPORT_MAX = IFLA_BRPORT_PROXYARP_WIFI
+	IFLA_BRPORT_ROOT_ID
+	IFLA_BRPORT_BRIDGE_ID
+	IFLA_BRPORT_DESIGNATED_PORT
+	IFLA_BRPORT_DESIGNATED_COST
+	IFLA_BRPORT_ID
+	IFLA_BRPORT_NO
+	IFLA_BRPORT_TOPOLOGY_CHANGE_ACK
+	IFLA_BRPORT_CONFIG_PENDING
+	IFLA_BRPORT_MESSAGE_AGE_TIMER
+	IFLA_BRPORT_FORWARD_DELAY_TIMER
+	IFLA_BRPORT_HOLD_TIMER
+	IFLA_BRPORT_FLUSH
+	IFLA_BRPORT_MULTICAST_ROUTER
+	IFLA_BRPORT_PAD
+	IFLA_BRPORT_MCAST_FLOOD
+	IFLA_BRPORT_MCAST_TO_UCAST
+	IFLA_BRPORT_VLAN_TUNNEL
+	IFLA_BRPORT_BCAST_FLOOD
+	IFLA_BRPORT_GROUP_FWD_MASK
+	IFLA_BRPORT_NEIGH_SUPPRESS
+	IFLA_BRPORT_ISOLATED
+	IFLA_BRPORT_MAX = IFLA_BRPORT_ISOLATED
 )
 
 const (
@@ -191,7 +241,8 @@ const (
 	IFLA_GRE_ENCAP_SPORT
 	IFLA_GRE_ENCAP_DPORT
 	IFLA_GRE_COLLECT_METADATA
-	IFLA_GRE_MAX = IFLA_GRE_COLLECT_METADATA
+	IFLA_GRE_IGNORE_DF
+	IFLA_GRE_MAX = IFLA_GRE_IGNORE_DF
 )
 
 const (
@@ -226,7 +277,13 @@ const (
 	IFLA_VF_TRUST        /* Trust state of VF */
 	IFLA_VF_IB_NODE_GUID /* VF Infiniband node GUID */
 	IFLA_VF_IB_PORT_GUID /* VF Infiniband port GUID */
-	IFLA_VF_MAX          = IFLA_VF_IB_PORT_GUID
+	IFLA_VF_VLAN_LIST    /* nested list of vlans, option for QinQ */
+	IFLA_VF_MAX          = IFLA_VF_VLAN_LIST
+)
+
+const (
+	IFLA_VF_VLAN_INFO_UNSPEC = iota
+	IFLA_VF_VLAN_INFO        /* VLAN ID, QoS and VLAN protocol */
 )
 
 const (
@@ -249,6 +306,7 @@ const (
 const (
 	SizeofVfMac        = 0x24
 	SizeofVfVlan       = 0x0c
+	SizeofVfVlanInfo   = 0x10
 	SizeofVfTxRate     = 0x08
 	SizeofVfRate       = 0x0c
 	SizeofVfSpoofchk   = 0x08
@@ -304,6 +362,36 @@ func (msg *VfVlan) Serialize() []byte {
 	return (*(*[SizeofVfVlan]byte)(unsafe.Pointer(msg)))[:]
 }
 
+// struct ifla_vf_vlan_info {
+//   __u32 vf;
+//   __u32 vlan; /* 0 - 4095, 0 disables VLAN filter */
+//   __u32 qos;
+//   __be16 vlan_proto; /* VLAN protocol either 802.1Q or 802.1ad */
+// };
+
+type VfVlanInfo struct {
+	VfVlan
+	VlanProto uint16 // host byte order, serialized as big endian
+}
+
+func (msg *VfVlanInfo) Len() int {
+	return SizeofVfVlanInfo
+}
+
+func DeserializeVfVlanInfo(b []byte) *VfVlanInfo {
+	return &VfVlanInfo{
+		VfVlan:    *DeserializeVfVlan(b),
+		VlanProto: binary.BigEndian.Uint16(b[SizeofVfVlan : SizeofVfVlan+2]),
+	}
+}
+
+func (msg *VfVlanInfo) Serialize() []byte {
+	b := make([]byte, SizeofVfVlanInfo)
+	copy(b, msg.VfVlan.Serialize())
+	binary.BigEndian.PutUint16(b[SizeofVfVlan:], msg.VlanProto)
+	return b
+}
+
 // struct ifla_vf_tx_rate {
 //   __u32 vf;
 //   __u32 rate; /* Max TX bandwidth in Mbps, 0 disables throttling */
@@ -466,7 +554,9 @@ const (
 	XDP_FLAGS_UPDATE_IF_NOEXIST = 1 << iota
 	XDP_FLAGS_SKB_MODE
 	XDP_FLAGS_DRV_MODE
-	XDP_FLAGS_MASK = XDP_FLAGS_UPDATE_IF_NOEXIST | XDP_FLAGS_SKB_MODE | XDP_FLAGS_DRV_MODE
+	XDP_FLAGS_HW_MODE
+	XDP_FLAGS_MODES = XDP_FLAGS_SKB_MODE | XDP_FLAGS_DRV_MODE | XDP_FLAGS_HW_MODE
+	XDP_FLAGS_MASK  = XDP_FLAGS_UPDATE_IF_NOEXIST | XDP_FLAGS_MODES
 )
 
 const (
@@ -579,6 +669,15 @@ const (
 	GTP_ROLE_SGSN
 )
 
+const (
+	IFLA_BAREUDP_UNSPEC = iota
+	IFLA_BAREUDP_PORT
+	IFLA_BAREUDP_ETHERTYPE
+	IFLA_BAREUDP_SRCPORT_MIN
+	IFLA_BAREUDP_MULTIPROTO_MODE
+	IFLA_BAREUDP_MAX = IFLA_BAREUDP_MULTIPROTO_MODE
+)
+
 const (
 	IFLA_XFRM_UNSPEC = iota
 	IFLA_XFRM_LINK
@@ -608,3 +707,181 @@ const (
 	IFLA_IPOIB_UMCAST
 	IFLA_IPOIB_MAX = IFLA_IPOIB_UMCAST
 )
+
+const (
+	IFLA_STATS_UNSPEC = iota
+	IFLA_STATS_LINK_64
+	IFLA_STATS_LINK_XSTATS
+	IFLA_STATS_LINK_XSTATS_SLAVE
+	IFLA_STATS_LINK_OFFLOAD_XSTATS
+	IFLA_STATS_AF_SPEC
+	IFLA_STATS_MAX = IFLA_STATS_AF_SPEC
+)
+
+// IflaStatsFilterBit returns the RTM_GETSTATS filter mask bit that selects
+// the IFLA_STATS_* attribute attr.
+func IflaStatsFilterBit(attr int) uint32 {
+	return 1 << uint(attr-1)
+}
+
+const (
+	LINK_XSTATS_TYPE_UNSPEC = iota
+	LINK_XSTATS_TYPE_BRIDGE
+	LINK_XSTATS_TYPE_BOND
+	LINK_XSTATS_TYPE_MAX = LINK_XSTATS_TYPE_BOND
+)
+
+const (
+	BRIDGE_XSTATS_UNSPEC = iota
+	BRIDGE_XSTATS_VLAN
+	BRIDGE_XSTATS_MCAST
+	BRIDGE_XSTATS_PAD
+	BRIDGE_XSTATS_STP
+	BRIDGE_XSTATS_MAX = BRIDGE_XSTATS_STP
+)
+
+const (
+	BR_MCAST_DIR_RX = iota
+	BR_MCAST_DIR_TX
+	BR_MCAST_DIR_SIZE
+)
+
+const (
+	BOND_XSTATS_UNSPEC = iota
+	BOND_XSTATS_3AD
+	BOND_XSTATS_PAD
+	BOND_XSTATS_MAX = BOND_XSTATS_PAD
+)
+
+const (
+	BOND_3AD_STAT_LACPDU_RX = iota
+	BOND_3AD_STAT_LACPDU_TX
+	BOND_3AD_STAT_LACPDU_UNKNOWN_RX
+	BOND_3AD_STAT_LACPDU_ILLEGAL_RX
+	BOND_3AD_STAT_MARKER_RX
+	BOND_3AD_STAT_MARKER_TX
+	BOND_3AD_STAT_MARKER_RESP_RX
+	BOND_3AD_STAT_MARKER_RESP_TX
+	BOND_3AD_STAT_MARKER_UNKNOWN_RX
+	BOND_3AD_STAT_PAD
+	BOND_3AD_STAT_MAX = BOND_3AD_STAT_PAD
+)
+
+const SizeofIfStatsMsg = 0x0c
+
+// struct if_stats_msg {
+//   __u8  family;
+//   __u8  pad1;
+//   __u16 pad2;
+//   __u32 ifindex;
+//   __u32 filter_mask;
+// };
+
+type IfStatsMsg struct {
+	Family     uint8
+	Pad1       uint8
+	Pad2       uint16
+	Ifindex    uint32
+	FilterMask uint32
+}
+
+func (msg *IfStatsMsg) Len() int {
+	return SizeofIfStatsMsg
+}
+
+func DeserializeIfStatsMsg(b []byte) *IfStatsMsg {
+	return (*IfStatsMsg)(unsafe.Pointer(&b[0:SizeofIfStatsMsg][0]))
+}
+
+func (msg *IfStatsMsg) Serialize() []byte {
+	return (*(*[SizeofIfStatsMsg]byte)(unsafe.Pointer(msg)))[:]
+}
+
+const (
+	IFLA_INET_UNSPEC = iota
+	IFLA_INET_CONF
+	IFLA_INET_MAX = IFLA_INET_CONF
+)
+
+const (
+	IFLA_INET6_UNSPEC = iota
+	IFLA_INET6_FLAGS
+	IFLA_INET6_CONF
+	IFLA_INET6_STATS
+	IFLA_INET6_MCAST
+	IFLA_INET6_CACHEINFO
+	IFLA_INET6_ICMP6STATS
+	IFLA_INET6_TOKEN
+	IFLA_INET6_ADDR_GEN_MODE
+	IFLA_INET6_MAX = IFLA_INET6_ADDR_GEN_MODE
+)
+
+// The IPv4 devconf entries are 1 based, entry n is found at index n-1 of
+// the IFLA_INET_CONF array.
+const (
+	IPV4_DEVCONF_FORWARDING = iota + 1
+	IPV4_DEVCONF_MC_FORWARDING
+	IPV4_DEVCONF_PROXY_ARP
+	IPV4_DEVCONF_ACCEPT_REDIRECTS
+	IPV4_DEVCONF_SECURE_REDIRECTS
+	IPV4_DEVCONF_SEND_REDIRECTS
+	IPV4_DEVCONF_SHARED_MEDIA
+	IPV4_DEVCONF_RP_FILTER
+	IPV4_DEVCONF_ACCEPT_SOURCE_ROUTE
+	IPV4_DEVCONF_BOOTP_RELAY
+	IPV4_DEVCONF_LOG_MARTIANS
+	IPV4_DEVCONF_TAG
+	IPV4_DEVCONF_ARPFILTER
+	IPV4_DEVCONF_MEDIUM_ID
+	IPV4_DEVCONF_NOXFRM
+	IPV4_DEVCONF_NOPOLICY
+	IPV4_DEVCONF_FORCE_IGMP_VERSION
+	IPV4_DEVCONF_ARP_ANNOUNCE
+	IPV4_DEVCONF_ARP_IGNORE
+	IPV4_DEVCONF_PROMOTE_SECONDARIES
+	IPV4_DEVCONF_ARP_ACCEPT
+	IPV4_DEVCONF_ARP_NOTIFY
+	IPV4_DEVCONF_ACCEPT_LOCAL
+	IPV4_DEVCONF_SRC_VMARK
+	IPV4_DEVCONF_PROXY_ARP_PVLAN
+	IPV4_DEVCONF_ROUTE_LOCALNET
+	IPV4_DEVCONF_IGMPV2_UNSOLICITED_REPORT_INTERVAL
+	IPV4_DEVCONF_IGMPV3_UNSOLICITED_REPORT_INTERVAL
+	IPV4_DEVCONF_IGNORE_ROUTES_WITH_LINKDOWN
+	IPV4_DEVCONF_DROP_UNICAST_IN_L2_MULTICAST
+	IPV4_DEVCONF_DROP_GRATUITOUS_ARP
+	IPV4_DEVCONF_BC_FORWARDING
+)
+
+// The IPv6 devconf entries are 0 based, entry n is found at index n of the
+// IFLA_INET6_CONF array.
+const (
+	DEVCONF_FORWARDING = iota
+	DEVCONF_HOPLIMIT
+	DEVCONF_MTU6
+	DEVCONF_ACCEPT_RA
+	DEVCONF_ACCEPT_REDIRECTS
+	DEVCONF_AUTOCONF
+	DEVCONF_DAD_TRANSMITS
+	DEVCONF_RTR_SOLICITS
+	DEVCONF_RTR_SOLICIT_INTERVAL
+	DEVCONF_RTR_SOLICIT_DELAY
+	DEVCONF_USE_TEMPADDR
+	DEVCONF_TEMP_VALID_LFT
+	DEVCONF_TEMP_PREFERED_LFT
+	DEVCONF_REGEN_MAX_RETRY
+	DEVCONF_MAX_DESYNC_FACTOR
+	DEVCONF_MAX_ADDRESSES
+	DEVCONF_FORCE_MLD_VERSION
+	DEVCONF_ACCEPT_RA_DEFRTR
+	DEVCONF_ACCEPT_RA_PINFO
+	DEVCONF_ACCEPT_RA_RTR_PREF
+	DEVCONF_RTR_PROBE_INTERVAL
+	DEVCONF_ACCEPT_RA_RT_INFO_MAX_PLEN
+	DEVCONF_PROXY_NDP
+	DEVCONF_OPTIMISTIC_DAD
+	DEVCONF_ACCEPT_SOURCE_ROUTE
+	DEVCONF_MC_FORWARDING
+	DEVCONF_DISABLE_IPV6
+	DEVCONF_ACCEPT_DAD
+)
@@ -62,6 +62,23 @@ func TestVfVlanDeserializeSerialize(t *testing.T) {
 	testDeserializeSerialize(t, orig, safemsg, msg)
 }
 
+func TestVfVlanInfoDeserializeSerialize(t *testing.T) {
+	msg := VfVlanInfo{
+		VfVlan:    VfVlan{Vf: 1, Vlan: 100, Qos: 3},
+		VlanProto: 0x88a8,
+	}
+	b := msg.Serialize()
+	if len(b) != SizeofVfVlanInfo {
+		t.Fatalf("Serialized length %d, expected %d", len(b), SizeofVfVlanInfo)
+	}
+	if b[SizeofVfVlan] != 0x88 || b[SizeofVfVlan+1] != 0xa8 {
+		t.Fatalf("VlanProto not in network byte order: %x", b[SizeofVfVlan:])
+	}
+	if got := DeserializeVfVlanInfo(b); *got != msg {
+		t.Fatalf("Deserialized %+v, expected %+v", *got, msg)
+	}
+}
+
 func (msg *VfTxRate) write(b []byte) {
 	native := NativeEndian()
 	native.PutUint32(b[0:4], uint32(msg.Vf))
@@ -197,3 +214,33 @@ func TestVfRssQueryEnDeserializeSerialize(t *testing.T) {
 	msg := DeserializeVfRssQueryEn(orig)
 	testDeserializeSerialize(t, orig, safemsg, msg)
 }
+
+func (msg *IfStatsMsg) write(b []byte) {
+	native := NativeEndian()
+	b[0] = msg.Family
+	b[1] = msg.Pad1
+	native.PutUint16(b[2:4], msg.Pad2)
+	native.PutUint32(b[4:8], msg.Ifindex)
+	native.PutUint32(b[8:12], msg.FilterMask)
+}
+
+func (msg *IfStatsMsg) serializeSafe() []byte {
+	length := SizeofIfStatsMsg
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeIfStatsMsgSafe(b []byte) *IfStatsMsg {
+	var msg = IfStatsMsg{}
+	binary.Read(bytes.NewReader(b[0:SizeofIfStatsMsg]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestIfStatsMsgDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofIfStatsMsg)
+	rand.Read(orig)
+	safemsg := deserializeIfStatsMsgSafe(orig)
+	msg := DeserializeIfStatsMsg(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
@@ -3,6 +3,7 @@ package nl
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"net"
@@ -26,6 +27,10 @@ const (
 	// from kernel more verbose messages e.g. for statistics,
 	// tc rules or filters, or other more memory requiring data.
 	RECEIVE_BUFFER_SIZE = 65536
+	// BatchWindow is the number of requests ExecuteBatch sends in a single
+	// sendmsg call. Each request queues an acknowledgement on the socket
+	// before any of them are read, so this bounds the receive buffer use.
+	BatchWindow = 64
 	// Kernel netlink pid
 	PidKernel uint32 = 0
 )
@@ -342,6 +347,31 @@ type NetlinkRequest struct {
 	Data    []NetlinkRequestData
 	RawData []byte
 	Sockets map[int]*SocketHandle
+	// Hook, if set, is called with the request when it is sent and with
+	// every received message whose sequence number matches the request.
+	Hook MessageHook
+	// StrictCheck enables strict checking on the socket opened for the
+	// request, so the kernel applies the filters of a dump itself. Shared
+	// sockets keep their own setting. Kernels before 4.20 ignore it.
+	StrictCheck bool
+}
+
+// MessageHook is called with the type and the serialized bytes, including
+// the netlink header, of a netlink message. Outgoing messages have the
+// NLM_F_REQUEST flag set in the header.
+type MessageHook func(msgType int, data []byte)
+
+// callHook calls hook with the serialized message m.
+func callHook(hook MessageHook, m *syscall.NetlinkMessage) {
+	b := make([]byte, unix.SizeofNlMsghdr+len(m.Data))
+	native := NativeEndian()
+	native.PutUint32(b[0:4], m.Header.Len)
+	native.PutUint16(b[4:6], m.Header.Type)
+	native.PutUint16(b[6:8], m.Header.Flags)
+	native.PutUint32(b[8:12], m.Header.Seq)
+	native.PutUint32(b[12:16], m.Header.Pid)
+	copy(b[unix.SizeofNlMsghdr:], m.Data)
+	hook(int(m.Header.Type), b)
 }
 
 // Serialize the Netlink Request into a byte array
@@ -385,6 +415,12 @@ func (req *NetlinkRequest) AddRawData(data []byte) {
 // Returns a list of netlink messages in serialized format, optionally filtered
 // by resType.
 func (req *NetlinkRequest) Execute(sockType int, resType uint16) ([][]byte, error) {
+	return req.ExecuteContext(context.Background(), sockType, resType)
+}
+
+// ExecuteContext works like Execute, but stops waiting for the response and
+// returns ctx.Err() once ctx is done.
+func (req *NetlinkRequest) ExecuteContext(ctx context.Context, sockType int, resType uint16) ([][]byte, error) {
 	var (
 		s   *NetlinkSocket
 		err error
@@ -404,11 +440,18 @@ func (req *NetlinkRequest) Execute(sockType int, resType uint16) ([][]byte, erro
 			return nil, err
 		}
 		defer s.Close()
+		if req.StrictCheck {
+			// older kernels fail with ENOPROTOOPT and dump everything
+			unix.SetsockoptInt(int(s.fd), unix.SOL_NETLINK, unix.NETLINK_GET_STRICT_CHK, 1)
+		}
 	} else {
 		s.Lock()
 		defer s.Unlock()
 	}
 
+	if req.Hook != nil {
+		req.Hook(int(req.Type), req.Serialize())
+	}
 	if err := s.Send(req); err != nil {
 		return nil, err
 	}
@@ -418,10 +461,21 @@ func (req *NetlinkRequest) Execute(sockType int, resType uint16) ([][]byte, erro
 		return nil, err
 	}
 
+	var wake *contextWaker
+	if ctx.Done() != nil {
+		if wake, err = newContextWaker(ctx); err != nil {
+			return nil, err
+		}
+		defer wake.Close()
+	}
+
 	var res [][]byte
 
 done:
 	for {
+		if err := s.waitContext(ctx, wake); err != nil {
+			return nil, err
+		}
 		msgs, from, err := s.Receive()
 		if err != nil {
 			return nil, err
@@ -436,6 +490,9 @@ done:
 				}
 				return nil, fmt.Errorf("Wrong Seq nr %d, expected %d", m.Header.Seq, req.Seq)
 			}
+			if req.Hook != nil {
+				callHook(req.Hook, &m)
+			}
 			if m.Header.Pid != pid {
 				continue
 			}
@@ -462,6 +519,106 @@ done:
 	return res, nil
 }
 
+// ExecuteBatch sends reqs on a single socket of the given sockType, packing
+// up to BatchWindow requests in each sendmsg call, and waits for the
+// acknowledgement of every request. The requests are not atomic, each one
+// is applied or rejected by the kernel on its own. The returned slice holds
+// the result of each request in the order of reqs. The error is only set
+// if the batch could not be sent or the acknowledgements not be read, in
+// which case the state of the remaining requests is unknown.
+func ExecuteBatch(sockType int, reqs []*NetlinkRequest) ([]error, error) {
+	res := make([]error, len(reqs))
+	if len(reqs) == 0 {
+		return res, nil
+	}
+
+	var (
+		s   *NetlinkSocket
+		sh  *SocketHandle
+		err error
+	)
+	if reqs[0].Sockets != nil {
+		sh = reqs[0].Sockets[sockType]
+	}
+	if sh != nil {
+		s = sh.Socket
+		s.Lock()
+		defer s.Unlock()
+	} else {
+		s, err = getNetlinkSocket(sockType)
+		if err != nil {
+			return nil, err
+		}
+		defer s.Close()
+	}
+
+	pid, err := s.GetPid()
+	if err != nil {
+		return nil, err
+	}
+
+	native := NativeEndian()
+	for start := 0; start < len(reqs); start += BatchWindow {
+		end := start + BatchWindow
+		if end > len(reqs) {
+			end = len(reqs)
+		}
+		pending := make(map[uint32]int, end-start)
+		var buf []byte
+		for i := start; i < end; i++ {
+			req := reqs[i]
+			if sh != nil {
+				req.Seq = atomic.AddUint32(&sh.Seq, 1)
+			}
+			req.Flags |= unix.NLM_F_ACK
+			pending[req.Seq] = i
+			msg := req.Serialize()
+			if req.Hook != nil {
+				req.Hook(int(req.Type), msg)
+			}
+			buf = append(buf, msg...)
+			// messages in a batch start on a 4 byte boundary
+			for len(buf)%unix.NLMSG_ALIGNTO != 0 {
+				buf = append(buf, 0)
+			}
+		}
+		fd := s.GetFd()
+		if fd < 0 {
+			return nil, fmt.Errorf("Send called on a closed socket")
+		}
+		if err := unix.Sendto(fd, buf, 0, &s.lsa); err != nil {
+			return nil, err
+		}
+
+		for len(pending) > 0 {
+			msgs, from, err := s.Receive()
+			if err != nil {
+				return nil, err
+			}
+			if from.Pid != PidKernel {
+				return nil, fmt.Errorf("Wrong sender portid %d, expected %d", from.Pid, PidKernel)
+			}
+			for _, m := range msgs {
+				i, ok := pending[m.Header.Seq]
+				if !ok {
+					continue
+				}
+				if hook := reqs[i].Hook; hook != nil {
+					callHook(hook, &m)
+				}
+				if m.Header.Pid != pid || m.Header.Type != unix.NLMSG_ERROR {
+					continue
+				}
+				if errno := int32(native.Uint32(m.Data[0:4])); errno != 0 {
+					res[i] = syscall.Errno(-errno)
+				}
+				delete(pending, m.Header.Seq)
+			}
+		}
+	}
+	return res, nil
+}
+
 // Create a new netlink request from proto and flags
 // Note the Len value will be inaccurate once data is added until
 // the message is serialized
@@ -656,6 +813,68 @@ func (s *NetlinkSocket) SetSendTimeout(timeout *unix.Timeval) error {
 	return unix.SetsockoptTimeval(int(s.fd), unix.SOL_SOCKET, unix.SO_SNDTIMEO, timeout)
 }
 
+// contextWaker makes a pipe readable once its context is done, so a
+// request can poll it next to its socket instead of waking up periodically.
+type contextWaker struct {
+	fds  [2]int
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newContextWaker(ctx context.Context) (*contextWaker, error) {
+	w := &contextWaker{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := unix.Pipe2(w.fds[:], unix.O_CLOEXEC|unix.O_NONBLOCK); err != nil {
+		return nil, err
+	}
+	go func() {
+		defer close(w.done)
+		select {
+		case <-ctx.Done():
+			unix.Write(w.fds[1], []byte{0})
+		case <-w.stop:
+		}
+	}()
+	return w, nil
+}
+
+// Close stops watching the context and releases the pipe.
+func (w *contextWaker) Close() {
+	close(w.stop)
+	<-w.done
+	unix.Close(w.fds[0])
+	unix.Close(w.fds[1])
+}
+
+// waitContext blocks until the socket is readable or the context watched by
+// wake is done. A nil wake never interrupts the wait.
+func (s *NetlinkSocket) waitContext(ctx context.Context, wake *contextWaker) error {
+	if wake == nil {
+		return nil
+	}
+	fds := []unix.PollFd{
+		{Fd: atomic.LoadInt32(&s.fd), Events: unix.POLLIN},
+		{Fd: int32(wake.fds[0]), Events: unix.POLLIN},
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		_, err := unix.Poll(fds, -1)
+		if err == unix.EINTR {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if fds[0].Revents != 0 {
+			return nil
+		}
+	}
+}
+
 // SetReceiveTimeout allows to set a receive timeout on the socket
 func (s *NetlinkSocket) SetReceiveTimeout(timeout *unix.Timeval) error {
 	// Set a read timeout of SOCKET_READ_TIMEOUT, this will allow the Read to periodically unblock and avoid that a routine
@@ -2,6 +2,7 @@ package nl
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/binary"
 	"reflect"
@@ -98,3 +99,19 @@ func TestIfSocketCloses(t *testing.T) {
 		t.Fatalf("Expected error instead received nil")
 	}
 }
+
+func TestExecuteContextCancel(t *testing.T) {
+	// the kernel doesn't answer a NOOP without NLM_F_ACK, so only the
+	// context can end the wait
+	req := NewNetlinkRequest(unix.NLMSG_NOOP, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := req.ExecuteContext(ctx, unix.NETLINK_ROUTE, 0); err != context.DeadlineExceeded {
+		t.Fatalf("Expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Request returned %s after its context was done", elapsed)
+	}
+}
@@ -105,3 +105,92 @@ func (msg *RtGenMsg) Serialize() []byte {
 	out[0] = msg.Family
 	return out
 }
+
+// Nexthop objects, see include/uapi/linux/nexthop.h
+const (
+	RTM_NEWNEXTHOP = 0x68
+	RTM_DELNEXTHOP = 0x69
+	RTM_GETNEXTHOP = 0x6a
+
+	RTA_NH_ID = 0x1e
+
+	RTNLGRP_NEXTHOP = 0x20
+)
+
+const (
+	NHA_UNSPEC = iota
+	NHA_ID
+	NHA_GROUP
+	NHA_GROUP_TYPE
+	NHA_BLACKHOLE
+	NHA_OIF
+	NHA_GATEWAY
+	NHA_ENCAP_TYPE
+	NHA_ENCAP
+	NHA_GROUPS
+	NHA_MASTER
+	NHA_MAX = NHA_MASTER
+)
+
+const (
+	NEXTHOP_GRP_TYPE_MPATH = 0
+)
+
+const (
+	SizeofNhMsg      = 0x08
+	SizeofNexthopGrp = 0x08
+)
+
+// struct nhmsg {
+//   unsigned char nh_family;
+//   unsigned char nh_scope;
+//   unsigned char nh_protocol;
+//   unsigned char resvd;
+//   unsigned int  nh_flags;
+// };
+
+type NhMsg struct {
+	Family   uint8
+	Scope    uint8
+	Protocol uint8
+	Resvd    uint8
+	Flags    uint32
+}
+
+func (msg *NhMsg) Len() int {
+	return SizeofNhMsg
+}
+
+func DeserializeNhMsg(b []byte) *NhMsg {
+	return (*NhMsg)(unsafe.Pointer(&b[0:SizeofNhMsg][0]))
+}
+
+func (msg *NhMsg) Serialize() []byte {
+	return (*(*[SizeofNhMsg]byte)(unsafe.Pointer(msg)))[:]
+}
+
+// struct nexthop_grp {
+//   __u32 id;
+//   __u8  weight;
+//   __u8  resvd1;
+//   __u16 resvd2;
+// };
+
+type NexthopGrp struct {
+	Id     uint32
+	Weight uint8
+	Resvd1 uint8
+	Resvd2 uint16
+}
+
+func (msg *NexthopGrp) Len() int {
+	return SizeofNexthopGrp
+}
+
+func DeserializeNexthopGrp(b []byte) *NexthopGrp {
+	return (*NexthopGrp)(unsafe.Pointer(&b[0:SizeofNexthopGrp][0]))
+}
+
+func (msg *NexthopGrp) Serialize() []byte {
+	return (*(*[SizeofNexthopGrp]byte)(unsafe.Pointer(msg)))[:]
+}
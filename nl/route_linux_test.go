@@ -42,3 +42,32 @@ func TestRtMsgDeserializeSerialize(t *testing.T) {
 	msg := DeserializeRtMsg(orig)
 	testDeserializeSerialize(t, orig, safemsg, msg)
 }
+
+func (msg *NhMsg) write(b []byte) {
+	native := NativeEndian()
+	b[0] = msg.Family
+	b[1] = msg.Scope
+	b[2] = msg.Protocol
+	b[3] = msg.Resvd
+	native.PutUint32(b[4:8], msg.Flags)
+}
+
+func (msg *NhMsg) serializeSafe() []byte {
+	b := make([]byte, SizeofNhMsg)
+	msg.write(b)
+	return b
+}
+
+func deserializeNhMsgSafe(b []byte) *NhMsg {
+	var msg = NhMsg{}
+	binary.Read(bytes.NewReader(b[0:SizeofNhMsg]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestNhMsgDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofNhMsg)
+	rand.Read(orig)
+	safemsg := deserializeNhMsgSafe(orig)
+	msg := DeserializeNhMsg(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
@@ -70,12 +70,18 @@ func EncodeSEG6Encap(mode int, segments []net.IP) ([]byte, error) {
 	// srh.reserved: Defined as "Tag" in draft-ietf-6man-segment-routing-header-07
 	native.PutUint16(b[10:], 0) // srh.reserved
 	for _, netIP := range segments {
-		b = append(b, netIP...) // srh.Segments
+		if netIP.To16() == nil || netIP.To4() != nil {
+			return nil, fmt.Errorf("EncodeSEG6Encap: segment %s is not an IPv6 address", netIP)
+		}
+		b = append(b, netIP.To16()...) // srh.Segments
 	}
 	return b, nil
 }
 
 func DecodeSEG6Encap(buf []byte) (int, []net.IP, error) {
+	if len(buf) < 12 {
+		return 0, nil, fmt.Errorf("DecodeSEG6Encap: short buffer (len: %d)", len(buf))
+	}
 	native := NativeEndian()
 	mode := int(native.Uint32(buf))
 	srh := IPv6SrHdr{
@@ -21,6 +21,8 @@ const (
 	FRA_TABLE  /* Extended table id */
 	FRA_FWMASK /* mask for netfilter mark */
 	FRA_OIFNAME
+	FRA_PAD
+	FRA_L3MDEV /* iif or oif is l3mdev goto its table */
 )
 
 // ip rule netlink request types
@@ -60,6 +62,30 @@ const (
 	LWTUNNEL_ENCAP_SEG6_LOCAL
 )
 
+// LwtEncapTypeString returns the name iproute2 uses for a light weight
+// tunnel encap type.
+func LwtEncapTypeString(typ int) string {
+	switch typ {
+	case LWTUNNEL_ENCAP_NONE:
+		return "none"
+	case LWTUNNEL_ENCAP_MPLS:
+		return "mpls"
+	case LWTUNNEL_ENCAP_IP:
+		return "ip"
+	case LWTUNNEL_ENCAP_ILA:
+		return "ila"
+	case LWTUNNEL_ENCAP_IP6:
+		return "ip6"
+	case LWTUNNEL_ENCAP_SEG6:
+		return "seg6"
+	case LWTUNNEL_ENCAP_BPF:
+		return "bpf"
+	case LWTUNNEL_ENCAP_SEG6_LOCAL:
+		return "seg6local"
+	}
+	return "unknown"
+}
+
 // routing header types
 const (
 	IPV6_SRCRT_STRICT = 0x01 // Deprecated; will be removed
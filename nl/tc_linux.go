@@ -20,6 +20,46 @@ const (
 
 const TC_LINKLAYER_MASK = 0x0F
 
+const (
+	TCA_STAB_UNSPEC = iota
+	TCA_STAB_BASE
+	TCA_STAB_DATA
+)
+
+// struct tc_sizespec {
+//   unsigned char cell_log;
+//   unsigned char size_log;
+//   short         cell_align;
+//   int           overhead;
+//   unsigned int  linklayer;
+//   unsigned int  mpu;
+//   unsigned int  mtu;
+//   unsigned int  tsize;
+// };
+
+type TcSizeSpec struct {
+	CellLog   uint8
+	SizeLog   uint8
+	CellAlign int16
+	Overhead  int32
+	Linklayer uint32
+	Mpu       uint32
+	Mtu       uint32
+	Tsize     uint32
+}
+
+func (msg *TcSizeSpec) Len() int {
+	return SizeofTcSizeSpec
+}
+
+func DeserializeTcSizeSpec(b []byte) *TcSizeSpec {
+	return (*TcSizeSpec)(unsafe.Pointer(&b[0:SizeofTcSizeSpec][0]))
+}
+
+func (x *TcSizeSpec) Serialize() []byte {
+	return (*(*[SizeofTcSizeSpec]byte)(unsafe.Pointer(x)))[:]
+}
+
 // Police
 const (
 	TCA_POLICE_UNSPEC = iota
@@ -45,6 +85,15 @@ const (
 	TCA_MAX = TCA_STAB
 )
 
+// Message types missing above, TCA_HW_OFFLOAD is set by the kernel when
+// the qdisc is offloaded to hardware.
+const (
+	TCA_PAD = iota + TCA_STAB + 1
+	TCA_DUMP_INVISIBLE
+	TCA_CHAIN
+	TCA_HW_OFFLOAD
+)
+
 const (
 	TCA_ACT_TAB = 1
 	TCAA_MAX    = 1
@@ -78,6 +127,17 @@ const (
 	SizeofTcMsg          = 0x14
 	SizeofTcActionMsg    = 0x04
 	SizeofTcPrioMap      = 0x14
+	SizeofTcFifoQopt     = 0x04
+	SizeofTcEtfQopt      = 0x0c
+	SizeofTcMqprioQopt   = 0x52
+	SizeofTcMultiqQopt   = 0x04
+	SizeofTcCbsQopt      = 0x14
+	SizeofTcPlugQopt     = 0x08
+	SizeofTcSfbQopt      = 0x24
+	SizeofTcSfbXstats    = 0x24
+	SizeofTcSizeSpec     = 0x18
+	SizeofTcCodelXstats  = 0x24
+	SizeofTcHhfXstats    = 0x10
 	SizeofTcRateSpec     = 0x0c
 	SizeofTcNetemQopt    = 0x18
 	SizeofTcNetemCorr    = 0x0c
@@ -93,7 +153,13 @@ const (
 	SizeofTcMirred       = SizeofTcGen + 0x08
 	SizeofTcTunnelKey    = SizeofTcGen + 0x04
 	SizeofTcSkbEdit      = SizeofTcGen
+	SizeofTcGactP        = 0x08
+	SizeofTcVlan         = SizeofTcGen + 0x04
 	SizeofTcPolice       = 2*SizeofTcRateSpec + 0x20
+	SizeofTcfEmatchTree  = 0x04
+	SizeofTcfEmatchHdr   = 0x08
+	SizeofTcfEmCmp       = 0x0c
+	SizeofTcfMetaHdr     = 0x08
 )
 
 // struct tcmsg {
@@ -176,6 +242,26 @@ func (x *TcPrioMap) Serialize() []byte {
 	return (*(*[SizeofTcPrioMap]byte)(unsafe.Pointer(x)))[:]
 }
 
+// struct tc_fifo_qopt {
+//   __u32 limit; /* Queue length: bytes for bfifo, packets for pfifo */
+// };
+
+type TcFifoQopt struct {
+	Limit uint32
+}
+
+func (msg *TcFifoQopt) Len() int {
+	return SizeofTcFifoQopt
+}
+
+func DeserializeTcFifoQopt(b []byte) *TcFifoQopt {
+	return (*TcFifoQopt)(unsafe.Pointer(&b[0:SizeofTcFifoQopt][0]))
+}
+
+func (x *TcFifoQopt) Serialize() []byte {
+	return (*(*[SizeofTcFifoQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
 const (
 	TCA_TBF_UNSPEC = iota
 	TCA_TBF_PARMS
@@ -488,7 +574,19 @@ const (
 	TCA_U32_INDEV
 	TCA_U32_PCNT
 	TCA_U32_MARK
-	TCA_U32_MAX = TCA_U32_MARK
+	TCA_U32_FLAGS
+	TCA_U32_PAD
+	TCA_U32_MAX = TCA_U32_PAD
+)
+
+// Generic classifier flags, carried in the classifier specific flags
+// attribute (e.g. TCA_U32_FLAGS).
+const (
+	TCA_CLS_FLAGS_SKIP_HW   = 1 << iota // don't offload filter to HW
+	TCA_CLS_FLAGS_SKIP_SW               // don't use filter in SW
+	TCA_CLS_FLAGS_IN_HW                 // filter is offloaded to HW
+	TCA_CLS_FLAGS_NOT_IN_HW             // filter isn't offloaded to HW
+	TCA_CLS_FLAGS_VERBOSE               // verbose logging
 )
 
 // struct tc_u32_key {
@@ -619,6 +717,30 @@ const (
 	TCA_GACT_MAX = TCA_GACT_PROB
 )
 
+// struct tc_gact_p {
+//   __u16                 ptype;
+//   __u16                 pval;
+//   int                   paction;
+// };
+
+type TcGactP struct {
+	Ptype   uint16
+	Pval    uint16
+	Paction int32
+}
+
+func (x *TcGactP) Len() int {
+	return SizeofTcGactP
+}
+
+func DeserializeTcGactP(b []byte) *TcGactP {
+	return (*TcGactP)(unsafe.Pointer(&b[0:SizeofTcGactP][0]))
+}
+
+func (x *TcGactP) Serialize() []byte {
+	return (*(*[SizeofTcGactP]byte)(unsafe.Pointer(x)))[:]
+}
+
 type TcGact TcGen
 
 const (
@@ -735,7 +857,13 @@ const (
 	TCA_TUNNEL_KEY_ENC_IPV6_SRC
 	TCA_TUNNEL_KEY_ENC_IPV6_DST
 	TCA_TUNNEL_KEY_ENC_KEY_ID
-	TCA_TUNNEL_KEY_MAX = TCA_TUNNEL_KEY_ENC_KEY_ID
+	TCA_TUNNEL_KEY_PAD
+	TCA_TUNNEL_KEY_ENC_DST_PORT
+	TCA_TUNNEL_KEY_NO_CSUM
+	TCA_TUNNEL_KEY_ENC_OPTS
+	TCA_TUNNEL_KEY_ENC_TOS
+	TCA_TUNNEL_KEY_ENC_TTL
+	TCA_TUNNEL_KEY_MAX = TCA_TUNNEL_KEY_ENC_TTL
 )
 
 type TcTunnelKey struct {
@@ -764,7 +892,8 @@ const (
 	TCA_SKBEDIT_MARK
 	TCA_SKBEDIT_PAD
 	TCA_SKBEDIT_PTYPE
-	TCA_SKBEDIT_MAX = TCA_SKBEDIT_MARK
+	TCA_SKBEDIT_MASK
+	TCA_SKBEDIT_MAX = TCA_SKBEDIT_MASK
 )
 
 type TcSkbEdit struct {
@@ -783,6 +912,50 @@ func (x *TcSkbEdit) Serialize() []byte {
 	return (*(*[SizeofTcSkbEdit]byte)(unsafe.Pointer(x)))[:]
 }
 
+const (
+	TCA_VLAN_UNSPEC = iota
+	TCA_VLAN_TM
+	TCA_VLAN_PARMS
+	TCA_VLAN_PUSH_VLAN_ID
+	TCA_VLAN_PUSH_VLAN_PROTOCOL
+	TCA_VLAN_PAD
+	TCA_VLAN_PUSH_VLAN_PRIORITY
+	TCA_VLAN_MAX = TCA_VLAN_PUSH_VLAN_PRIORITY
+)
+
+// struct tc_vlan {
+//   tc_gen;
+//   int v_action;
+// };
+
+type TcVlan struct {
+	TcGen
+	VAction int32
+}
+
+func (x *TcVlan) Len() int {
+	return SizeofTcVlan
+}
+
+func DeserializeTcVlan(b []byte) *TcVlan {
+	return (*TcVlan)(unsafe.Pointer(&b[0:SizeofTcVlan][0]))
+}
+
+func (x *TcVlan) Serialize() []byte {
+	return (*(*[SizeofTcVlan]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TCA_SAMPLE_UNSPEC = iota
+	TCA_SAMPLE_TM
+	TCA_SAMPLE_PARMS
+	TCA_SAMPLE_RATE
+	TCA_SAMPLE_TRUNC_SIZE
+	TCA_SAMPLE_PSAMPLE_GROUP
+	TCA_SAMPLE_PAD
+	TCA_SAMPLE_MAX = TCA_SAMPLE_PAD
+)
+
 // struct tc_police {
 // 	__u32			index;
 // 	int			action;
@@ -838,6 +1011,282 @@ const (
 	TCA_MATCHALL_FLAGS
 )
 
+const (
+	TCA_BASIC_UNSPEC = iota
+	TCA_BASIC_CLASSID
+	TCA_BASIC_EMATCHES
+	TCA_BASIC_ACT
+	TCA_BASIC_POLICE
+)
+
+const (
+	TCA_EMATCH_TREE_UNSPEC = iota
+	TCA_EMATCH_TREE_HDR
+	TCA_EMATCH_TREE_LIST
+)
+
+// Ematch kinds
+const (
+	TCF_EM_CONTAINER = iota
+	TCF_EM_CMP
+	TCF_EM_NBYTE
+	TCF_EM_U32
+	TCF_EM_META
+	TCF_EM_TEXT
+	TCF_EM_VLAN
+	TCF_EM_CANID
+	TCF_EM_IPSET
+	TCF_EM_IPT
+)
+
+// Ematch header flags
+const (
+	TCF_EM_REL_END  = 0
+	TCF_EM_REL_AND  = 1 << 0
+	TCF_EM_REL_OR   = 1 << 1
+	TCF_EM_INVERT   = 1 << 2
+	TCF_EM_SIMPLE   = 1 << 3
+	TCF_EM_REL_MASK = TCF_EM_REL_AND | TCF_EM_REL_OR
+)
+
+const (
+	TCF_EM_PROG_TC = 2
+)
+
+const (
+	TCF_LAYER_LINK = iota
+	TCF_LAYER_NETWORK
+	TCF_LAYER_TRANSPORT
+)
+
+const (
+	TCF_EM_OPND_EQ = iota
+	TCF_EM_OPND_GT
+	TCF_EM_OPND_LT
+)
+
+const (
+	TCF_EM_ALIGN_U8  = 1
+	TCF_EM_ALIGN_U16 = 2
+	TCF_EM_ALIGN_U32 = 4
+)
+
+const (
+	TCF_EM_CMP_TRANS = 1
+)
+
+// struct tcf_ematch_tree_hdr {
+//   __u16 nmatches;
+//   __u16 progid;
+// };
+
+type TcfEmatchTree struct {
+	Nmatches uint16
+	Progid   uint16
+}
+
+func (x *TcfEmatchTree) Len() int {
+	return SizeofTcfEmatchTree
+}
+
+func DeserializeTcfEmatchTree(b []byte) *TcfEmatchTree {
+	return (*TcfEmatchTree)(unsafe.Pointer(&b[0:SizeofTcfEmatchTree][0]))
+}
+
+func (x *TcfEmatchTree) Serialize() []byte {
+	return (*(*[SizeofTcfEmatchTree]byte)(unsafe.Pointer(x)))[:]
+}
+
+// struct tcf_ematch_hdr {
+//   __u16 matchid;
+//   __u16 kind;
+//   __u16 flags;
+//   __u16 pad; /* currently unused */
+// };
+
+type TcfEmatchHdr struct {
+	Matchid uint16
+	Kind    uint16
+	Flags   uint16
+	Pad     uint16
+}
+
+func (x *TcfEmatchHdr) Len() int {
+	return SizeofTcfEmatchHdr
+}
+
+func DeserializeTcfEmatchHdr(b []byte) *TcfEmatchHdr {
+	return (*TcfEmatchHdr)(unsafe.Pointer(&b[0:SizeofTcfEmatchHdr][0]))
+}
+
+func (x *TcfEmatchHdr) Serialize() []byte {
+	return (*(*[SizeofTcfEmatchHdr]byte)(unsafe.Pointer(x)))[:]
+}
+
+// struct tcf_em_cmp {
+//   __u32 val;
+//   __u32 mask;
+//   __u16 off;
+//   __u8  align:4;
+//   __u8  flags:4;
+//   __u8  layer:4;
+//   __u8  opnd:4;
+// };
+
+type TcfEmCmp struct {
+	Val   uint32
+	Mask  uint32
+	Off   uint16
+	Align uint8
+	Flags uint8
+	Layer uint8
+	Opnd  uint8
+}
+
+func (x *TcfEmCmp) Len() int {
+	return SizeofTcfEmCmp
+}
+
+// The bit fields are allocated from the least significant bit on little
+// endian machines and from the most significant bit on big endian ones.
+func DeserializeTcfEmCmp(b []byte) *TcfEmCmp {
+	native := NativeEndian()
+	x := &TcfEmCmp{
+		Val:  native.Uint32(b[0:4]),
+		Mask: native.Uint32(b[4:8]),
+		Off:  native.Uint16(b[8:10]),
+	}
+	if native == binary.LittleEndian {
+		x.Align, x.Flags = b[10]&0x0f, b[10]>>4
+		x.Layer, x.Opnd = b[11]&0x0f, b[11]>>4
+	} else {
+		x.Align, x.Flags = b[10]>>4, b[10]&0x0f
+		x.Layer, x.Opnd = b[11]>>4, b[11]&0x0f
+	}
+	return x
+}
+
+func (x *TcfEmCmp) Serialize() []byte {
+	native := NativeEndian()
+	b := make([]byte, SizeofTcfEmCmp)
+	native.PutUint32(b[0:4], x.Val)
+	native.PutUint32(b[4:8], x.Mask)
+	native.PutUint16(b[8:10], x.Off)
+	if native == binary.LittleEndian {
+		b[10] = x.Align&0x0f | x.Flags<<4
+		b[11] = x.Layer&0x0f | x.Opnd<<4
+	} else {
+		b[10] = x.Align<<4 | x.Flags&0x0f
+		b[11] = x.Layer<<4 | x.Opnd&0x0f
+	}
+	return b
+}
+
+const (
+	TCA_EM_META_UNSPEC = iota
+	TCA_EM_META_HDR
+	TCA_EM_META_LVALUE
+	TCA_EM_META_RVALUE
+)
+
+// The kind of a meta value holds its type in the top 4 bits and its id in
+// the low 11 bits.
+const (
+	TCF_META_TYPE_VAR = iota
+	TCF_META_TYPE_INT
+)
+
+const (
+	TCF_META_TYPE_SHIFT = 12
+	TCF_META_TYPE_MASK  = 0xf << TCF_META_TYPE_SHIFT
+	TCF_META_ID_MASK    = 0x7ff
+)
+
+const (
+	TCF_META_ID_VALUE = iota
+	TCF_META_ID_RANDOM
+	TCF_META_ID_LOADAVG_0
+	TCF_META_ID_LOADAVG_1
+	TCF_META_ID_LOADAVG_2
+	TCF_META_ID_DEV
+	TCF_META_ID_PRIORITY
+	TCF_META_ID_PROTOCOL
+	TCF_META_ID_PKTTYPE
+	TCF_META_ID_PKTLEN
+	TCF_META_ID_DATALEN
+	TCF_META_ID_MACLEN
+	TCF_META_ID_NFMARK
+	TCF_META_ID_TCINDEX
+	TCF_META_ID_RTCLASSID
+	TCF_META_ID_RTIIF
+	TCF_META_ID_SK_FAMILY
+	TCF_META_ID_SK_STATE
+	TCF_META_ID_SK_REUSE
+	TCF_META_ID_SK_BOUND_IF
+	TCF_META_ID_SK_REFCNT
+	TCF_META_ID_SK_SHUTDOWN
+	TCF_META_ID_SK_PROTO
+	TCF_META_ID_SK_TYPE
+	TCF_META_ID_SK_RCVBUF
+	TCF_META_ID_SK_RMEM_ALLOC
+	TCF_META_ID_SK_WMEM_ALLOC
+	TCF_META_ID_SK_OMEM_ALLOC
+	TCF_META_ID_SK_WMEM_QUEUED
+	TCF_META_ID_SK_RCV_QLEN
+	TCF_META_ID_SK_SND_QLEN
+	TCF_META_ID_SK_ERR_QLEN
+	TCF_META_ID_SK_FORWARD_ALLOCS
+	TCF_META_ID_SK_SNDBUF
+	TCF_META_ID_SK_ALLOCS
+	TCF_META_ID_SK_ROUTE_CAPS // unused
+	TCF_META_ID_SK_HASH
+	TCF_META_ID_SK_LINGERTIME
+	TCF_META_ID_SK_ACK_BACKLOG
+	TCF_META_ID_SK_MAX_ACK_BACKLOG
+	TCF_META_ID_SK_PRIO
+	TCF_META_ID_SK_RCVLOWAT
+	TCF_META_ID_SK_RCVTIMEO
+	TCF_META_ID_SK_SNDTIMEO
+	TCF_META_ID_SK_SENDMSG_OFF
+	TCF_META_ID_SK_WRITE_PENDING
+	TCF_META_ID_VLAN_TAG
+	TCF_META_ID_RXHASH
+)
+
+// struct tcf_meta_val {
+//   __u16 kind;
+//   __u8  shift;
+//   __u8  op;
+// };
+//
+// struct tcf_meta_hdr {
+//   struct tcf_meta_val left;
+//   struct tcf_meta_val right;
+// };
+
+type TcfMetaVal struct {
+	Kind  uint16
+	Shift uint8
+	Op    uint8
+}
+
+type TcfMetaHdr struct {
+	Left  TcfMetaVal
+	Right TcfMetaVal
+}
+
+func (x *TcfMetaHdr) Len() int {
+	return SizeofTcfMetaHdr
+}
+
+func DeserializeTcfMetaHdr(b []byte) *TcfMetaHdr {
+	return (*TcfMetaHdr)(unsafe.Pointer(&b[0:SizeofTcfMetaHdr][0]))
+}
+
+func (x *TcfMetaHdr) Serialize() []byte {
+	return (*(*[SizeofTcfMetaHdr]byte)(unsafe.Pointer(x)))[:]
+}
+
 const (
 	TCA_FQ_UNSPEC             = iota
 	TCA_FQ_PLIMIT             // limit of total number of packets in queue
@@ -866,6 +1315,404 @@ const (
 	TCA_FQ_CODEL_MEMORY_LIMIT
 )
 
+const (
+	TCA_CODEL_UNSPEC = iota
+	TCA_CODEL_TARGET
+	TCA_CODEL_LIMIT
+	TCA_CODEL_INTERVAL
+	TCA_CODEL_ECN
+	TCA_CODEL_CE_THRESHOLD
+)
+
+const (
+	TCA_PIE_UNSPEC = iota
+	TCA_PIE_TARGET
+	TCA_PIE_LIMIT
+	TCA_PIE_TUPDATE
+	TCA_PIE_ALPHA
+	TCA_PIE_BETA
+	TCA_PIE_ECN
+	TCA_PIE_BYTEMODE
+	TCA_PIE_DQ_RATE_ESTIMATOR
+)
+
+const (
+	TCA_FQ_PIE_UNSPEC = iota
+	TCA_FQ_PIE_LIMIT
+	TCA_FQ_PIE_FLOWS
+	TCA_FQ_PIE_TARGET
+	TCA_FQ_PIE_TUPDATE
+	TCA_FQ_PIE_ALPHA
+	TCA_FQ_PIE_BETA
+	TCA_FQ_PIE_QUANTUM
+	TCA_FQ_PIE_MEMORY_LIMIT
+	TCA_FQ_PIE_ECN_PROB
+	TCA_FQ_PIE_ECN
+	TCA_FQ_PIE_BYTEMODE
+	TCA_FQ_PIE_DQ_RATE_ESTIMATOR
+)
+
+// struct tc_codel_xstats {
+//   __u32 maxpacket; /* largest packet we've seen so far */
+//   __u32 count;     /* how many drops we've done since the last time we
+//                     * entered dropping state
+//                     */
+//   __u32 lastcount; /* count at entry to dropping state */
+//   __u32 ldelay;    /* in-queue delay seen by most recently dequeued packet */
+//   __s32 drop_next; /* time to drop next packet */
+//   __u32 drop_overlimit; /* number of time max qdisc packet limit was hit */
+//   __u32 ecn_mark;  /* number of packets we ECN marked instead of dropped */
+//   __u32 dropping;  /* are we in dropping state ? */
+//   __u32 ce_mark;   /* number of CE marked packets because of ce_threshold */
+// };
+
+type TcCodelXstats struct {
+	Maxpacket     uint32
+	Count         uint32
+	Lastcount     uint32
+	Ldelay        uint32
+	DropNext      int32
+	DropOverlimit uint32
+	EcnMark       uint32
+	Dropping      uint32
+	CeMark        uint32
+}
+
+func (msg *TcCodelXstats) Len() int {
+	return SizeofTcCodelXstats
+}
+
+// DeserializeTcCodelXstats decodes the codel xstats, older kernels send
+// them without the trailing fields which are then left at zero.
+func DeserializeTcCodelXstats(b []byte) *TcCodelXstats {
+	if len(b) < SizeofTcCodelXstats {
+		buf := make([]byte, SizeofTcCodelXstats)
+		copy(buf, b)
+		b = buf
+	}
+	return (*TcCodelXstats)(unsafe.Pointer(&b[0:SizeofTcCodelXstats][0]))
+}
+
+func (x *TcCodelXstats) Serialize() []byte {
+	return (*(*[SizeofTcCodelXstats]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TCA_HHF_UNSPEC = iota
+	TCA_HHF_BACKLOG_LIMIT
+	TCA_HHF_QUANTUM
+	TCA_HHF_HH_FLOWS_LIMIT
+	TCA_HHF_RESET_TIMEOUT
+	TCA_HHF_ADMIT_BYTES
+	TCA_HHF_EVICT_TIMEOUT
+	TCA_HHF_NON_HH_WEIGHT
+)
+
+// struct tc_hhf_xstats {
+//   __u32 drop_overlimit; /* number of times max qdisc packet limit was hit */
+//   __u32 hh_overlimit;   /* number of times max heavy-hitters was hit */
+//   __u32 hh_tot_count;   /* number of captured heavy-hitters so far */
+//   __u32 hh_cur_count;   /* number of current heavy-hitters */
+// };
+
+type TcHhfXstats struct {
+	DropOverlimit uint32
+	HhOverlimit   uint32
+	HhTotCount    uint32
+	HhCurCount    uint32
+}
+
+func (msg *TcHhfXstats) Len() int {
+	return SizeofTcHhfXstats
+}
+
+func DeserializeTcHhfXstats(b []byte) *TcHhfXstats {
+	return (*TcHhfXstats)(unsafe.Pointer(&b[0:SizeofTcHhfXstats][0]))
+}
+
+func (x *TcHhfXstats) Serialize() []byte {
+	return (*(*[SizeofTcHhfXstats]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TCA_CAKE_UNSPEC = iota
+	TCA_CAKE_PAD
+	TCA_CAKE_BASE_RATE64
+	TCA_CAKE_DIFFSERV_MODE
+	TCA_CAKE_ATM
+	TCA_CAKE_FLOW_MODE
+	TCA_CAKE_OVERHEAD
+	TCA_CAKE_RTT
+	TCA_CAKE_TARGET
+	TCA_CAKE_AUTORATE
+	TCA_CAKE_MEMORY
+	TCA_CAKE_NAT
+	TCA_CAKE_RAW
+	TCA_CAKE_WASH
+	TCA_CAKE_MPU
+	TCA_CAKE_INGRESS
+	TCA_CAKE_ACK_FILTER
+	TCA_CAKE_SPLIT_GSO
+	TCA_CAKE_FWMARK
+)
+
+const (
+	TCA_ETF_UNSPEC = iota
+	TCA_ETF_PARMS
+)
+
+const (
+	TC_ETF_DEADLINE_MODE_ON = 1 << 0
+	TC_ETF_OFFLOAD_ON       = 1 << 1
+	TC_ETF_SKIP_SOCK_CHECK  = 1 << 2
+)
+
+// struct tc_etf_qopt {
+//   __s32 delta;
+//   __s32 clockid;
+//   __u32 flags;
+// };
+
+type TcEtfQopt struct {
+	Delta   int32
+	Clockid int32
+	Flags   uint32
+}
+
+func (msg *TcEtfQopt) Len() int {
+	return SizeofTcEtfQopt
+}
+
+func DeserializeTcEtfQopt(b []byte) *TcEtfQopt {
+	return (*TcEtfQopt)(unsafe.Pointer(&b[0:SizeofTcEtfQopt][0]))
+}
+
+func (x *TcEtfQopt) Serialize() []byte {
+	return (*(*[SizeofTcEtfQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TC_QOPT_BITMASK   = 15
+	TC_QOPT_MAX_QUEUE = 16
+)
+
+// struct tc_mqprio_qopt {
+//   __u8 num_tc;
+//   __u8 prio_tc_map[TC_QOPT_BITMASK + 1];
+//   __u8 hw;
+//   __u16 count[TC_QOPT_MAX_QUEUE];
+//   __u16 offset[TC_QOPT_MAX_QUEUE];
+// };
+
+type TcMqprioQopt struct {
+	NumTc     uint8
+	PrioTcMap [TC_QOPT_BITMASK + 1]uint8
+	Hw        uint8
+	Count     [TC_QOPT_MAX_QUEUE]uint16
+	Offset    [TC_QOPT_MAX_QUEUE]uint16
+}
+
+func (msg *TcMqprioQopt) Len() int {
+	return SizeofTcMqprioQopt
+}
+
+func DeserializeTcMqprioQopt(b []byte) *TcMqprioQopt {
+	return (*TcMqprioQopt)(unsafe.Pointer(&b[0:SizeofTcMqprioQopt][0]))
+}
+
+func (x *TcMqprioQopt) Serialize() []byte {
+	return (*(*[SizeofTcMqprioQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TC_MQPRIO_HW_OFFLOAD_NONE = 0
+	TC_MQPRIO_HW_OFFLOAD_TCS  = 1
+)
+
+// struct tc_multiq_qopt {
+//   __u16 bands;     /* Number of bands */
+//   __u16 max_bands; /* Maximum number of queues */
+// };
+
+type TcMultiqQopt struct {
+	Bands    uint16
+	MaxBands uint16
+}
+
+func (msg *TcMultiqQopt) Len() int {
+	return SizeofTcMultiqQopt
+}
+
+func DeserializeTcMultiqQopt(b []byte) *TcMultiqQopt {
+	return (*TcMultiqQopt)(unsafe.Pointer(&b[0:SizeofTcMultiqQopt][0]))
+}
+
+func (x *TcMultiqQopt) Serialize() []byte {
+	return (*(*[SizeofTcMultiqQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TCA_CBS_UNSPEC = iota
+	TCA_CBS_PARMS
+)
+
+// struct tc_cbs_qopt {
+//   __u8 offload;
+//   __u8 _pad[3];
+//   __s32 hicredit;
+//   __s32 locredit;
+//   __s32 idleslope;
+//   __s32 sendslope;
+// };
+
+type TcCbsQopt struct {
+	Offload   uint8
+	Pad       [3]uint8
+	Hicredit  int32
+	Locredit  int32
+	Idleslope int32
+	Sendslope int32
+}
+
+func (msg *TcCbsQopt) Len() int {
+	return SizeofTcCbsQopt
+}
+
+func DeserializeTcCbsQopt(b []byte) *TcCbsQopt {
+	return (*TcCbsQopt)(unsafe.Pointer(&b[0:SizeofTcCbsQopt][0]))
+}
+
+func (x *TcCbsQopt) Serialize() []byte {
+	return (*(*[SizeofTcCbsQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
+// struct tc_plug_qopt {
+//   int action;
+//   __u32 limit;
+// };
+
+type TcPlugQopt struct {
+	Action int32
+	Limit  uint32
+}
+
+func (msg *TcPlugQopt) Len() int {
+	return SizeofTcPlugQopt
+}
+
+func DeserializeTcPlugQopt(b []byte) *TcPlugQopt {
+	return (*TcPlugQopt)(unsafe.Pointer(&b[0:SizeofTcPlugQopt][0]))
+}
+
+func (x *TcPlugQopt) Serialize() []byte {
+	return (*(*[SizeofTcPlugQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TCA_SFB_UNSPEC = iota
+	TCA_SFB_PARMS
+)
+
+// struct tc_sfb_qopt {
+//   __u32 rehash_interval; /* delay between hash move, in ms */
+//   __u32 warmup_time;     /* double buffering warmup time in ms (warmup_time < rehash_interval) */
+//   __u32 max;             /* max len of qlen_min */
+//   __u32 bin_size;        /* maximum queue length per bin */
+//   __u32 increment;       /* probability increment, (d1 in Blue) */
+//   __u32 decrement;       /* probability decrement, (d2 in Blue) */
+//   __u32 limit;           /* max SFB queue length */
+//   __u32 penalty_rate;    /* inelastic flows are rate limited to 'rate' pps */
+//   __u32 penalty_burst;
+// };
+
+type TcSfbQopt struct {
+	RehashInterval uint32
+	WarmupTime     uint32
+	Max            uint32
+	BinSize        uint32
+	Increment      uint32
+	Decrement      uint32
+	Limit          uint32
+	PenaltyRate    uint32
+	PenaltyBurst   uint32
+}
+
+func (msg *TcSfbQopt) Len() int {
+	return SizeofTcSfbQopt
+}
+
+func DeserializeTcSfbQopt(b []byte) *TcSfbQopt {
+	return (*TcSfbQopt)(unsafe.Pointer(&b[0:SizeofTcSfbQopt][0]))
+}
+
+func (x *TcSfbQopt) Serialize() []byte {
+	return (*(*[SizeofTcSfbQopt]byte)(unsafe.Pointer(x)))[:]
+}
+
+// struct tc_sfb_xstats {
+//   __u32 earlydrop;
+//   __u32 penaltydrop;
+//   __u32 bucketdrop;
+//   __u32 queuedrop;
+//   __u32 childdrop; /* drops in child qdisc */
+//   __u32 marked;
+//   __u32 maxqlen;
+//   __u32 maxprob;
+//   __u32 avgprob;
+// };
+
+type TcSfbXstats struct {
+	Earlydrop   uint32
+	Penaltydrop uint32
+	Bucketdrop  uint32
+	Queuedrop   uint32
+	Childdrop   uint32
+	Marked      uint32
+	Maxqlen     uint32
+	Maxprob     uint32
+	Avgprob     uint32
+}
+
+func (msg *TcSfbXstats) Len() int {
+	return SizeofTcSfbXstats
+}
+
+func DeserializeTcSfbXstats(b []byte) *TcSfbXstats {
+	return (*TcSfbXstats)(unsafe.Pointer(&b[0:SizeofTcSfbXstats][0]))
+}
+
+func (x *TcSfbXstats) Serialize() []byte {
+	return (*(*[SizeofTcSfbXstats]byte)(unsafe.Pointer(x)))[:]
+}
+
+const (
+	TCA_TAPRIO_ATTR_UNSPEC = iota
+	TCA_TAPRIO_ATTR_PRIOMAP
+	TCA_TAPRIO_ATTR_SCHED_ENTRY_LIST
+	TCA_TAPRIO_ATTR_SCHED_BASE_TIME
+	TCA_TAPRIO_ATTR_SCHED_SINGLE_ENTRY
+	TCA_TAPRIO_ATTR_SCHED_CLOCKID
+	TCA_TAPRIO_PAD
+	TCA_TAPRIO_ATTR_ADMIN_SCHED
+	TCA_TAPRIO_ATTR_SCHED_CYCLE_TIME
+	TCA_TAPRIO_ATTR_SCHED_CYCLE_TIME_EXTENSION
+	TCA_TAPRIO_ATTR_FLAGS
+	TCA_TAPRIO_ATTR_TXTIME_DELAY
+)
+
+const (
+	TCA_TAPRIO_SCHED_UNSPEC = iota
+	TCA_TAPRIO_SCHED_ENTRY
+)
+
+const (
+	TCA_TAPRIO_SCHED_ENTRY_UNSPEC = iota
+	TCA_TAPRIO_SCHED_ENTRY_INDEX
+	TCA_TAPRIO_SCHED_ENTRY_CMD
+	TCA_TAPRIO_SCHED_ENTRY_GATE_MASK
+	TCA_TAPRIO_SCHED_ENTRY_INTERVAL
+)
+
 const (
 	TCA_HFSC_UNSPEC = iota
 	TCA_HFSC_RSC
@@ -171,3 +171,407 @@ func TestTcHtbCoptDeserializeSerialize(t *testing.T) {
 	msg := DeserializeTcHtbCopt(orig)
 	testDeserializeSerialize(t, orig, safemsg, msg)
 }
+
+func (msg *TcGactP) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint16(b[0:2], msg.Ptype)
+	native.PutUint16(b[2:4], msg.Pval)
+	native.PutUint32(b[4:8], uint32(msg.Paction))
+}
+
+func (msg *TcGactP) serializeSafe() []byte {
+	length := msg.Len()
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcGactPSafe(b []byte) *TcGactP {
+	var msg = TcGactP{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcGactP]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcGactPDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcGactP)
+	rand.Read(orig)
+	safemsg := deserializeTcGactPSafe(orig)
+	msg := DeserializeTcGactP(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+func (msg *TcVlan) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint32(b[0:4], msg.Index)
+	native.PutUint32(b[4:8], msg.Capab)
+	native.PutUint32(b[8:12], uint32(msg.Action))
+	native.PutUint32(b[12:16], uint32(msg.Refcnt))
+	native.PutUint32(b[16:20], uint32(msg.Bindcnt))
+	native.PutUint32(b[20:24], uint32(msg.VAction))
+}
+
+func (msg *TcVlan) serializeSafe() []byte {
+	length := msg.Len()
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcVlanSafe(b []byte) *TcVlan {
+	var msg = TcVlan{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcVlan]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcVlanDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcVlan)
+	rand.Read(orig)
+	safemsg := deserializeTcVlanSafe(orig)
+	msg := DeserializeTcVlan(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+func (msg *TcCodelXstats) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint32(b[0:4], msg.Maxpacket)
+	native.PutUint32(b[4:8], msg.Count)
+	native.PutUint32(b[8:12], msg.Lastcount)
+	native.PutUint32(b[12:16], msg.Ldelay)
+	native.PutUint32(b[16:20], uint32(msg.DropNext))
+	native.PutUint32(b[20:24], msg.DropOverlimit)
+	native.PutUint32(b[24:28], msg.EcnMark)
+	native.PutUint32(b[28:32], msg.Dropping)
+	native.PutUint32(b[32:36], msg.CeMark)
+}
+
+func (msg *TcCodelXstats) serializeSafe() []byte {
+	length := msg.Len()
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcCodelXstatsSafe(b []byte) *TcCodelXstats {
+	var msg = TcCodelXstats{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcCodelXstats]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcCodelXstatsDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcCodelXstats)
+	rand.Read(orig)
+	safemsg := deserializeTcCodelXstatsSafe(orig)
+	msg := DeserializeTcCodelXstats(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+
+	// kernels before 4.13 don't send ce_mark
+	msg = DeserializeTcCodelXstats(orig[:SizeofTcCodelXstats-4])
+	if msg.CeMark != 0 || msg.Dropping != safemsg.Dropping {
+		t.Fatal("Deserialization of short xstats failed.\n", safemsg, "\n", msg)
+	}
+}
+
+func (msg *TcHhfXstats) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint32(b[0:4], msg.DropOverlimit)
+	native.PutUint32(b[4:8], msg.HhOverlimit)
+	native.PutUint32(b[8:12], msg.HhTotCount)
+	native.PutUint32(b[12:16], msg.HhCurCount)
+}
+
+func (msg *TcHhfXstats) serializeSafe() []byte {
+	length := msg.Len()
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcHhfXstatsSafe(b []byte) *TcHhfXstats {
+	var msg = TcHhfXstats{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcHhfXstats]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcHhfXstatsDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcHhfXstats)
+	rand.Read(orig)
+	safemsg := deserializeTcHhfXstatsSafe(orig)
+	msg := DeserializeTcHhfXstats(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+func TestTcfEmCmpDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcfEmCmp)
+	rand.Read(orig)
+	msg := DeserializeTcfEmCmp(orig)
+	if !bytes.Equal(orig, msg.Serialize()) {
+		t.Fatal("Serialization failed.\n", orig, "\n", msg.Serialize())
+	}
+
+	// iproute2 on a little endian machine: cmp(u16 at 2 layer transport eq 443)
+	cmp := TcfEmCmp{Val: 443, Mask: 0xffff, Off: 2, Align: TCF_EM_ALIGN_U16, Flags: TCF_EM_CMP_TRANS, Layer: TCF_LAYER_TRANSPORT}
+	b := cmp.Serialize()
+	if NativeEndian() == binary.LittleEndian && (b[10] != 0x12 || b[11] != 0x02) {
+		t.Fatalf("Unexpected bit field layout %x", b[10:])
+	}
+	if got := DeserializeTcfEmCmp(b); *got != cmp {
+		t.Fatalf("Deserialized %+v, expected %+v", *got, cmp)
+	}
+}
+
+/* TcSizeSpec */
+func (msg *TcSizeSpec) write(b []byte) {
+	native := NativeEndian()
+	b[0] = msg.CellLog
+	b[1] = msg.SizeLog
+	native.PutUint16(b[2:4], uint16(msg.CellAlign))
+	native.PutUint32(b[4:8], uint32(msg.Overhead))
+	native.PutUint32(b[8:12], msg.Linklayer)
+	native.PutUint32(b[12:16], msg.Mpu)
+	native.PutUint32(b[16:20], msg.Mtu)
+	native.PutUint32(b[20:24], msg.Tsize)
+}
+
+func (msg *TcSizeSpec) serializeSafe() []byte {
+	length := SizeofTcSizeSpec
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcSizeSpecSafe(b []byte) *TcSizeSpec {
+	var msg = TcSizeSpec{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcSizeSpec]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcSizeSpecDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcSizeSpec)
+	rand.Read(orig)
+	safemsg := deserializeTcSizeSpecSafe(orig)
+	msg := DeserializeTcSizeSpec(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+/* TcfMetaHdr */
+func (msg *TcfMetaHdr) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint16(b[0:2], msg.Left.Kind)
+	b[2] = msg.Left.Shift
+	b[3] = msg.Left.Op
+	native.PutUint16(b[4:6], msg.Right.Kind)
+	b[6] = msg.Right.Shift
+	b[7] = msg.Right.Op
+}
+
+func (msg *TcfMetaHdr) serializeSafe() []byte {
+	length := SizeofTcfMetaHdr
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcfMetaHdrSafe(b []byte) *TcfMetaHdr {
+	var msg = TcfMetaHdr{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcfMetaHdr]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcfMetaHdrDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcfMetaHdr)
+	rand.Read(orig)
+	safemsg := deserializeTcfMetaHdrSafe(orig)
+	msg := DeserializeTcfMetaHdr(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+/* TcMqprioQopt */
+func (msg *TcMqprioQopt) write(b []byte) {
+	native := NativeEndian()
+	b[0] = msg.NumTc
+	copy(b[1:17], msg.PrioTcMap[:])
+	b[17] = msg.Hw
+	for i := 0; i < TC_QOPT_MAX_QUEUE; i++ {
+		native.PutUint16(b[18+2*i:20+2*i], msg.Count[i])
+		native.PutUint16(b[50+2*i:52+2*i], msg.Offset[i])
+	}
+}
+
+func (msg *TcMqprioQopt) serializeSafe() []byte {
+	length := SizeofTcMqprioQopt
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcMqprioQoptSafe(b []byte) *TcMqprioQopt {
+	var msg = TcMqprioQopt{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcMqprioQopt]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcMqprioQoptDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcMqprioQopt)
+	rand.Read(orig)
+	safemsg := deserializeTcMqprioQoptSafe(orig)
+	msg := DeserializeTcMqprioQopt(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+/* TcMultiqQopt */
+func (msg *TcMultiqQopt) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint16(b[0:2], msg.Bands)
+	native.PutUint16(b[2:4], msg.MaxBands)
+}
+
+func (msg *TcMultiqQopt) serializeSafe() []byte {
+	length := SizeofTcMultiqQopt
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcMultiqQoptSafe(b []byte) *TcMultiqQopt {
+	var msg = TcMultiqQopt{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcMultiqQopt]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcMultiqQoptDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcMultiqQopt)
+	rand.Read(orig)
+	safemsg := deserializeTcMultiqQoptSafe(orig)
+	msg := DeserializeTcMultiqQopt(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+/* TcCbsQopt */
+func (msg *TcCbsQopt) write(b []byte) {
+	native := NativeEndian()
+	b[0] = msg.Offload
+	copy(b[1:4], msg.Pad[:])
+	native.PutUint32(b[4:8], uint32(msg.Hicredit))
+	native.PutUint32(b[8:12], uint32(msg.Locredit))
+	native.PutUint32(b[12:16], uint32(msg.Idleslope))
+	native.PutUint32(b[16:20], uint32(msg.Sendslope))
+}
+
+func (msg *TcCbsQopt) serializeSafe() []byte {
+	length := SizeofTcCbsQopt
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcCbsQoptSafe(b []byte) *TcCbsQopt {
+	var msg = TcCbsQopt{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcCbsQopt]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcCbsQoptDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcCbsQopt)
+	rand.Read(orig)
+	safemsg := deserializeTcCbsQoptSafe(orig)
+	msg := DeserializeTcCbsQopt(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+/* TcPlugQopt */
+func (msg *TcPlugQopt) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint32(b[0:4], uint32(msg.Action))
+	native.PutUint32(b[4:8], msg.Limit)
+}
+
+func (msg *TcPlugQopt) serializeSafe() []byte {
+	length := SizeofTcPlugQopt
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcPlugQoptSafe(b []byte) *TcPlugQopt {
+	var msg = TcPlugQopt{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcPlugQopt]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcPlugQoptDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcPlugQopt)
+	rand.Read(orig)
+	safemsg := deserializeTcPlugQoptSafe(orig)
+	msg := DeserializeTcPlugQopt(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+/* TcSfbQopt */
+func (msg *TcSfbQopt) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint32(b[0:4], msg.RehashInterval)
+	native.PutUint32(b[4:8], msg.WarmupTime)
+	native.PutUint32(b[8:12], msg.Max)
+	native.PutUint32(b[12:16], msg.BinSize)
+	native.PutUint32(b[16:20], msg.Increment)
+	native.PutUint32(b[20:24], msg.Decrement)
+	native.PutUint32(b[24:28], msg.Limit)
+	native.PutUint32(b[28:32], msg.PenaltyRate)
+	native.PutUint32(b[32:36], msg.PenaltyBurst)
+}
+
+func (msg *TcSfbQopt) serializeSafe() []byte {
+	length := SizeofTcSfbQopt
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcSfbQoptSafe(b []byte) *TcSfbQopt {
+	var msg = TcSfbQopt{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcSfbQopt]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcSfbQoptDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcSfbQopt)
+	rand.Read(orig)
+	safemsg := deserializeTcSfbQoptSafe(orig)
+	msg := DeserializeTcSfbQopt(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
+
+/* TcSfbXstats */
+func (msg *TcSfbXstats) write(b []byte) {
+	native := NativeEndian()
+	native.PutUint32(b[0:4], msg.Earlydrop)
+	native.PutUint32(b[4:8], msg.Penaltydrop)
+	native.PutUint32(b[8:12], msg.Bucketdrop)
+	native.PutUint32(b[12:16], msg.Queuedrop)
+	native.PutUint32(b[16:20], msg.Childdrop)
+	native.PutUint32(b[20:24], msg.Marked)
+	native.PutUint32(b[24:28], msg.Maxqlen)
+	native.PutUint32(b[28:32], msg.Maxprob)
+	native.PutUint32(b[32:36], msg.Avgprob)
+}
+
+func (msg *TcSfbXstats) serializeSafe() []byte {
+	length := SizeofTcSfbXstats
+	b := make([]byte, length)
+	msg.write(b)
+	return b
+}
+
+func deserializeTcSfbXstatsSafe(b []byte) *TcSfbXstats {
+	var msg = TcSfbXstats{}
+	binary.Read(bytes.NewReader(b[0:SizeofTcSfbXstats]), NativeEndian(), &msg)
+	return &msg
+}
+
+func TestTcSfbXstatsDeserializeSerialize(t *testing.T) {
+	var orig = make([]byte, SizeofTcSfbXstats)
+	rand.Read(orig)
+	safemsg := deserializeTcSfbXstatsSafe(orig)
+	msg := DeserializeTcSfbXstats(orig)
+	testDeserializeSerialize(t, orig, safemsg, msg)
+}
@@ -14,8 +14,22 @@ type Protinfo struct {
 	Flood        bool
 	ProxyArp     bool
 	ProxyArpWiFi bool
+	McastFlood   bool
+	McastToUcast bool
+	// NeighSuppress suppresses ARP and ND for addresses known to the bridge
+	NeighSuppress bool
+	// MulticastRouter is one of the MDB_RTR_TYPE_* constants
+	MulticastRouter uint8
 }
 
+// Multicast router modes of a bridge port
+const (
+	MDB_RTR_TYPE_DISABLED   = 0 // never a multicast router port
+	MDB_RTR_TYPE_TEMP_QUERY = 1 // router port when a querier is seen (default)
+	MDB_RTR_TYPE_PERM       = 2 // always a multicast router port
+	MDB_RTR_TYPE_TEMP       = 3 // router port until the router timer expires
+)
+
 // String returns a list of enabled flags
 func (prot *Protinfo) String() string {
 	if prot == nil {
@@ -47,6 +61,15 @@ func (prot *Protinfo) String() string {
 	if prot.ProxyArpWiFi {
 		boolStrings = append(boolStrings, "ProxyArpWiFi")
 	}
+	if prot.McastFlood {
+		boolStrings = append(boolStrings, "McastFlood")
+	}
+	if prot.McastToUcast {
+		boolStrings = append(boolStrings, "McastToUcast")
+	}
+	if prot.NeighSuppress {
+		boolStrings = append(boolStrings, "NeighSuppress")
+	}
 	return strings.Join(boolStrings, " ")
 }
 
@@ -68,6 +68,14 @@ func parseProtinfo(infos []syscall.NetlinkRouteAttr) (pi Protinfo) {
 			pi.ProxyArp = byteToBool(info.Value[0])
 		case nl.IFLA_BRPORT_PROXYARP_WIFI:
 			pi.ProxyArpWiFi = byteToBool(info.Value[0])
+		case nl.IFLA_BRPORT_MCAST_FLOOD:
+			pi.McastFlood = byteToBool(info.Value[0])
+		case nl.IFLA_BRPORT_MCAST_TO_UCAST:
+			pi.McastToUcast = byteToBool(info.Value[0])
+		case nl.IFLA_BRPORT_NEIGH_SUPPRESS:
+			pi.NeighSuppress = byteToBool(info.Value[0])
+		case nl.IFLA_BRPORT_MULTICAST_ROUTER:
+			pi.MulticastRouter = uint8(info.Value[0])
 		}
 	}
 	return
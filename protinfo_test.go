@@ -159,3 +159,108 @@ func TestProtinfo(t *testing.T) {
 		t.Fatalf("Flood field was changed for %s but shouldn't", iface4.Name)
 	}
 }
+
+func TestProtinfoMulticast(t *testing.T) {
+	minKernelRequired(t, 4, 11)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	master := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(master); err != nil {
+		t.Fatal(err)
+	}
+	iface := &Dummy{LinkAttrs{Name: "bar1", MasterIndex: master.Index}}
+	if err := LinkAdd(iface); err != nil {
+		t.Fatal(err)
+	}
+
+	oldpi, err := LinkGetProtinfo(iface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !oldpi.McastFlood {
+		t.Fatalf("McastFlood is not enabled by default for %s, but should", iface.Name)
+	}
+	if oldpi.MulticastRouter != MDB_RTR_TYPE_TEMP_QUERY {
+		t.Fatalf("MulticastRouter is %d by default for %s, expected %d", oldpi.MulticastRouter, iface.Name, MDB_RTR_TYPE_TEMP_QUERY)
+	}
+
+	if err := LinkSetMcastFlood(iface, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMcastToUcast(iface, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetMulticastRouter(iface, MDB_RTR_TYPE_PERM); err != nil {
+		t.Fatal(err)
+	}
+	pi, err := LinkGetProtinfo(iface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pi.McastFlood {
+		t.Fatalf("McastFlood is enabled for %s, but shouldn't", iface.Name)
+	}
+	if !pi.McastToUcast {
+		t.Fatalf("McastToUcast is not enabled for %s, but should", iface.Name)
+	}
+	if pi.MulticastRouter != MDB_RTR_TYPE_PERM {
+		t.Fatalf("MulticastRouter is %d for %s, expected %d", pi.MulticastRouter, iface.Name, MDB_RTR_TYPE_PERM)
+	}
+	if pi.Flood != oldpi.Flood {
+		t.Fatalf("Flood field was changed for %s but shouldn't", iface.Name)
+	}
+	if pi.Learning != oldpi.Learning {
+		t.Fatalf("Learning field was changed for %s but shouldn't", iface.Name)
+	}
+}
+
+func TestProtinfoFloodLearningNeighSuppress(t *testing.T) {
+	minKernelRequired(t, 4, 15)
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	master := &Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}
+	if err := LinkAdd(master); err != nil {
+		t.Fatal(err)
+	}
+	iface := &Dummy{LinkAttrs{Name: "bar1", MasterIndex: master.Index}}
+	if err := LinkAdd(iface); err != nil {
+		t.Fatal(err)
+	}
+
+	oldpi, err := LinkGetProtinfo(iface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !oldpi.Learning || !oldpi.Flood {
+		t.Fatalf("Learning and Flood are not enabled by default for %s, but should", iface.Name)
+	}
+	if oldpi.NeighSuppress {
+		t.Fatalf("NeighSuppress is enabled by default for %s, but shouldn't", iface.Name)
+	}
+
+	if err := LinkSetLearning(iface, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetFlood(iface, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetBrNeighSuppress(iface, true); err != nil {
+		t.Fatal(err)
+	}
+	pi, err := LinkGetProtinfo(iface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pi.Learning {
+		t.Fatalf("Learning is enabled for %s, but shouldn't", iface.Name)
+	}
+	if pi.Flood {
+		t.Fatalf("Flood is enabled for %s, but shouldn't", iface.Name)
+	}
+	if !pi.NeighSuppress {
+		t.Fatalf("NeighSuppress is not enabled for %s, but should", iface.Name)
+	}
+	if pi.McastFlood != oldpi.McastFlood {
+		t.Fatalf("McastFlood field was changed for %s but shouldn't", iface.Name)
+	}
+}
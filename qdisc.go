@@ -3,8 +3,12 @@ package netlink
 import (
 	"fmt"
 	"math"
+	"strings"
 )
 
+// Reserved parent handles of the kernel (TC_H_* in pkt_sched.h).
+// HANDLE_ROOT is the parent of the root egress qdisc and HANDLE_INGRESS
+// (TC_H_INGRESS) the parent of the ingress and clsact qdiscs.
 const (
 	HANDLE_NONE      = 0
 	HANDLE_INGRESS   = 0xFFFFFFF1
@@ -12,9 +16,16 @@ const (
 	HANDLE_ROOT      = 0xFFFFFFFF
 	PRIORITY_MAP_LEN = 16
 )
+
+// Filter parents of the ingress and clsact qdiscs. They are the minor
+// handles TC_H_MIN_INGRESS and TC_H_MIN_EGRESS below the ffff: major
+// handle, i.e. ffff:fff2 and ffff:fff3. HANDLE_CLSACT_INGRESS and
+// HANDLE_CLSACT_EGRESS are the same values named after the clsact hooks.
 const (
-	HANDLE_MIN_INGRESS = 0xFFFFFFF2
-	HANDLE_MIN_EGRESS  = 0xFFFFFFF3
+	HANDLE_MIN_INGRESS    = 0xFFFFFFF2
+	HANDLE_MIN_EGRESS     = 0xFFFFFFF3
+	HANDLE_CLSACT_INGRESS = HANDLE_MIN_INGRESS
+	HANDLE_CLSACT_EGRESS  = HANDLE_MIN_EGRESS
 )
 
 type Qdisc interface {
@@ -30,6 +41,25 @@ type QdiscAttrs struct {
 	Handle    uint32
 	Parent    uint32
 	Refcnt    uint32 // read only
+	Stab      *Stab  // size table, nil for none
+	// RawAttributes holds the qdisc attributes not decoded above. They are
+	// sent back unchanged when the qdisc is added, changed or replaced.
+	RawAttributes []RawAttribute
+}
+
+// Stab is the size table of a qdisc. It makes the qdisc account each
+// packet with Overhead more bytes and, for LINKLAYER_ATM, with the padding
+// of the ATM cells carrying it, as on ADSL links. Mpu is the minimum
+// packet size. Without ATM and Mpu only the overhead is applied, else a
+// table of Tsize entries covering packets up to Mtu bytes is computed on
+// add, 0 uses 512 entries and 2047 bytes. LinkLayer takes the nl
+// LINKLAYER_ constants.
+type Stab struct {
+	Overhead  int32
+	LinkLayer int
+	Mpu       uint32
+	Mtu       uint32
+	Tsize     uint32
 }
 
 func (q QdiscAttrs) String() string {
@@ -58,6 +88,100 @@ func HandleStr(handle uint32) string {
 	}
 }
 
+// tcHandleStr formats a handle like tc, which leaves out a zero minor.
+func tcHandleStr(handle uint32) string {
+	switch handle {
+	case HANDLE_NONE:
+		return "none"
+	case HANDLE_ROOT:
+		return "root"
+	}
+	major, minor := MajorMinor(handle)
+	if minor == 0 {
+		return fmt.Sprintf("%x:", major)
+	}
+	return fmt.Sprintf("%x:%x", major, minor)
+}
+
+// qdiscString formats a qdisc like `tc qdisc show`:
+// qdisc $type $handle dev $index root|parent $parent [refcnt $refcnt] $options
+func qdiscString(qdisc Qdisc, options ...string) string {
+	attrs := qdisc.Attrs()
+	elems := []string{"qdisc", qdisc.Type(), tcHandleStr(attrs.Handle), fmt.Sprintf("dev %d", attrs.LinkIndex)}
+	if attrs.Parent == HANDLE_ROOT {
+		elems = append(elems, "root")
+	} else {
+		elems = append(elems, "parent", tcHandleStr(attrs.Parent))
+	}
+	if attrs.Refcnt > 0 {
+		elems = append(elems, fmt.Sprintf("refcnt %d", attrs.Refcnt))
+	}
+	return strings.Join(append(elems, options...), " ")
+}
+
+// formatRate formats a rate in bytes per second in bits like tc, with the
+// largest unit that keeps it a whole number below 1000 units, e.g. 10Mbit.
+func formatRate(rate uint64) string {
+	units := []string{"bit", "Kbit", "Mbit", "Gbit", "Tbit"}
+	rate *= 8
+	i := 0
+	for ; i < len(units)-1; i++ {
+		if rate < 1000 || (rate%1000 != 0 && rate < 1000*1000) {
+			break
+		}
+		rate /= 1000
+	}
+	return fmt.Sprintf("%d%s", rate, units[i])
+}
+
+// formatSize formats a size in bytes like tc, e.g. 1600b or 32Kb. Like
+// tc it rounds to Kb and Mb when the size is close enough, as sizes
+// converted back from ticks are off by a few bytes.
+func formatSize(size uint32) string {
+	sz := float64(size)
+	if mb := math.Round(sz / (1024 * 1024)); size >= 1024*1024 && math.Abs(mb*1024*1024-sz) < 1024 {
+		return fmt.Sprintf("%gMb", mb)
+	}
+	if kb := math.Round(sz / 1024); size >= 1024 && math.Abs(kb*1024-sz) < 16 {
+		return fmt.Sprintf("%gKb", kb)
+	}
+	return fmt.Sprintf("%db", size)
+}
+
+// formatTime formats a time in microseconds like tc, e.g. 5ms or 1.5s.
+func formatTime(usec uint32) string {
+	switch {
+	case usec >= 1000000:
+		return fmt.Sprintf("%gs", float64(usec)/1000000)
+	case usec >= 1000:
+		return fmt.Sprintf("%gms", float64(usec)/1000)
+	}
+	return fmt.Sprintf("%dus", usec)
+}
+
+// formatPercent formats a probability scaled to math.MaxUint32 like tc.
+func formatPercent(prob uint32) string {
+	return fmt.Sprintf("%.3g%%", float64(prob)*100/math.MaxUint32)
+}
+
+// formatPriomap formats a priority map like tc, one band per priority.
+func formatPriomap(priomap []uint8) string {
+	elems := make([]string, len(priomap))
+	for i, band := range priomap {
+		elems[i] = fmt.Sprintf("%d", band)
+	}
+	return strings.Join(elems, " ")
+}
+
+// onOff formats a flag like tc does for qdisc options that can be
+// switched on and off.
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
+
 func Percentage2u32(percentage float32) uint32 {
 	// FIXME this is most likely not the best way to convert from % to uint32
 	if percentage == 100 {
@@ -74,6 +198,10 @@ type PfifoFast struct {
 	PriorityMap [PRIORITY_MAP_LEN]uint8
 }
 
+func (qdisc *PfifoFast) String() string {
+	return qdiscString(qdisc, fmt.Sprintf("bands %d priomap %s", qdisc.Bands, formatPriomap(qdisc.PriorityMap[:])))
+}
+
 func (qdisc *PfifoFast) Attrs() *QdiscAttrs {
 	return &qdisc.QdiscAttrs
 }
@@ -82,6 +210,52 @@ func (qdisc *PfifoFast) Type() string {
 	return "pfifo_fast"
 }
 
+// Pfifo is a first in, first out qdisc limited by the number of packets
+type Pfifo struct {
+	QdiscAttrs
+	// Limit is the queue length in packets, 0 uses the tx queue length
+	// of the link
+	Limit uint32
+}
+
+func (qdisc *Pfifo) String() string {
+	if qdisc.Limit == 0 {
+		return qdiscString(qdisc)
+	}
+	return qdiscString(qdisc, fmt.Sprintf("limit %dp", qdisc.Limit))
+}
+
+func (qdisc *Pfifo) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Pfifo) Type() string {
+	return "pfifo"
+}
+
+// Bfifo is a first in, first out qdisc limited by the number of bytes
+type Bfifo struct {
+	QdiscAttrs
+	// Limit is the queue length in bytes, 0 uses the tx queue length of
+	// the link times its MTU
+	Limit uint32
+}
+
+func (qdisc *Bfifo) String() string {
+	if qdisc.Limit == 0 {
+		return qdiscString(qdisc)
+	}
+	return qdiscString(qdisc, fmt.Sprintf("limit %s", formatSize(qdisc.Limit)))
+}
+
+func (qdisc *Bfifo) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Bfifo) Type() string {
+	return "bfifo"
+}
+
 // Prio is a basic qdisc that works just like PfifoFast
 type Prio struct {
 	QdiscAttrs
@@ -97,6 +271,10 @@ func NewPrio(attrs QdiscAttrs) *Prio {
 	}
 }
 
+func (qdisc *Prio) String() string {
+	return qdiscString(qdisc, fmt.Sprintf("bands %d priomap %s", qdisc.Bands, formatPriomap(qdisc.PriorityMap[:])))
+}
+
 func (qdisc *Prio) Attrs() *QdiscAttrs {
 	return &qdisc.QdiscAttrs
 }
@@ -112,7 +290,12 @@ type Htb struct {
 	Rate2Quantum uint32
 	Defcls       uint32
 	Debug        uint32
-	DirectPkts   uint32
+	// DirectPkts is the number of packets that bypassed shaping through
+	// the direct queue because they matched no class, read only
+	DirectPkts uint32
+	// DirectQlen is the length of the direct queue in packets, nil keeps
+	// the kernel default of the tx queue length
+	DirectQlen *uint32
 }
 
 func NewHtb(attrs QdiscAttrs) *Htb {
@@ -126,6 +309,16 @@ func NewHtb(attrs QdiscAttrs) *Htb {
 	}
 }
 
+func (qdisc *Htb) String() string {
+	options := []string{
+		fmt.Sprintf("r2q %d default %#x direct_packets_stat %d", qdisc.Rate2Quantum, qdisc.Defcls, qdisc.DirectPkts),
+	}
+	if qdisc.DirectQlen != nil {
+		options = append(options, fmt.Sprintf("direct_qlen %d", *qdisc.DirectQlen))
+	}
+	return qdiscString(qdisc, options...)
+}
+
 func (qdisc *Htb) Attrs() *QdiscAttrs {
 	return &qdisc.QdiscAttrs
 }
@@ -176,13 +369,6 @@ type Netem struct {
 	CorruptCorr   uint32
 }
 
-func (netem *Netem) String() string {
-	return fmt.Sprintf(
-		"{Latency: %v, Limit: %v, Loss: %v, Gap: %v, Duplicate: %v, Jitter: %v}",
-		netem.Latency, netem.Limit, netem.Loss, netem.Gap, netem.Duplicate, netem.Jitter,
-	)
-}
-
 func (qdisc *Netem) Attrs() *QdiscAttrs {
 	return &qdisc.QdiscAttrs
 }
@@ -215,6 +401,10 @@ type Ingress struct {
 	QdiscAttrs
 }
 
+func (qdisc *Ingress) String() string {
+	return qdiscString(qdisc)
+}
+
 func (qdisc *Ingress) Attrs() *QdiscAttrs {
 	return &qdisc.QdiscAttrs
 }
@@ -230,6 +420,10 @@ type GenericQdisc struct {
 	QdiscType string
 }
 
+func (qdisc *GenericQdisc) String() string {
+	return qdiscString(qdisc)
+}
+
 func (qdisc *GenericQdisc) Attrs() *QdiscAttrs {
 	return &qdisc.QdiscAttrs
 }
@@ -259,10 +453,7 @@ func (hfsc *Hfsc) Type() string {
 }
 
 func (hfsc *Hfsc) String() string {
-	return fmt.Sprintf(
-		"{%v -- default: %d}",
-		hfsc.Attrs(), hfsc.Defcls,
-	)
+	return qdiscString(hfsc, fmt.Sprintf("default %#x", hfsc.Defcls))
 }
 
 // Fq is a classless packet scheduler meant to be mostly used for locally generated traffic.
@@ -284,10 +475,38 @@ type Fq struct {
 }
 
 func (fq *Fq) String() string {
-	return fmt.Sprintf(
-		"{PacketLimit: %v, FlowPacketLimit: %v, Quantum: %v, InitialQuantum: %v, Pacing: %v, FlowDefaultRate: %v, FlowMaxRate: %v, Buckets: %v, FlowRefillDelay: %v,  LowRateThreshold: %v}",
-		fq.PacketLimit, fq.FlowPacketLimit, fq.Quantum, fq.InitialQuantum, fq.Pacing, fq.FlowDefaultRate, fq.FlowMaxRate, fq.Buckets, fq.FlowRefillDelay, fq.LowRateThreshold,
-	)
+	options := []string{}
+	if fq.PacketLimit > 0 {
+		options = append(options, fmt.Sprintf("limit %dp", fq.PacketLimit))
+	}
+	if fq.FlowPacketLimit > 0 {
+		options = append(options, fmt.Sprintf("flow_limit %dp", fq.FlowPacketLimit))
+	}
+	if fq.Buckets > 0 {
+		options = append(options, fmt.Sprintf("buckets %d", 1<<fq.Buckets))
+	}
+	if fq.Quantum > 0 {
+		options = append(options, fmt.Sprintf("quantum %s", formatSize(fq.Quantum)))
+	}
+	if fq.InitialQuantum > 0 {
+		options = append(options, fmt.Sprintf("initial_quantum %s", formatSize(fq.InitialQuantum)))
+	}
+	if fq.Pacing == 0 {
+		options = append(options, "nopacing")
+	}
+	if fq.FlowDefaultRate > 0 {
+		options = append(options, fmt.Sprintf("defrate %s", formatRate(uint64(fq.FlowDefaultRate))))
+	}
+	if fq.FlowMaxRate > 0 {
+		options = append(options, fmt.Sprintf("maxrate %s", formatRate(uint64(fq.FlowMaxRate))))
+	}
+	if fq.LowRateThreshold > 0 {
+		options = append(options, fmt.Sprintf("low_rate_threshold %s", formatRate(uint64(fq.LowRateThreshold))))
+	}
+	if fq.FlowRefillDelay > 0 {
+		options = append(options, fmt.Sprintf("refill_delay %s", formatTime(fq.FlowRefillDelay)))
+	}
+	return qdiscString(fq, options...)
 }
 
 func NewFq(attrs QdiscAttrs) *Fq {
@@ -318,10 +537,26 @@ type FqCodel struct {
 }
 
 func (fqcodel *FqCodel) String() string {
-	return fmt.Sprintf(
-		"{%v -- Target: %v, Limit: %v, Interval: %v, ECM: %v, Flows: %v, Quantum: %v}",
-		fqcodel.Attrs(), fqcodel.Target, fqcodel.Limit, fqcodel.Interval, fqcodel.ECN, fqcodel.Flows, fqcodel.Quantum,
-	)
+	options := []string{}
+	if fqcodel.Limit > 0 {
+		options = append(options, fmt.Sprintf("limit %dp", fqcodel.Limit))
+	}
+	if fqcodel.Flows > 0 {
+		options = append(options, fmt.Sprintf("flows %d", fqcodel.Flows))
+	}
+	if fqcodel.Quantum > 0 {
+		options = append(options, fmt.Sprintf("quantum %d", fqcodel.Quantum))
+	}
+	if fqcodel.Target > 0 {
+		options = append(options, fmt.Sprintf("target %s", formatTime(fqcodel.Target)))
+	}
+	if fqcodel.Interval > 0 {
+		options = append(options, fmt.Sprintf("interval %s", formatTime(fqcodel.Interval)))
+	}
+	if fqcodel.ECN > 0 {
+		options = append(options, "ecn")
+	}
+	return qdiscString(fqcodel, options...)
 }
 
 func NewFqCodel(attrs QdiscAttrs) *FqCodel {
@@ -338,3 +573,653 @@ func (qdisc *FqCodel) Attrs() *QdiscAttrs {
 func (qdisc *FqCodel) Type() string {
 	return "fq_codel"
 }
+
+// Codel (Controlled Delay) is an AQM that drops packets based on the time
+// they spent in the queue.
+type Codel struct {
+	QdiscAttrs
+	// Target, Interval and CEThreshold are in microseconds
+	Target      uint32
+	Limit       uint32
+	Interval    uint32
+	ECN         uint32
+	CEThreshold uint32
+	// XStats are the statistics of the codel state machine, read only
+	XStats *CodelXStats
+}
+
+// CodelXStats are the codel specific statistics of a Codel qdisc.
+type CodelXStats struct {
+	MaxPacket     uint32
+	Count         uint32 // drops since entering the dropping state
+	LastCount     uint32
+	LDelay        uint32 // queue delay of the last dequeued packet in microseconds
+	DropNext      int32
+	DropOverlimit uint32 // drops because the limit was hit
+	EcnMark       uint32
+	Dropping      bool
+	CeMark        uint32
+}
+
+func (codel *Codel) String() string {
+	options := []string{}
+	if codel.Limit > 0 {
+		options = append(options, fmt.Sprintf("limit %dp", codel.Limit))
+	}
+	if codel.Target > 0 {
+		options = append(options, fmt.Sprintf("target %s", formatTime(codel.Target)))
+	}
+	if codel.Interval > 0 {
+		options = append(options, fmt.Sprintf("interval %s", formatTime(codel.Interval)))
+	}
+	if codel.CEThreshold > 0 {
+		options = append(options, fmt.Sprintf("ce_threshold %s", formatTime(codel.CEThreshold)))
+	}
+	if codel.ECN > 0 {
+		options = append(options, "ecn")
+	}
+	return qdiscString(codel, options...)
+}
+
+func (qdisc *Codel) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Codel) Type() string {
+	return "codel"
+}
+
+// Pie (Proportional Integral controller Enhanced) is an AQM that drops
+// packets with a probability derived from the queue delay.
+type Pie struct {
+	QdiscAttrs
+	// Target and TUpdate are in microseconds
+	Target  uint32
+	TUpdate uint32
+	Limit   uint32
+	// Alpha and Beta weigh the delay deviation and trend, 0-32
+	Alpha    uint32
+	Beta     uint32
+	ECN      uint32
+	Bytemode uint32
+}
+
+func (pie *Pie) String() string {
+	options := []string{}
+	if pie.Limit > 0 {
+		options = append(options, fmt.Sprintf("limit %dp", pie.Limit))
+	}
+	if pie.Target > 0 {
+		options = append(options, fmt.Sprintf("target %s", formatTime(pie.Target)))
+	}
+	if pie.TUpdate > 0 {
+		options = append(options, fmt.Sprintf("tupdate %s", formatTime(pie.TUpdate)))
+	}
+	if pie.Alpha > 0 || pie.Beta > 0 {
+		options = append(options, fmt.Sprintf("alpha %d beta %d", pie.Alpha, pie.Beta))
+	}
+	if pie.ECN > 0 {
+		options = append(options, "ecn")
+	}
+	if pie.Bytemode > 0 {
+		options = append(options, "bytemode")
+	}
+	return qdiscString(pie, options...)
+}
+
+func (qdisc *Pie) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Pie) Type() string {
+	return "pie"
+}
+
+// FqPie combines flow queuing with the PIE AQM on each flow.
+type FqPie struct {
+	QdiscAttrs
+	// Target and TUpdate are in microseconds
+	Target      uint32
+	TUpdate     uint32
+	Limit       uint32
+	Flows       uint32
+	Alpha       uint32
+	Beta        uint32
+	Quantum     uint32
+	MemoryLimit uint32
+	// ECNProb is the drop probability in percent above which packets are
+	// dropped instead of marked
+	ECNProb  uint32
+	ECN      uint32
+	Bytemode uint32
+}
+
+func (fqpie *FqPie) String() string {
+	options := []string{}
+	if fqpie.Limit > 0 {
+		options = append(options, fmt.Sprintf("limit %dp", fqpie.Limit))
+	}
+	if fqpie.Flows > 0 {
+		options = append(options, fmt.Sprintf("flows %d", fqpie.Flows))
+	}
+	if fqpie.Target > 0 {
+		options = append(options, fmt.Sprintf("target %s", formatTime(fqpie.Target)))
+	}
+	if fqpie.TUpdate > 0 {
+		options = append(options, fmt.Sprintf("tupdate %s", formatTime(fqpie.TUpdate)))
+	}
+	if fqpie.Alpha > 0 || fqpie.Beta > 0 {
+		options = append(options, fmt.Sprintf("alpha %d beta %d", fqpie.Alpha, fqpie.Beta))
+	}
+	if fqpie.Quantum > 0 {
+		options = append(options, fmt.Sprintf("quantum %s", formatSize(fqpie.Quantum)))
+	}
+	if fqpie.MemoryLimit > 0 {
+		options = append(options, fmt.Sprintf("memory_limit %s", formatSize(fqpie.MemoryLimit)))
+	}
+	if fqpie.ECNProb > 0 {
+		options = append(options, fmt.Sprintf("ecn_prob %d", fqpie.ECNProb))
+	}
+	if fqpie.ECN > 0 {
+		options = append(options, "ecn")
+	}
+	if fqpie.Bytemode > 0 {
+		options = append(options, "bytemode")
+	}
+	return qdiscString(fqpie, options...)
+}
+
+func (qdisc *FqPie) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *FqPie) Type() string {
+	return "fq_pie"
+}
+
+// CakeDiffserv is the mapping of DSCP values to the priority tins of cake.
+type CakeDiffserv uint32
+
+const (
+	CAKE_DIFFSERV_DIFFSERV3  CakeDiffserv = iota // 3 tins: bulk, best effort and voice (default)
+	CAKE_DIFFSERV_DIFFSERV4                      // 4 tins: bulk, best effort, video and voice
+	CAKE_DIFFSERV_DIFFSERV8                      // 8 tins for the full range of DSCP classes
+	CAKE_DIFFSERV_BESTEFFORT                     // a single tin, DSCP is ignored
+	CAKE_DIFFSERV_PRECEDENCE                     // 8 tins on the legacy IP precedence bits
+)
+
+func (d CakeDiffserv) String() string {
+	switch d {
+	case CAKE_DIFFSERV_DIFFSERV3:
+		return "diffserv3"
+	case CAKE_DIFFSERV_DIFFSERV4:
+		return "diffserv4"
+	case CAKE_DIFFSERV_DIFFSERV8:
+		return "diffserv8"
+	case CAKE_DIFFSERV_BESTEFFORT:
+		return "besteffort"
+	case CAKE_DIFFSERV_PRECEDENCE:
+		return "precedence"
+	}
+	return fmt.Sprintf("unknown(%d)", uint32(d))
+}
+
+// Cake (Common Applications Kept Enhanced) is a shaping qdisc combining
+// flow isolation, an AQM and diffserv aware priority tins.
+type Cake struct {
+	QdiscAttrs
+	// Bandwidth is the shaped rate in bytes per second, 0 is unlimited
+	Bandwidth uint64
+	// RTT is the expected round trip time in microseconds, 0 keeps the
+	// kernel default
+	RTT      uint32
+	Diffserv CakeDiffserv
+	// AutorateIngress estimates the bandwidth from the rate at which
+	// traffic arrives, for links whose capacity varies
+	AutorateIngress bool
+	// Nat looks up the flows in conntrack, it is only sent when set, so a
+	// QdiscChange can't turn it off
+	Nat     bool
+	Wash    bool
+	Ingress bool
+}
+
+func (cake *Cake) String() string {
+	options := []string{"bandwidth unlimited"}
+	if cake.Bandwidth > 0 {
+		options[0] = fmt.Sprintf("bandwidth %s", formatRate(cake.Bandwidth))
+	}
+	if cake.AutorateIngress {
+		options = append(options, "autorate-ingress")
+	}
+	options = append(options, cake.Diffserv.String())
+	if cake.Nat {
+		options = append(options, "nat")
+	} else {
+		options = append(options, "nonat")
+	}
+	if cake.Wash {
+		options = append(options, "wash")
+	} else {
+		options = append(options, "nowash")
+	}
+	if cake.Ingress {
+		options = append(options, "ingress")
+	}
+	if cake.RTT > 0 {
+		options = append(options, fmt.Sprintf("rtt %s", formatTime(cake.RTT)))
+	}
+	return qdiscString(cake, options...)
+}
+
+func NewCake(attrs QdiscAttrs) *Cake {
+	return &Cake{
+		QdiscAttrs: attrs,
+		Diffserv:   CAKE_DIFFSERV_DIFFSERV3,
+	}
+}
+
+func (qdisc *Cake) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Cake) Type() string {
+	return "cake"
+}
+
+// Etf (Earliest TxTime First) holds packets until the txtime set on them
+// by the sending socket. ClockId is the clock of the txtime, the kernel
+// only accepts CLOCK_TAI.
+type Etf struct {
+	QdiscAttrs
+	ClockId int32
+	// Delta is how many nanoseconds before its txtime a packet is dequeued
+	Delta int32
+	// Deadline dequeues packets as soon as possible while txtime is a
+	// deadline rather than the exact transmit time
+	Deadline      bool
+	Offload       bool
+	SkipSockCheck bool
+}
+
+func (etf *Etf) String() string {
+	clockid := fmt.Sprintf("%d", etf.ClockId)
+	if etf.ClockId == 11 {
+		clockid = "TAI"
+	}
+	return qdiscString(etf, fmt.Sprintf(
+		"clockid %s delta %d offload %s deadline_mode %s skip_sock_check %s",
+		clockid, etf.Delta, onOff(etf.Offload), onOff(etf.Deadline), onOff(etf.SkipSockCheck),
+	))
+}
+
+func NewEtf(attrs QdiscAttrs) *Etf {
+	return &Etf{
+		QdiscAttrs: attrs,
+		ClockId:    11, // CLOCK_TAI
+	}
+}
+
+func (qdisc *Etf) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Etf) Type() string {
+	return "etf"
+}
+
+const (
+	TAPRIO_CMD_SET_GATES       = 0x00
+	TAPRIO_CMD_SET_AND_HOLD    = 0x01
+	TAPRIO_CMD_SET_AND_RELEASE = 0x02
+)
+
+const (
+	TAPRIO_FLAG_TXTIME_ASSIST = 0x1
+	TAPRIO_FLAG_FULL_OFFLOAD  = 0x2
+)
+
+// TaprioEntry opens the gates of the traffic classes set in GateMask for
+// Interval nanoseconds.
+type TaprioEntry struct {
+	Command  uint8
+	GateMask uint32
+	Interval uint32
+}
+
+// String formats the entry like the sched-entry argument of tc.
+func (entry TaprioEntry) String() string {
+	cmd := fmt.Sprintf("%d", entry.Command)
+	switch entry.Command {
+	case TAPRIO_CMD_SET_GATES:
+		cmd = "S"
+	case TAPRIO_CMD_SET_AND_HOLD:
+		cmd = "H"
+	case TAPRIO_CMD_SET_AND_RELEASE:
+		cmd = "R"
+	}
+	return fmt.Sprintf("sched-entry %s %02x %d", cmd, entry.GateMask, entry.Interval)
+}
+
+// formatTcQueues formats the tx queue ranges of the traffic classes like
+// the queues argument of tc, count@offset for each class.
+func formatTcQueues(numTc uint8, count, offset [PRIORITY_MAP_LEN]uint16) string {
+	elems := []string{}
+	for i := 0; i < int(numTc) && i < PRIORITY_MAP_LEN; i++ {
+		elems = append(elems, fmt.Sprintf("%d@%d", count[i], offset[i]))
+	}
+	return strings.Join(elems, " ")
+}
+
+// Taprio (Time Aware Priority) schedules traffic classes through a
+// repeating list of gate entries (IEEE 802.1Qbv). Without offload flags
+// the schedule is run in software against ClockId.
+type Taprio struct {
+	QdiscAttrs
+	NumTc     uint8
+	PrioTcMap [PRIORITY_MAP_LEN]uint8
+	// Count and Offset are the range of tx queues of each traffic class
+	Count    [PRIORITY_MAP_LEN]uint16
+	Offset   [PRIORITY_MAP_LEN]uint16
+	ClockId  int32
+	BaseTime int64
+	// CycleTime is the length of the schedule in nanoseconds, 0 lets the
+	// kernel use the sum of the entry intervals
+	CycleTime int64
+	Flags     uint32
+	Schedule  []TaprioEntry
+}
+
+func (taprio *Taprio) String() string {
+	clockid := fmt.Sprintf("%d", taprio.ClockId)
+	if taprio.ClockId == 11 {
+		clockid = "TAI"
+	}
+	options := []string{
+		fmt.Sprintf("num_tc %d", taprio.NumTc),
+		fmt.Sprintf("map %s", formatPriomap(taprio.PrioTcMap[:])),
+		fmt.Sprintf("queues %s", formatTcQueues(taprio.NumTc, taprio.Count, taprio.Offset)),
+		fmt.Sprintf("clockid %s base-time %d", clockid, taprio.BaseTime),
+	}
+	if taprio.CycleTime > 0 {
+		options = append(options, fmt.Sprintf("cycle-time %d", taprio.CycleTime))
+	}
+	if taprio.Flags > 0 {
+		options = append(options, fmt.Sprintf("flags %#x", taprio.Flags))
+	}
+	for _, entry := range taprio.Schedule {
+		options = append(options, entry.String())
+	}
+	return qdiscString(taprio, options...)
+}
+
+func NewTaprio(attrs QdiscAttrs) *Taprio {
+	return &Taprio{
+		QdiscAttrs: attrs,
+		ClockId:    11, // CLOCK_TAI
+	}
+}
+
+func (qdisc *Taprio) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Taprio) Type() string {
+	return "taprio"
+}
+
+// Mqprio maps priorities to traffic classes and each traffic class to a
+// range of tx queues of a multiqueue device.
+type Mqprio struct {
+	QdiscAttrs
+	NumTc     uint8
+	PrioTcMap [PRIORITY_MAP_LEN]uint8
+	// Count and Offset are the range of tx queues of each traffic class
+	Count  [PRIORITY_MAP_LEN]uint16
+	Offset [PRIORITY_MAP_LEN]uint16
+	// HwOffload asks the driver to configure the traffic classes in
+	// hardware
+	HwOffload bool
+}
+
+func (mqprio *Mqprio) String() string {
+	hw := 0
+	if mqprio.HwOffload {
+		hw = 1
+	}
+	return qdiscString(mqprio, fmt.Sprintf(
+		"num_tc %d map %s queues %s hw %d",
+		mqprio.NumTc, formatPriomap(mqprio.PrioTcMap[:]), formatTcQueues(mqprio.NumTc, mqprio.Count, mqprio.Offset), hw,
+	))
+}
+
+func (qdisc *Mqprio) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Mqprio) Type() string {
+	return "mqprio"
+}
+
+// Multiq has one band per tx queue of the device, packets are put in the
+// band of their queue mapping. The kernel always uses the number of tx
+// queues for Bands, MaxBands is the number of queues of the device.
+type Multiq struct {
+	QdiscAttrs
+	Bands    uint16
+	MaxBands uint16
+}
+
+func (multiq *Multiq) String() string {
+	return qdiscString(multiq, fmt.Sprintf("bands %d/%d", multiq.Bands, multiq.MaxBands))
+}
+
+func (qdisc *Multiq) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Multiq) Type() string {
+	return "multiq"
+}
+
+// Cbs (Credit Based Shaper) shapes a traffic class of an AVB stream as
+// described in IEEE 802.1Qav. The slopes are in kbit/s and the credits
+// in bytes.
+type Cbs struct {
+	QdiscAttrs
+	Hicredit  int32
+	Locredit  int32
+	Idleslope int32
+	// Sendslope is the idle slope minus the link speed, so never positive
+	Sendslope int32
+	Offload   bool
+}
+
+func (cbs *Cbs) String() string {
+	offload := 0
+	if cbs.Offload {
+		offload = 1
+	}
+	return qdiscString(cbs, fmt.Sprintf(
+		"hicredit %d locredit %d sendslope %d idleslope %d offload %d",
+		cbs.Hicredit, cbs.Locredit, cbs.Sendslope, cbs.Idleslope, offload,
+	))
+}
+
+func (qdisc *Cbs) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Cbs) Type() string {
+	return "cbs"
+}
+
+// Hhf (Heavy-Hitter Filter) puts the flows that send the most into a
+// separate low priority queue so they can't starve the other flows.
+// Limit is in packets, Quantum and AdmitBytes in bytes, ResetTimeout and
+// EVICTTimeout in microseconds. NonHHWeight is how many times the quantum
+// of the heavy-hitter queue the other flows get.
+type Hhf struct {
+	QdiscAttrs
+	Limit        uint32
+	Quantum      uint32
+	HHLimit      uint32 // maximum number of heavy-hitters tracked
+	ResetTimeout uint32
+	AdmitBytes   uint32 // bytes a flow sends in a period to become a heavy-hitter
+	EVICTTimeout uint32
+	NonHHWeight  uint32
+	// XStats are the heavy-hitter statistics, read only
+	XStats *HhfXStats
+}
+
+// HhfXStats are the hhf specific statistics of a Hhf qdisc.
+type HhfXStats struct {
+	DropOverlimit uint32 // drops because the limit was hit
+	HHOverlimit   uint32 // heavy-hitters not tracked because HHLimit was hit
+	HHTotCount    uint32 // heavy-hitters caught so far
+	HHCurCount    uint32 // heavy-hitters tracked now
+}
+
+func (hhf *Hhf) String() string {
+	options := []string{}
+	if hhf.Limit > 0 {
+		options = append(options, fmt.Sprintf("limit %dp", hhf.Limit))
+	}
+	if hhf.Quantum > 0 {
+		options = append(options, fmt.Sprintf("quantum %s", formatSize(hhf.Quantum)))
+	}
+	if hhf.HHLimit > 0 {
+		options = append(options, fmt.Sprintf("hh_limit %d", hhf.HHLimit))
+	}
+	if hhf.ResetTimeout > 0 {
+		options = append(options, fmt.Sprintf("reset_timeout %s", formatTime(hhf.ResetTimeout)))
+	}
+	if hhf.AdmitBytes > 0 {
+		options = append(options, fmt.Sprintf("admit_bytes %s", formatSize(hhf.AdmitBytes)))
+	}
+	if hhf.EVICTTimeout > 0 {
+		options = append(options, fmt.Sprintf("evict_timeout %s", formatTime(hhf.EVICTTimeout)))
+	}
+	if hhf.NonHHWeight > 0 {
+		options = append(options, fmt.Sprintf("non_hh_weight %d", hhf.NonHHWeight))
+	}
+	return qdiscString(hhf, options...)
+}
+
+func (qdisc *Hhf) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Hhf) Type() string {
+	return "hhf"
+}
+
+// Sfb (Stochastic Fair Blue) drops or marks the packets of each flow with
+// a probability that grows while the flow keeps its bins full, and rate
+// limits the flows that don't back off. Rehash and DB (the warmup time
+// of the second set of bins) are in milliseconds, Target is the queue
+// length per bin, Max the length of the longest allowed queue, and Limit
+// the length of the whole queue in packets, 0 uses the tx queue length
+// of the link. Increment and Decrement are probabilities where
+// SFB_MAX_PROB stands for 1. PenaltyRate is in packets per second.
+// The kernel uses all of them as given, so start from NewSfb.
+type Sfb struct {
+	QdiscAttrs
+	Rehash       uint32
+	DB           uint32
+	Max          uint32
+	Target       uint32
+	Increment    uint32
+	Decrement    uint32
+	Limit        uint32
+	PenaltyRate  uint32
+	PenaltyBurst uint32
+	// XStats are the sfb statistics, read only
+	XStats *SfbXStats
+}
+
+// SFB_MAX_PROB is the probability of 1 in Sfb Increment and Decrement.
+const SFB_MAX_PROB = 0xFFFF
+
+// SfbXStats are the sfb specific statistics of a Sfb qdisc.
+type SfbXStats struct {
+	EarlyDrop   uint32
+	PenaltyDrop uint32
+	BucketDrop  uint32
+	QueueDrop   uint32
+	ChildDrop   uint32 // drops in the child qdisc
+	Marked      uint32
+	MaxQlen     uint32
+	MaxProb     uint32
+	AvgProb     uint32
+}
+
+// NewSfb returns a Sfb with the defaults used by tc.
+func NewSfb(attrs QdiscAttrs) *Sfb {
+	return &Sfb{
+		QdiscAttrs:   attrs,
+		Rehash:       600000,
+		DB:           60000,
+		Max:          25,
+		Target:       20,
+		Increment:    (SFB_MAX_PROB + 500) / 1000,
+		Decrement:    (SFB_MAX_PROB + 3000) / 6000,
+		PenaltyRate:  10,
+		PenaltyBurst: 20,
+	}
+}
+
+func (sfb *Sfb) String() string {
+	return qdiscString(sfb, fmt.Sprintf(
+		"limit %d max %d target %d increment %.5f decrement %.5f penalty_rate %dpps penalty_burst %dp rehash %dms db %dms",
+		sfb.Limit, sfb.Max, sfb.Target,
+		float64(sfb.Increment)/SFB_MAX_PROB, float64(sfb.Decrement)/SFB_MAX_PROB,
+		sfb.PenaltyRate, sfb.PenaltyBurst, sfb.Rehash, sfb.DB,
+	))
+}
+
+func (qdisc *Sfb) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Sfb) Type() string {
+	return "sfb"
+}
+
+// PlugAction is a command sent to a plug qdisc with QdiscChange.
+type PlugAction int32
+
+const (
+	PLUG_BUFFER             PlugAction = iota // start buffering the packets of a new epoch
+	PLUG_RELEASE_ONE                          // release the packets buffered before the last PLUG_BUFFER
+	PLUG_RELEASE_INDEFINITE                   // release all packets and stop buffering
+	PLUG_LIMIT                                // set the limit to Limit
+)
+
+// Plug buffers packets until it is told to release them, for instance to
+// hold back the output of a VM until its checkpoint is committed. Limit
+// is the size of the buffer in bytes, 0 on creation uses the tx queue
+// length of the link times its MTU. Action is only used by QdiscChange
+// and QdiscReplace of an existing plug, which always send it, and the
+// kernel only takes Limit from a change with PLUG_LIMIT.
+// The kernel does not report the settings of a plug qdisc.
+type Plug struct {
+	QdiscAttrs
+	Action PlugAction
+	Limit  uint32
+}
+
+func (plug *Plug) String() string {
+	if plug.Limit == 0 {
+		return qdiscString(plug)
+	}
+	return qdiscString(plug, fmt.Sprintf("limit %s", formatSize(plug.Limit)))
+}
+
+func (qdisc *Plug) Attrs() *QdiscAttrs {
+	return &qdisc.QdiscAttrs
+}
+
+func (qdisc *Plug) Type() string {
+	return "plug"
+}
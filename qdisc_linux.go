@@ -76,6 +76,67 @@ func NewNetem(attrs QdiscAttrs, nattrs NetemQdiscAttrs) *Netem {
 	}
 }
 
+// String formats the netem qdisc like `tc qdisc show`, with the delay and
+// jitter converted back from ticks.
+// NOTE function is here because it uses other linux functions
+func (netem *Netem) String() string {
+	options := []string{fmt.Sprintf("limit %d", netem.Limit)}
+	if netem.Latency > 0 {
+		delay := fmt.Sprintf("delay %s", formatTime(tick2Time(netem.Latency)))
+		if netem.Jitter > 0 {
+			delay += fmt.Sprintf(" %s", formatTime(tick2Time(netem.Jitter)))
+			if netem.DelayCorr > 0 {
+				delay += fmt.Sprintf(" %s", formatPercent(netem.DelayCorr))
+			}
+		}
+		options = append(options, delay)
+	}
+	if netem.Loss > 0 {
+		loss := fmt.Sprintf("loss %s", formatPercent(netem.Loss))
+		if netem.LossCorr > 0 {
+			loss += fmt.Sprintf(" %s", formatPercent(netem.LossCorr))
+		}
+		options = append(options, loss)
+	}
+	if netem.Duplicate > 0 {
+		duplicate := fmt.Sprintf("duplicate %s", formatPercent(netem.Duplicate))
+		if netem.DuplicateCorr > 0 {
+			duplicate += fmt.Sprintf(" %s", formatPercent(netem.DuplicateCorr))
+		}
+		options = append(options, duplicate)
+	}
+	if netem.ReorderProb > 0 {
+		reorder := fmt.Sprintf("reorder %s", formatPercent(netem.ReorderProb))
+		if netem.ReorderCorr > 0 {
+			reorder += fmt.Sprintf(" %s", formatPercent(netem.ReorderCorr))
+		}
+		options = append(options, reorder)
+	}
+	if netem.CorruptProb > 0 {
+		corrupt := fmt.Sprintf("corrupt %s", formatPercent(netem.CorruptProb))
+		if netem.CorruptCorr > 0 {
+			corrupt += fmt.Sprintf(" %s", formatPercent(netem.CorruptCorr))
+		}
+		options = append(options, corrupt)
+	}
+	if netem.Gap > 0 {
+		options = append(options, fmt.Sprintf("gap %d", netem.Gap))
+	}
+	return qdiscString(netem, options...)
+}
+
+// String formats the tbf qdisc like `tc qdisc show`, with the burst
+// converted back from ticks.
+// NOTE function is here because it uses other linux functions
+func (qdisc *Tbf) String() string {
+	options := []string{fmt.Sprintf("rate %s burst %s", formatRate(qdisc.Rate), formatSize(burst(qdisc.Rate, qdisc.Buffer)))}
+	if qdisc.Peakrate > 0 {
+		options = append(options, fmt.Sprintf("peakrate %s minburst %s", formatRate(qdisc.Peakrate), formatSize(qdisc.Minburst)))
+	}
+	options = append(options, fmt.Sprintf("limit %s", formatSize(qdisc.Limit)))
+	return qdiscString(qdisc, options...)
+}
+
 // QdiscDel will delete a qdisc from the system.
 // Equivalent to: `tc qdisc del $qdisc`
 func QdiscDel(qdisc Qdisc) error {
@@ -134,7 +195,45 @@ func (h *Handle) QdiscAdd(qdisc Qdisc) error {
 		qdisc)
 }
 
+// QdiscAddAndGet will add a qdisc to the system and return the qdisc as
+// echoed by the kernel. A handle allocated by the kernel is also set in the
+// attributes of the passed qdisc.
+// Equivalent to: `tc -echo qdisc add $qdisc`
+func QdiscAddAndGet(qdisc Qdisc) (Qdisc, error) {
+	return pkgHandle.QdiscAddAndGet(qdisc)
+}
+
+// QdiscAddAndGet will add a qdisc to the system and return the qdisc as
+// echoed by the kernel. A handle allocated by the kernel is also set in the
+// attributes of the passed qdisc.
+// Equivalent to: `tc -echo qdisc add $qdisc`
+func (h *Handle) QdiscAddAndGet(qdisc Qdisc) (Qdisc, error) {
+	msgs, err := h.qdiscExecute(
+		unix.RTM_NEWQDISC,
+		unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ECHO,
+		qdisc)
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("kernel did not echo the added qdisc")
+	}
+	res, err := parseQdiscMsg(msgs[0])
+	if err != nil {
+		return nil, err
+	}
+	qdisc.Attrs().Handle = res.Attrs().Handle
+	return res, nil
+}
+
 func (h *Handle) qdiscModify(cmd, flags int, qdisc Qdisc) error {
+	_, err := h.qdiscExecute(cmd, flags, qdisc)
+	return err
+}
+
+// qdiscExecute sends a qdisc request and returns the RTM_NEWQDISC messages
+// echoed by the kernel when flags has NLM_F_ECHO set.
+func (h *Handle) qdiscExecute(cmd, flags int, qdisc Qdisc) ([][]byte, error) {
 	req := h.newNetlinkRequest(cmd, flags|unix.NLM_F_ACK)
 	base := qdisc.Attrs()
 	msg := &nl.TcMsg{
@@ -148,17 +247,26 @@ func (h *Handle) qdiscModify(cmd, flags int, qdisc Qdisc) error {
 	// When deleting don't bother building the rest of the netlink payload
 	if cmd != unix.RTM_DELQDISC {
 		if err := qdiscPayload(req, qdisc); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
-	return err
+	var resType uint16
+	if flags&unix.NLM_F_ECHO != 0 {
+		resType = unix.RTM_NEWQDISC
+	}
+	return req.Execute(unix.NETLINK_ROUTE, resType)
 }
 
 func qdiscPayload(req *nl.NetlinkRequest, qdisc Qdisc) error {
 
 	req.AddData(nl.NewRtAttr(nl.TCA_KIND, nl.ZeroTerminated(qdisc.Type())))
+	if stab := qdisc.Attrs().Stab; stab != nil {
+		req.AddData(stabPayload(stab))
+	}
+	for _, raw := range qdisc.Attrs().RawAttributes {
+		req.AddData(nl.NewRtAttr(int(raw.Type), raw.Value))
+	}
 
 	options := nl.NewRtAttr(nl.TCA_OPTIONS, nil)
 
@@ -169,6 +277,32 @@ func qdiscPayload(req *nl.NetlinkRequest, qdisc Qdisc) error {
 			Priomap: qdisc.PriorityMap,
 		}
 		options = nl.NewRtAttr(nl.TCA_OPTIONS, tcmap.Serialize())
+	case *Pfifo:
+		// without options the kernel uses the tx queue length, a zero
+		// limit would drop everything
+		options = nil
+		if qdisc.Limit > 0 {
+			opt := nl.TcFifoQopt{Limit: qdisc.Limit}
+			options = nl.NewRtAttr(nl.TCA_OPTIONS, opt.Serialize())
+		}
+	case *Bfifo:
+		options = nil
+		if qdisc.Limit > 0 {
+			opt := nl.TcFifoQopt{Limit: qdisc.Limit}
+			options = nl.NewRtAttr(nl.TCA_OPTIONS, opt.Serialize())
+		}
+	case *Plug:
+		// like the fifos a zero limit on creation would drop everything,
+		// without options the kernel derives it from the link. A request
+		// that may reach an existing plug always needs them, they carry
+		// the action.
+		pureCreate := req.Flags&(unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_REPLACE) == unix.NLM_F_CREATE|unix.NLM_F_EXCL
+		if pureCreate && qdisc.Limit == 0 {
+			options = nil
+		} else {
+			opt := nl.TcPlugQopt{Action: int32(qdisc.Action), Limit: qdisc.Limit}
+			options = nl.NewRtAttr(nl.TCA_OPTIONS, opt.Serialize())
+		}
 	case *Tbf:
 		opt := nl.TcTbfQopt{}
 		opt.Rate.Rate = uint32(qdisc.Rate)
@@ -194,7 +328,9 @@ func qdiscPayload(req *nl.NetlinkRequest, qdisc Qdisc) error {
 		opt.Debug = qdisc.Debug
 		opt.DirectPkts = qdisc.DirectPkts
 		options.AddRtAttr(nl.TCA_HTB_INIT, opt.Serialize())
-		// options.AddRtAttr(nl.TCA_HTB_DIRECT_QLEN, opt.Serialize())
+		if qdisc.DirectQlen != nil {
+			options.AddRtAttr(nl.TCA_HTB_DIRECT_QLEN, nl.Uint32Attr(*qdisc.DirectQlen))
+		}
 	case *Hfsc:
 		opt := nl.TcHfscOpt{}
 		opt.Defcls = qdisc.Defcls
@@ -251,6 +387,207 @@ func qdiscPayload(req *nl.NetlinkRequest, qdisc Qdisc) error {
 			options.AddRtAttr(nl.TCA_FQ_CODEL_QUANTUM, nl.Uint32Attr((uint32(qdisc.Quantum))))
 		}
 
+	case *Hhf:
+		if qdisc.Limit > 0 {
+			options.AddRtAttr(nl.TCA_HHF_BACKLOG_LIMIT, nl.Uint32Attr(qdisc.Limit))
+		}
+		if qdisc.Quantum > 0 {
+			options.AddRtAttr(nl.TCA_HHF_QUANTUM, nl.Uint32Attr(qdisc.Quantum))
+		}
+		if qdisc.HHLimit > 0 {
+			options.AddRtAttr(nl.TCA_HHF_HH_FLOWS_LIMIT, nl.Uint32Attr(qdisc.HHLimit))
+		}
+		if qdisc.ResetTimeout > 0 {
+			options.AddRtAttr(nl.TCA_HHF_RESET_TIMEOUT, nl.Uint32Attr(qdisc.ResetTimeout))
+		}
+		if qdisc.AdmitBytes > 0 {
+			options.AddRtAttr(nl.TCA_HHF_ADMIT_BYTES, nl.Uint32Attr(qdisc.AdmitBytes))
+		}
+		if qdisc.EVICTTimeout > 0 {
+			options.AddRtAttr(nl.TCA_HHF_EVICT_TIMEOUT, nl.Uint32Attr(qdisc.EVICTTimeout))
+		}
+		if qdisc.NonHHWeight > 0 {
+			options.AddRtAttr(nl.TCA_HHF_NON_HH_WEIGHT, nl.Uint32Attr(qdisc.NonHHWeight))
+		}
+	case *Sfb:
+		opt := nl.TcSfbQopt{
+			RehashInterval: qdisc.Rehash,
+			WarmupTime:     qdisc.DB,
+			Max:            qdisc.Max,
+			BinSize:        qdisc.Target,
+			Increment:      qdisc.Increment,
+			Decrement:      qdisc.Decrement,
+			Limit:          qdisc.Limit,
+			PenaltyRate:    qdisc.PenaltyRate,
+			PenaltyBurst:   qdisc.PenaltyBurst,
+		}
+		options.AddRtAttr(nl.TCA_SFB_PARMS, opt.Serialize())
+	case *Codel:
+		options.AddRtAttr(nl.TCA_CODEL_ECN, nl.Uint32Attr(qdisc.ECN))
+		if qdisc.Target > 0 {
+			options.AddRtAttr(nl.TCA_CODEL_TARGET, nl.Uint32Attr(qdisc.Target))
+		}
+		if qdisc.Limit > 0 {
+			options.AddRtAttr(nl.TCA_CODEL_LIMIT, nl.Uint32Attr(qdisc.Limit))
+		}
+		if qdisc.Interval > 0 {
+			options.AddRtAttr(nl.TCA_CODEL_INTERVAL, nl.Uint32Attr(qdisc.Interval))
+		}
+		if qdisc.CEThreshold > 0 {
+			options.AddRtAttr(nl.TCA_CODEL_CE_THRESHOLD, nl.Uint32Attr(qdisc.CEThreshold))
+		}
+	case *Pie:
+		if qdisc.Alpha > 32 || qdisc.Beta > 32 {
+			return fmt.Errorf("pie alpha and beta must be in the range 0-32")
+		}
+		options.AddRtAttr(nl.TCA_PIE_ECN, nl.Uint32Attr(qdisc.ECN))
+		options.AddRtAttr(nl.TCA_PIE_BYTEMODE, nl.Uint32Attr(qdisc.Bytemode))
+		if qdisc.Target > 0 {
+			options.AddRtAttr(nl.TCA_PIE_TARGET, nl.Uint32Attr(qdisc.Target))
+		}
+		if qdisc.TUpdate > 0 {
+			options.AddRtAttr(nl.TCA_PIE_TUPDATE, nl.Uint32Attr(qdisc.TUpdate))
+		}
+		if qdisc.Limit > 0 {
+			options.AddRtAttr(nl.TCA_PIE_LIMIT, nl.Uint32Attr(qdisc.Limit))
+		}
+		if qdisc.Alpha > 0 {
+			options.AddRtAttr(nl.TCA_PIE_ALPHA, nl.Uint32Attr(qdisc.Alpha))
+		}
+		if qdisc.Beta > 0 {
+			options.AddRtAttr(nl.TCA_PIE_BETA, nl.Uint32Attr(qdisc.Beta))
+		}
+	case *FqPie:
+		if qdisc.Alpha > 32 || qdisc.Beta > 32 {
+			return fmt.Errorf("fq_pie alpha and beta must be in the range 0-32")
+		}
+		options.AddRtAttr(nl.TCA_FQ_PIE_ECN, nl.Uint32Attr(qdisc.ECN))
+		options.AddRtAttr(nl.TCA_FQ_PIE_BYTEMODE, nl.Uint32Attr(qdisc.Bytemode))
+		if qdisc.Target > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_TARGET, nl.Uint32Attr(qdisc.Target))
+		}
+		if qdisc.TUpdate > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_TUPDATE, nl.Uint32Attr(qdisc.TUpdate))
+		}
+		if qdisc.Limit > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_LIMIT, nl.Uint32Attr(qdisc.Limit))
+		}
+		if qdisc.Flows > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_FLOWS, nl.Uint32Attr(qdisc.Flows))
+		}
+		if qdisc.Alpha > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_ALPHA, nl.Uint32Attr(qdisc.Alpha))
+		}
+		if qdisc.Beta > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_BETA, nl.Uint32Attr(qdisc.Beta))
+		}
+		if qdisc.Quantum > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_QUANTUM, nl.Uint32Attr(qdisc.Quantum))
+		}
+		if qdisc.MemoryLimit > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_MEMORY_LIMIT, nl.Uint32Attr(qdisc.MemoryLimit))
+		}
+		if qdisc.ECNProb > 0 {
+			options.AddRtAttr(nl.TCA_FQ_PIE_ECN_PROB, nl.Uint32Attr(qdisc.ECNProb))
+		}
+	case *Cake:
+		options.AddRtAttr(nl.TCA_CAKE_BASE_RATE64, nl.Uint64Attr(qdisc.Bandwidth))
+		options.AddRtAttr(nl.TCA_CAKE_DIFFSERV_MODE, nl.Uint32Attr(uint32(qdisc.Diffserv)))
+		options.AddRtAttr(nl.TCA_CAKE_AUTORATE, boolUint32Attr(qdisc.AutorateIngress))
+		// kernels without conntrack reject the attribute even when it is 0
+		if qdisc.Nat {
+			options.AddRtAttr(nl.TCA_CAKE_NAT, boolUint32Attr(qdisc.Nat))
+		}
+		options.AddRtAttr(nl.TCA_CAKE_WASH, boolUint32Attr(qdisc.Wash))
+		options.AddRtAttr(nl.TCA_CAKE_INGRESS, boolUint32Attr(qdisc.Ingress))
+		if qdisc.RTT > 0 {
+			options.AddRtAttr(nl.TCA_CAKE_RTT, nl.Uint32Attr(qdisc.RTT))
+		}
+	case *Cbs:
+		if qdisc.Idleslope < 0 || qdisc.Sendslope > 0 {
+			return fmt.Errorf("cbs idleslope must not be negative and sendslope not positive")
+		}
+		opt := nl.TcCbsQopt{
+			Hicredit:  qdisc.Hicredit,
+			Locredit:  qdisc.Locredit,
+			Idleslope: qdisc.Idleslope,
+			Sendslope: qdisc.Sendslope,
+		}
+		if qdisc.Offload {
+			opt.Offload = 1
+		}
+		options.AddRtAttr(nl.TCA_CBS_PARMS, opt.Serialize())
+	case *Etf:
+		if qdisc.Delta < 0 {
+			return fmt.Errorf("etf delta must not be negative")
+		}
+		opt := nl.TcEtfQopt{
+			Delta:   qdisc.Delta,
+			Clockid: qdisc.ClockId,
+		}
+		if qdisc.Deadline {
+			opt.Flags |= nl.TC_ETF_DEADLINE_MODE_ON
+		}
+		if qdisc.Offload {
+			opt.Flags |= nl.TC_ETF_OFFLOAD_ON
+		}
+		if qdisc.SkipSockCheck {
+			opt.Flags |= nl.TC_ETF_SKIP_SOCK_CHECK
+		}
+		options.AddRtAttr(nl.TCA_ETF_PARMS, opt.Serialize())
+	case *Multiq:
+		opt := nl.TcMultiqQopt{
+			Bands: qdisc.Bands,
+		}
+		options = nl.NewRtAttr(nl.TCA_OPTIONS, opt.Serialize())
+	case *Mqprio:
+		if qdisc.NumTc > nl.TC_QOPT_MAX_QUEUE {
+			return fmt.Errorf("mqprio supports at most %d traffic classes", nl.TC_QOPT_MAX_QUEUE)
+		}
+		opt := nl.TcMqprioQopt{
+			NumTc:     qdisc.NumTc,
+			PrioTcMap: qdisc.PrioTcMap,
+			Count:     qdisc.Count,
+			Offset:    qdisc.Offset,
+		}
+		if qdisc.HwOffload {
+			opt.Hw = nl.TC_MQPRIO_HW_OFFLOAD_TCS
+		}
+		options = nl.NewRtAttr(nl.TCA_OPTIONS, opt.Serialize())
+	case *Taprio:
+		if qdisc.NumTc > nl.TC_QOPT_MAX_QUEUE {
+			return fmt.Errorf("taprio supports at most %d traffic classes", nl.TC_QOPT_MAX_QUEUE)
+		}
+		opt := nl.TcMqprioQopt{
+			NumTc:     qdisc.NumTc,
+			PrioTcMap: qdisc.PrioTcMap,
+			Count:     qdisc.Count,
+			Offset:    qdisc.Offset,
+		}
+		options.AddRtAttr(nl.TCA_TAPRIO_ATTR_PRIOMAP, opt.Serialize())
+		if len(qdisc.Schedule) > 0 {
+			list := options.AddRtAttr(nl.TCA_TAPRIO_ATTR_SCHED_ENTRY_LIST, nil)
+			for _, e := range qdisc.Schedule {
+				if e.Interval == 0 {
+					return fmt.Errorf("taprio schedule entries need a non-zero interval")
+				}
+				entry := list.AddRtAttr(nl.TCA_TAPRIO_SCHED_ENTRY, nil)
+				entry.AddRtAttr(nl.TCA_TAPRIO_SCHED_ENTRY_CMD, nl.Uint8Attr(e.Command))
+				entry.AddRtAttr(nl.TCA_TAPRIO_SCHED_ENTRY_GATE_MASK, nl.Uint32Attr(e.GateMask))
+				entry.AddRtAttr(nl.TCA_TAPRIO_SCHED_ENTRY_INTERVAL, nl.Uint32Attr(e.Interval))
+			}
+		}
+		options.AddRtAttr(nl.TCA_TAPRIO_ATTR_SCHED_BASE_TIME, nl.Uint64Attr(uint64(qdisc.BaseTime)))
+		if qdisc.CycleTime > 0 {
+			options.AddRtAttr(nl.TCA_TAPRIO_ATTR_SCHED_CYCLE_TIME, nl.Uint64Attr(uint64(qdisc.CycleTime)))
+		}
+		// the clock is only accepted for schedules run in software
+		if qdisc.Flags&TAPRIO_FLAG_FULL_OFFLOAD == 0 {
+			options.AddRtAttr(nl.TCA_TAPRIO_ATTR_SCHED_CLOCKID, nl.Uint32Attr(uint32(qdisc.ClockId)))
+		}
+		if qdisc.Flags != 0 {
+			options.AddRtAttr(nl.TCA_TAPRIO_ATTR_FLAGS, nl.Uint32Attr(qdisc.Flags))
+		}
 	case *Fq:
 		options.AddRtAttr(nl.TCA_FQ_RATE_ENABLE, nl.Uint32Attr((uint32(qdisc.Pacing))))
 
@@ -319,114 +656,326 @@ func (h *Handle) QdiscList(link Link) ([]Qdisc, error) {
 
 	var res []Qdisc
 	for _, m := range msgs {
-		msg := nl.DeserializeTcMsg(m)
-
-		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+		// skip qdiscs from other interfaces
+		if link != nil && nl.DeserializeTcMsg(m).Ifindex != index {
+			continue
+		}
+		qdisc, err := parseQdiscMsg(m)
 		if err != nil {
 			return nil, err
 		}
+		res = append(res, qdisc)
+	}
 
-		// skip qdiscs from other interfaces
-		if link != nil && msg.Ifindex != index {
-			continue
+	return res, nil
+}
+
+// QdiscListByType gets a list of qdiscs of the given kind, e.g. "netem".
+// Equivalent to: `tc qdisc show`, filtered by kind.
+// The list can be filtered by link.
+func QdiscListByType(link Link, kind string) ([]Qdisc, error) {
+	return pkgHandle.QdiscListByType(link, kind)
+}
+
+// QdiscListByType gets a list of qdiscs of the given kind, e.g. "netem".
+// Equivalent to: `tc qdisc show`, filtered by kind.
+// The list can be filtered by link.
+func (h *Handle) QdiscListByType(link Link, kind string) ([]Qdisc, error) {
+	qdiscs, err := h.QdiscList(link)
+	if err != nil {
+		return nil, err
+	}
+	var res []Qdisc
+	for _, qdisc := range qdiscs {
+		if qdisc.Type() == kind {
+			res = append(res, qdisc)
 		}
+	}
+	return res, nil
+}
 
-		base := QdiscAttrs{
-			LinkIndex: int(msg.Ifindex),
-			Handle:    msg.Handle,
-			Parent:    msg.Parent,
-			Refcnt:    msg.Info,
-		}
-		var qdisc Qdisc
-		qdiscType := ""
-		for _, attr := range attrs {
-			switch attr.Attr.Type {
-			case nl.TCA_KIND:
-				qdiscType = string(attr.Value[:len(attr.Value)-1])
-				switch qdiscType {
-				case "pfifo_fast":
-					qdisc = &PfifoFast{}
-				case "prio":
-					qdisc = &Prio{}
-				case "tbf":
-					qdisc = &Tbf{}
-				case "ingress":
-					qdisc = &Ingress{}
-				case "htb":
-					qdisc = &Htb{}
-				case "fq":
-					qdisc = &Fq{}
-				case "hfsc":
-					qdisc = &Hfsc{}
-				case "fq_codel":
-					qdisc = &FqCodel{}
-				case "netem":
-					qdisc = &Netem{}
-				default:
-					qdisc = &GenericQdisc{QdiscType: qdiscType}
-				}
-			case nl.TCA_OPTIONS:
-				switch qdiscType {
-				case "pfifo_fast":
-					// pfifo returns TcPrioMap directly without wrapping it in rtattr
-					if err := parsePfifoFastData(qdisc, attr.Value); err != nil {
-						return nil, err
-					}
-				case "prio":
-					// prio returns TcPrioMap directly without wrapping it in rtattr
-					if err := parsePrioData(qdisc, attr.Value); err != nil {
-						return nil, err
-					}
-				case "tbf":
-					data, err := nl.ParseRouteAttr(attr.Value)
-					if err != nil {
-						return nil, err
-					}
-					if err := parseTbfData(qdisc, data); err != nil {
-						return nil, err
-					}
-				case "hfsc":
-					if err := parseHfscData(qdisc, attr.Value); err != nil {
-						return nil, err
-					}
-				case "htb":
-					data, err := nl.ParseRouteAttr(attr.Value)
-					if err != nil {
-						return nil, err
-					}
-					if err := parseHtbData(qdisc, data); err != nil {
-						return nil, err
-					}
-				case "fq":
-					data, err := nl.ParseRouteAttr(attr.Value)
-					if err != nil {
-						return nil, err
-					}
-					if err := parseFqData(qdisc, data); err != nil {
-						return nil, err
-					}
-				case "fq_codel":
-					data, err := nl.ParseRouteAttr(attr.Value)
-					if err != nil {
-						return nil, err
-					}
-					if err := parseFqCodelData(qdisc, data); err != nil {
-						return nil, err
-					}
-				case "netem":
-					if err := parseNetemData(qdisc, attr.Value); err != nil {
-						return nil, err
-					}
+// parseQdiscMsg decodes a RTM_NEWQDISC message into a Qdisc.
+// qdiscTypes maps the kind of a qdisc to a constructor of its type. Other
+// kinds are decoded as a GenericQdisc.
+var qdiscTypes = map[string]func() Qdisc{
+	"pfifo_fast": func() Qdisc { return &PfifoFast{} },
+	"pfifo":      func() Qdisc { return &Pfifo{} },
+	"bfifo":      func() Qdisc { return &Bfifo{} },
+	"prio":       func() Qdisc { return &Prio{} },
+	"tbf":        func() Qdisc { return &Tbf{} },
+	"ingress":    func() Qdisc { return &Ingress{} },
+	"htb":        func() Qdisc { return &Htb{} },
+	"fq":         func() Qdisc { return &Fq{} },
+	"hfsc":       func() Qdisc { return &Hfsc{} },
+	"fq_codel":   func() Qdisc { return &FqCodel{} },
+	"cake":       func() Qdisc { return &Cake{} },
+	"codel":      func() Qdisc { return &Codel{} },
+	"hhf":        func() Qdisc { return &Hhf{} },
+	"sfb":        func() Qdisc { return &Sfb{} },
+	"pie":        func() Qdisc { return &Pie{} },
+	"fq_pie":     func() Qdisc { return &FqPie{} },
+	"cbs":        func() Qdisc { return &Cbs{} },
+	"plug":       func() Qdisc { return &Plug{} },
+	"etf":        func() Qdisc { return &Etf{} },
+	"multiq":     func() Qdisc { return &Multiq{} },
+	"mqprio":     func() Qdisc { return &Mqprio{} },
+	"taprio":     func() Qdisc { return &Taprio{} },
+	"netem":      func() Qdisc { return &Netem{} },
+}
+
+func newQdiscOfType(kind string) Qdisc {
+	if newQdisc, ok := qdiscTypes[kind]; ok {
+		return newQdisc()
+	}
+	return &GenericQdisc{QdiscType: kind}
+}
+
+func parseQdiscMsg(m []byte) (Qdisc, error) {
+	msg := nl.DeserializeTcMsg(m)
 
-					// no options for ingress
+	attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+	if err != nil {
+		return nil, err
+	}
+
+	base := QdiscAttrs{
+		LinkIndex: int(msg.Ifindex),
+		Handle:    msg.Handle,
+		Parent:    msg.Parent,
+		Refcnt:    msg.Info,
+	}
+	var qdisc Qdisc
+	qdiscType := ""
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.TCA_KIND:
+			qdiscType = string(attr.Value[:len(attr.Value)-1])
+			qdisc = newQdiscOfType(qdiscType)
+		case nl.TCA_OPTIONS:
+			switch qdiscType {
+			case "pfifo_fast":
+				// pfifo returns TcPrioMap directly without wrapping it in rtattr
+				if err := parsePfifoFastData(qdisc, attr.Value); err != nil {
+					return nil, err
+				}
+			case "pfifo", "bfifo":
+				// fifos return tc_fifo_qopt directly without wrapping it in rtattr
+				if err := parseFifoData(qdisc, attr.Value); err != nil {
+					return nil, err
+				}
+			case "prio":
+				// prio returns TcPrioMap directly without wrapping it in rtattr
+				if err := parsePrioData(qdisc, attr.Value); err != nil {
+					return nil, err
+				}
+			case "tbf":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseTbfData(qdisc, data); err != nil {
+					return nil, err
 				}
+			case "hfsc":
+				if err := parseHfscData(qdisc, attr.Value); err != nil {
+					return nil, err
+				}
+			case "htb":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseHtbData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "fq":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseFqData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "fq_codel":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseFqCodelData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "codel":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseCodelData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "hhf":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseHhfData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "sfb":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseSfbData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "pie":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parsePieData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "fq_pie":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseFqPieData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "cake":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseCakeData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "cbs":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseCbsData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "etf":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseEtfData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "multiq":
+				// multiq returns TcMultiqQopt directly without wrapping it in rtattr
+				if err := parseMultiqData(qdisc, attr.Value); err != nil {
+					return nil, err
+				}
+			case "mqprio":
+				// mqprio returns TcMqprioQopt directly, optional attributes follow it
+				if err := parseMqprioData(qdisc, attr.Value); err != nil {
+					return nil, err
+				}
+			case "taprio":
+				data, err := nl.ParseRouteAttr(attr.Value)
+				if err != nil {
+					return nil, err
+				}
+				if err := parseTaprioData(qdisc, data); err != nil {
+					return nil, err
+				}
+			case "netem":
+				if err := parseNetemData(qdisc, attr.Value); err != nil {
+					return nil, err
+				}
+
+				// no options for ingress
+			}
+		case nl.TCA_STAB:
+			stab, err := parseStab(attr.Value)
+			if err != nil {
+				return nil, err
 			}
+			base.Stab = stab
+		case nl.TCA_XSTATS:
+			switch qdisc := qdisc.(type) {
+			case *Codel:
+				qdisc.XStats = parseCodelXStats(attr.Value)
+			case *Hhf:
+				qdisc.XStats = parseHhfXStats(attr.Value)
+			case *Sfb:
+				qdisc.XStats = parseSfbXStats(attr.Value)
+			}
+		case nl.TCA_STATS, nl.TCA_STATS2, nl.TCA_HW_OFFLOAD:
+			// Read only, rejected or ignored when sent back
+		default:
+			value := make([]byte, len(attr.Value))
+			copy(value, attr.Value)
+			base.RawAttributes = append(base.RawAttributes, RawAttribute{Type: attr.Attr.Type, Value: value})
 		}
-		*qdisc.Attrs() = base
-		res = append(res, qdisc)
 	}
+	*qdisc.Attrs() = base
+	return qdisc, nil
+}
 
-	return res, nil
+// stabPayload builds the size table attribute the way tc does, the table
+// is left out when it would only add the overhead.
+func stabPayload(stab *Stab) *nl.RtAttr {
+	native = nl.NativeEndian()
+	attr := nl.NewRtAttr(nl.TCA_STAB, nil)
+	spec := nl.TcSizeSpec{
+		Overhead:  stab.Overhead,
+		Linklayer: uint32(stab.LinkLayer),
+		Mpu:       stab.Mpu,
+	}
+	if stab.LinkLayer <= nl.LINKLAYER_ETHERNET && stab.Mpu == 0 {
+		attr.AddRtAttr(nl.TCA_STAB_BASE, spec.Serialize())
+		return attr
+	}
+	spec.Mtu = stab.Mtu
+	if spec.Mtu == 0 {
+		spec.Mtu = 2047
+	}
+	spec.Tsize = stab.Tsize
+	if spec.Tsize == 0 {
+		spec.Tsize = 512
+	}
+	for spec.Mtu>>spec.CellLog > spec.Tsize-1 {
+		spec.CellLog++
+	}
+	spec.CellAlign = -1
+	table := make([]byte, 2*spec.Tsize)
+	for i := uint32(0); i < spec.Tsize; i++ {
+		sz := AdjustSize(uint(i+1)<<spec.CellLog, uint(spec.Mpu), stab.LinkLayer)
+		native.PutUint16(table[2*i:], uint16(sz>>spec.SizeLog))
+	}
+	attr.AddRtAttr(nl.TCA_STAB_BASE, spec.Serialize())
+	attr.AddRtAttr(nl.TCA_STAB_DATA, table)
+	return attr
+}
+
+// parseStab reads the size table settings, the kernel does not send the
+// table itself.
+func parseStab(b []byte) (*Stab, error) {
+	attrs, err := nl.ParseRouteAttr(b)
+	if err != nil {
+		return nil, err
+	}
+	stab := &Stab{}
+	for _, attr := range attrs {
+		if attr.Attr.Type != nl.TCA_STAB_BASE || len(attr.Value) < nl.SizeofTcSizeSpec {
+			continue
+		}
+		spec := nl.DeserializeTcSizeSpec(attr.Value)
+		stab.Overhead = spec.Overhead
+		stab.LinkLayer = int(spec.Linklayer)
+		stab.Mpu = spec.Mpu
+		stab.Mtu = spec.Mtu
+		stab.Tsize = spec.Tsize
+	}
+	return stab, nil
 }
 
 func parsePfifoFastData(qdisc Qdisc, value []byte) error {
@@ -437,6 +986,137 @@ func parsePfifoFastData(qdisc Qdisc, value []byte) error {
 	return nil
 }
 
+func parseFifoData(qdisc Qdisc, value []byte) error {
+	if len(value) < nl.SizeofTcFifoQopt {
+		return nil
+	}
+	opt := nl.DeserializeTcFifoQopt(value)
+	switch fifo := qdisc.(type) {
+	case *Pfifo:
+		fifo.Limit = opt.Limit
+	case *Bfifo:
+		fifo.Limit = opt.Limit
+	}
+	return nil
+}
+
+func parseCbsData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	cbs := qdisc.(*Cbs)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_CBS_PARMS:
+			if len(datum.Value) < nl.SizeofTcCbsQopt {
+				continue
+			}
+			opt := nl.DeserializeTcCbsQopt(datum.Value)
+			cbs.Hicredit = opt.Hicredit
+			cbs.Locredit = opt.Locredit
+			cbs.Idleslope = opt.Idleslope
+			cbs.Sendslope = opt.Sendslope
+			cbs.Offload = opt.Offload != 0
+		}
+	}
+	return nil
+}
+
+func parseEtfData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	etf := qdisc.(*Etf)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_ETF_PARMS:
+			if len(datum.Value) < nl.SizeofTcEtfQopt {
+				continue
+			}
+			opt := nl.DeserializeTcEtfQopt(datum.Value)
+			etf.Delta = opt.Delta
+			etf.ClockId = opt.Clockid
+			etf.Deadline = opt.Flags&nl.TC_ETF_DEADLINE_MODE_ON != 0
+			etf.Offload = opt.Flags&nl.TC_ETF_OFFLOAD_ON != 0
+			etf.SkipSockCheck = opt.Flags&nl.TC_ETF_SKIP_SOCK_CHECK != 0
+		}
+	}
+	return nil
+}
+
+func parseMultiqData(qdisc Qdisc, value []byte) error {
+	multiq := qdisc.(*Multiq)
+	if len(value) < nl.SizeofTcMultiqQopt {
+		return fmt.Errorf("multiq options too short: %d bytes", len(value))
+	}
+	opt := nl.DeserializeTcMultiqQopt(value)
+	multiq.Bands = opt.Bands
+	multiq.MaxBands = opt.MaxBands
+	return nil
+}
+
+func parseMqprioData(qdisc Qdisc, value []byte) error {
+	mqprio := qdisc.(*Mqprio)
+	if len(value) < nl.SizeofTcMqprioQopt {
+		return fmt.Errorf("mqprio options too short: %d bytes", len(value))
+	}
+	opt := nl.DeserializeTcMqprioQopt(value)
+	mqprio.NumTc = opt.NumTc
+	mqprio.PrioTcMap = opt.PrioTcMap
+	mqprio.Count = opt.Count
+	mqprio.Offset = opt.Offset
+	mqprio.HwOffload = opt.Hw != nl.TC_MQPRIO_HW_OFFLOAD_NONE
+	return nil
+}
+
+func parseTaprioData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	native = nl.NativeEndian()
+	taprio := qdisc.(*Taprio)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_TAPRIO_ATTR_PRIOMAP:
+			if len(datum.Value) < nl.SizeofTcMqprioQopt {
+				continue
+			}
+			opt := nl.DeserializeTcMqprioQopt(datum.Value)
+			taprio.NumTc = opt.NumTc
+			taprio.PrioTcMap = opt.PrioTcMap
+			taprio.Count = opt.Count
+			taprio.Offset = opt.Offset
+		case nl.TCA_TAPRIO_ATTR_SCHED_ENTRY_LIST:
+			entries, err := nl.ParseRouteAttr(datum.Value)
+			if err != nil {
+				return err
+			}
+			taprio.Schedule = nil
+			for _, entry := range entries {
+				if entry.Attr.Type != nl.TCA_TAPRIO_SCHED_ENTRY {
+					continue
+				}
+				attrs, err := nl.ParseRouteAttr(entry.Value)
+				if err != nil {
+					return err
+				}
+				var e TaprioEntry
+				for _, attr := range attrs {
+					switch attr.Attr.Type {
+					case nl.TCA_TAPRIO_SCHED_ENTRY_CMD:
+						e.Command = attr.Value[0]
+					case nl.TCA_TAPRIO_SCHED_ENTRY_GATE_MASK:
+						e.GateMask = native.Uint32(attr.Value)
+					case nl.TCA_TAPRIO_SCHED_ENTRY_INTERVAL:
+						e.Interval = native.Uint32(attr.Value)
+					}
+				}
+				taprio.Schedule = append(taprio.Schedule, e)
+			}
+		case nl.TCA_TAPRIO_ATTR_SCHED_BASE_TIME:
+			taprio.BaseTime = int64(native.Uint64(datum.Value))
+		case nl.TCA_TAPRIO_ATTR_SCHED_CYCLE_TIME:
+			taprio.CycleTime = int64(native.Uint64(datum.Value))
+		case nl.TCA_TAPRIO_ATTR_SCHED_CLOCKID:
+			taprio.ClockId = int32(native.Uint32(datum.Value))
+		case nl.TCA_TAPRIO_ATTR_FLAGS:
+			taprio.Flags = native.Uint32(datum.Value)
+		}
+	}
+	return nil
+}
+
 func parsePrioData(qdisc Qdisc, value []byte) error {
 	prio := qdisc.(*Prio)
 	tcmap := nl.DeserializeTcPrioMap(value)
@@ -458,8 +1138,210 @@ func parseHtbData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
 			htb.Debug = opt.Debug
 			htb.DirectPkts = opt.DirectPkts
 		case nl.TCA_HTB_DIRECT_QLEN:
-			// TODO
-			//htb.DirectQlen = native.uint32(datum.Value)
+			directQlen := native.Uint32(datum.Value)
+			htb.DirectQlen = &directQlen
+		}
+	}
+	return nil
+}
+
+// boolUint32Attr encodes a flag that the kernel expects as a u32.
+func boolUint32Attr(val bool) []byte {
+	var v uint32
+	if val {
+		v = 1
+	}
+	return nl.Uint32Attr(v)
+}
+
+func parseCodelData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	native = nl.NativeEndian()
+	codel := qdisc.(*Codel)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_CODEL_TARGET:
+			codel.Target = native.Uint32(datum.Value)
+		case nl.TCA_CODEL_LIMIT:
+			codel.Limit = native.Uint32(datum.Value)
+		case nl.TCA_CODEL_INTERVAL:
+			codel.Interval = native.Uint32(datum.Value)
+		case nl.TCA_CODEL_ECN:
+			codel.ECN = native.Uint32(datum.Value)
+		case nl.TCA_CODEL_CE_THRESHOLD:
+			codel.CEThreshold = native.Uint32(datum.Value)
+		}
+	}
+	return nil
+}
+
+func parseHhfData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	native = nl.NativeEndian()
+	hhf := qdisc.(*Hhf)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_HHF_BACKLOG_LIMIT:
+			hhf.Limit = native.Uint32(datum.Value)
+		case nl.TCA_HHF_QUANTUM:
+			hhf.Quantum = native.Uint32(datum.Value)
+		case nl.TCA_HHF_HH_FLOWS_LIMIT:
+			hhf.HHLimit = native.Uint32(datum.Value)
+		case nl.TCA_HHF_RESET_TIMEOUT:
+			hhf.ResetTimeout = native.Uint32(datum.Value)
+		case nl.TCA_HHF_ADMIT_BYTES:
+			hhf.AdmitBytes = native.Uint32(datum.Value)
+		case nl.TCA_HHF_EVICT_TIMEOUT:
+			hhf.EVICTTimeout = native.Uint32(datum.Value)
+		case nl.TCA_HHF_NON_HH_WEIGHT:
+			hhf.NonHHWeight = native.Uint32(datum.Value)
+		}
+	}
+	return nil
+}
+
+func parseHhfXStats(value []byte) *HhfXStats {
+	if len(value) < nl.SizeofTcHhfXstats {
+		return nil
+	}
+	x := nl.DeserializeTcHhfXstats(value)
+	return &HhfXStats{
+		DropOverlimit: x.DropOverlimit,
+		HHOverlimit:   x.HhOverlimit,
+		HHTotCount:    x.HhTotCount,
+		HHCurCount:    x.HhCurCount,
+	}
+}
+
+func parseSfbData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	sfb := qdisc.(*Sfb)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_SFB_PARMS:
+			if len(datum.Value) < nl.SizeofTcSfbQopt {
+				continue
+			}
+			opt := nl.DeserializeTcSfbQopt(datum.Value)
+			sfb.Rehash = opt.RehashInterval
+			sfb.DB = opt.WarmupTime
+			sfb.Max = opt.Max
+			sfb.Target = opt.BinSize
+			sfb.Increment = opt.Increment
+			sfb.Decrement = opt.Decrement
+			sfb.Limit = opt.Limit
+			sfb.PenaltyRate = opt.PenaltyRate
+			sfb.PenaltyBurst = opt.PenaltyBurst
+		}
+	}
+	return nil
+}
+
+func parseSfbXStats(value []byte) *SfbXStats {
+	if len(value) < nl.SizeofTcSfbXstats {
+		return nil
+	}
+	x := nl.DeserializeTcSfbXstats(value)
+	return &SfbXStats{
+		EarlyDrop:   x.Earlydrop,
+		PenaltyDrop: x.Penaltydrop,
+		BucketDrop:  x.Bucketdrop,
+		QueueDrop:   x.Queuedrop,
+		ChildDrop:   x.Childdrop,
+		Marked:      x.Marked,
+		MaxQlen:     x.Maxqlen,
+		MaxProb:     x.Maxprob,
+		AvgProb:     x.Avgprob,
+	}
+}
+
+func parsePieData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	native = nl.NativeEndian()
+	pie := qdisc.(*Pie)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_PIE_TARGET:
+			pie.Target = native.Uint32(datum.Value)
+		case nl.TCA_PIE_TUPDATE:
+			pie.TUpdate = native.Uint32(datum.Value)
+		case nl.TCA_PIE_LIMIT:
+			pie.Limit = native.Uint32(datum.Value)
+		case nl.TCA_PIE_ALPHA:
+			pie.Alpha = native.Uint32(datum.Value)
+		case nl.TCA_PIE_BETA:
+			pie.Beta = native.Uint32(datum.Value)
+		case nl.TCA_PIE_ECN:
+			pie.ECN = native.Uint32(datum.Value)
+		case nl.TCA_PIE_BYTEMODE:
+			pie.Bytemode = native.Uint32(datum.Value)
+		}
+	}
+	return nil
+}
+
+func parseFqPieData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	native = nl.NativeEndian()
+	fqPie := qdisc.(*FqPie)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_FQ_PIE_TARGET:
+			fqPie.Target = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_TUPDATE:
+			fqPie.TUpdate = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_LIMIT:
+			fqPie.Limit = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_FLOWS:
+			fqPie.Flows = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_ALPHA:
+			fqPie.Alpha = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_BETA:
+			fqPie.Beta = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_QUANTUM:
+			fqPie.Quantum = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_MEMORY_LIMIT:
+			fqPie.MemoryLimit = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_ECN_PROB:
+			fqPie.ECNProb = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_ECN:
+			fqPie.ECN = native.Uint32(datum.Value)
+		case nl.TCA_FQ_PIE_BYTEMODE:
+			fqPie.Bytemode = native.Uint32(datum.Value)
+		}
+	}
+	return nil
+}
+
+func parseCodelXStats(value []byte) *CodelXStats {
+	x := nl.DeserializeTcCodelXstats(value)
+	return &CodelXStats{
+		MaxPacket:     x.Maxpacket,
+		Count:         x.Count,
+		LastCount:     x.Lastcount,
+		LDelay:        x.Ldelay,
+		DropNext:      x.DropNext,
+		DropOverlimit: x.DropOverlimit,
+		EcnMark:       x.EcnMark,
+		Dropping:      x.Dropping != 0,
+		CeMark:        x.CeMark,
+	}
+}
+
+func parseCakeData(qdisc Qdisc, data []syscall.NetlinkRouteAttr) error {
+	native = nl.NativeEndian()
+	cake := qdisc.(*Cake)
+	for _, datum := range data {
+		switch datum.Attr.Type {
+		case nl.TCA_CAKE_BASE_RATE64:
+			cake.Bandwidth = native.Uint64(datum.Value)
+		case nl.TCA_CAKE_DIFFSERV_MODE:
+			cake.Diffserv = CakeDiffserv(native.Uint32(datum.Value))
+		case nl.TCA_CAKE_AUTORATE:
+			cake.AutorateIngress = native.Uint32(datum.Value) != 0
+		case nl.TCA_CAKE_NAT:
+			cake.Nat = native.Uint32(datum.Value) != 0
+		case nl.TCA_CAKE_WASH:
+			cake.Wash = native.Uint32(datum.Value) != 0
+		case nl.TCA_CAKE_INGRESS:
+			cake.Ingress = native.Uint32(datum.Value) != 0
+		case nl.TCA_CAKE_RTT:
+			cake.RTT = native.Uint32(datum.Value)
 		}
 	}
 	return nil
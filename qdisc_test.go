@@ -3,7 +3,11 @@
 package netlink
 
 import (
+	"reflect"
 	"testing"
+
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
 )
 
 func TestTbfAddDel(t *testing.T) {
@@ -495,3 +499,965 @@ func TestIngressAddDel(t *testing.T) {
 		t.Fatal("Failed to remove qdisc")
 	}
 }
+
+func TestQdiscListByType(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	htb := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	if err := QdiscAdd(htb); err != nil {
+		t.Fatal(err)
+	}
+	ingress := &Ingress{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Parent:    HANDLE_INGRESS,
+		},
+	}
+	if err := QdiscAdd(ingress); err != nil {
+		t.Fatal(err)
+	}
+
+	qdiscs, err := QdiscListByType(link, "htb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatalf("Expected 1 htb qdisc, got %d", len(qdiscs))
+	}
+	if _, ok := qdiscs[0].(*Htb); !ok {
+		t.Fatal("Qdisc is the wrong type")
+	}
+	qdiscs, err = QdiscListByType(link, "netem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 0 {
+		t.Fatalf("Expected no netem qdiscs, got %d", len(qdiscs))
+	}
+}
+
+func TestCakeAddChangeDel(t *testing.T) {
+	minKernelRequired(t, 4, 19)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewCake(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	qdisc.Bandwidth = 1250000
+	qdisc.Diffserv = CAKE_DIFFSERV_DIFFSERV4
+	qdisc.AutorateIngress = true
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	cake, ok := qdiscs[0].(*Cake)
+	if !ok {
+		t.Fatal("Qdisc is the wrong type")
+	}
+	if cake.Bandwidth != qdisc.Bandwidth {
+		t.Fatal("Bandwidth does not match")
+	}
+	if cake.Diffserv != qdisc.Diffserv {
+		t.Fatalf("Diffserv %s does not match %s", cake.Diffserv, qdisc.Diffserv)
+	}
+	if !cake.AutorateIngress {
+		t.Fatal("AutorateIngress is not enabled")
+	}
+
+	qdisc.Diffserv = CAKE_DIFFSERV_BESTEFFORT
+	qdisc.AutorateIngress = false
+	if err := QdiscChange(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cake = qdiscs[0].(*Cake)
+	if cake.Diffserv != CAKE_DIFFSERV_BESTEFFORT || cake.AutorateIngress {
+		t.Fatalf("Qdisc not changed: %s", cake)
+	}
+
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 0 {
+		t.Fatal("Failed to remove qdisc")
+	}
+}
+
+func TestFifoAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	attrs := QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	}
+	for _, qdisc := range []Qdisc{
+		&Pfifo{QdiscAttrs: attrs, Limit: 100},
+		&Bfifo{QdiscAttrs: attrs, Limit: 150000},
+	} {
+		if err := QdiscAdd(qdisc); err != nil {
+			t.Fatal(err)
+		}
+		qdiscs, err := SafeQdiscList(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(qdiscs) != 1 {
+			t.Fatal("Failed to add qdisc")
+		}
+		switch qdisc := qdisc.(type) {
+		case *Pfifo:
+			pfifo, ok := qdiscs[0].(*Pfifo)
+			if !ok {
+				t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+			}
+			if pfifo.Limit != qdisc.Limit {
+				t.Fatalf("Limit %d does not match %d", pfifo.Limit, qdisc.Limit)
+			}
+		case *Bfifo:
+			bfifo, ok := qdiscs[0].(*Bfifo)
+			if !ok {
+				t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+			}
+			if bfifo.Limit != qdisc.Limit {
+				t.Fatalf("Limit %d does not match %d", bfifo.Limit, qdisc.Limit)
+			}
+		}
+
+		if err := QdiscDel(qdisc); err != nil {
+			t.Fatal(err)
+		}
+		// the bridge falls back to its default noqueue qdisc
+		qdiscs, err = QdiscListByType(link, qdisc.Type())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(qdiscs) != 0 {
+			t.Fatal("Failed to remove qdisc")
+		}
+	}
+}
+
+func TestCodelAddChangeDel(t *testing.T) {
+	minKernelRequired(t, 3, 5)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Codel{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+		Target:      4000,
+		Limit:       500,
+		Interval:    80000,
+		ECN:         1,
+		CEThreshold: 2000,
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	codel, ok := qdiscs[0].(*Codel)
+	if !ok {
+		t.Fatal("Qdisc is the wrong type")
+	}
+	if codel.Target != qdisc.Target || codel.Limit != qdisc.Limit || codel.Interval != qdisc.Interval ||
+		codel.ECN != qdisc.ECN || codel.CEThreshold != qdisc.CEThreshold {
+		t.Fatalf("Qdisc %s does not match %s", codel, qdisc)
+	}
+	if codel.XStats == nil {
+		t.Fatal("Codel xstats are missing")
+	}
+
+	qdisc.ECN = 0
+	if err := QdiscChange(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codel := qdiscs[0].(*Codel); codel.ECN != 0 {
+		t.Fatal("ECN was not disabled")
+	}
+
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 0 {
+		t.Fatal("Failed to remove qdisc")
+	}
+}
+
+func TestHhfAddDel(t *testing.T) {
+	minKernelRequired(t, 3, 15)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Hhf{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+		Limit:        1000,
+		Quantum:      1514,
+		HHLimit:      1024,
+		ResetTimeout: 40000,
+		AdmitBytes:   131072,
+		EVICTTimeout: 1000000,
+		NonHHWeight:  2,
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	hhf, ok := qdiscs[0].(*Hhf)
+	if !ok {
+		t.Fatal("Qdisc is the wrong type")
+	}
+	if hhf.Limit != qdisc.Limit || hhf.Quantum != qdisc.Quantum || hhf.HHLimit != qdisc.HHLimit ||
+		hhf.ResetTimeout != qdisc.ResetTimeout || hhf.AdmitBytes != qdisc.AdmitBytes ||
+		hhf.EVICTTimeout != qdisc.EVICTTimeout || hhf.NonHHWeight != qdisc.NonHHWeight {
+		t.Fatalf("Qdisc %s does not match %s", hhf, qdisc)
+	}
+	if hhf.XStats == nil {
+		t.Fatal("Hhf xstats are missing")
+	}
+
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 0 {
+		t.Fatal("Failed to remove qdisc")
+	}
+}
+
+func TestSfbAddDel(t *testing.T) {
+	minKernelRequired(t, 3, 0)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewSfb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	qdisc.Limit = 1000
+	qdisc.Target = 30
+	qdisc.PenaltyRate = 20
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	sfb, ok := qdiscs[0].(*Sfb)
+	if !ok {
+		t.Fatal("Qdisc is the wrong type")
+	}
+	if sfb.Rehash != qdisc.Rehash || sfb.DB != qdisc.DB || sfb.Max != qdisc.Max || sfb.Target != qdisc.Target ||
+		sfb.Increment != qdisc.Increment || sfb.Decrement != qdisc.Decrement || sfb.Limit != qdisc.Limit ||
+		sfb.PenaltyRate != qdisc.PenaltyRate || sfb.PenaltyBurst != qdisc.PenaltyBurst {
+		t.Fatalf("Qdisc %s does not match %s", sfb, qdisc)
+	}
+	if sfb.XStats == nil {
+		t.Fatal("Sfb xstats are missing")
+	}
+
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 0 {
+		t.Fatal("Failed to remove qdisc")
+	}
+}
+
+func TestPieAddDel(t *testing.T) {
+	minKernelRequired(t, 5, 6)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	attrs := QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	}
+	for _, qdisc := range []Qdisc{
+		&Pie{QdiscAttrs: attrs, Target: 20000, TUpdate: 30000, Limit: 500, Alpha: 2, Beta: 20, ECN: 1, Bytemode: 1},
+		&FqPie{QdiscAttrs: attrs, Target: 20000, TUpdate: 30000, Limit: 5000, Flows: 512, Alpha: 2, Beta: 20, ECNProb: 20, ECN: 1},
+	} {
+		if err := QdiscAdd(qdisc); err != nil {
+			t.Fatal(err)
+		}
+		qdiscs, err := SafeQdiscList(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(qdiscs) != 1 {
+			t.Fatal("Failed to add qdisc")
+		}
+		switch qdisc := qdisc.(type) {
+		case *Pie:
+			pie, ok := qdiscs[0].(*Pie)
+			if !ok {
+				t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+			}
+			if pie.Target != qdisc.Target || pie.TUpdate != qdisc.TUpdate || pie.Limit != qdisc.Limit ||
+				pie.Alpha != qdisc.Alpha || pie.Beta != qdisc.Beta || pie.ECN != qdisc.ECN || pie.Bytemode != qdisc.Bytemode {
+				t.Fatalf("Qdisc %s does not match %s", pie, qdisc)
+			}
+		case *FqPie:
+			fqPie, ok := qdiscs[0].(*FqPie)
+			if !ok {
+				t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+			}
+			if fqPie.Target != qdisc.Target || fqPie.Flows != qdisc.Flows || fqPie.ECNProb != qdisc.ECNProb || fqPie.ECN != qdisc.ECN {
+				t.Fatalf("Qdisc %s does not match %s", fqPie, qdisc)
+			}
+		}
+		if err := QdiscDel(qdisc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := QdiscAdd(&Pie{QdiscAttrs: attrs, Alpha: 33}); err == nil {
+		t.Fatal("Expected an error for an alpha out of range")
+	}
+}
+
+func TestQdiscStab(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stabs := []*Stab{
+		{Overhead: 10, LinkLayer: nl.LINKLAYER_ATM, Mtu: 2047, Tsize: 512},
+		{Overhead: 18, LinkLayer: nl.LINKLAYER_ETHERNET, Mpu: 64, Mtu: 1500, Tsize: 128},
+		// only the overhead, the kernel gets no table
+		{Overhead: -4, LinkLayer: nl.LINKLAYER_ETHERNET},
+		nil,
+	}
+	for _, stab := range stabs {
+		qdisc := NewHtb(QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+			Stab:      stab,
+		})
+		if err := QdiscAdd(qdisc); err != nil {
+			t.Fatal(err)
+		}
+		qdiscs, err := SafeQdiscList(link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(qdiscs) != 1 {
+			t.Fatal("Failed to add qdisc")
+		}
+		if !reflect.DeepEqual(qdiscs[0].Attrs().Stab, stab) {
+			t.Fatalf("Stab %+v does not match %+v", qdiscs[0].Attrs().Stab, stab)
+		}
+		if err := QdiscDel(qdisc); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestQdiscRawAttributes(t *testing.T) {
+	minKernelRequired(t, 4, 16)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	link, err := LinkAddAndGet(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// TCA_INGRESS_BLOCK (the shared filter block of the clsact ingress
+	// hook) is not modelled by QdiscAttrs
+	const tcaIngressBlock = 13
+	blockOf := func(qdisc Qdisc) uint32 {
+		for _, raw := range qdisc.Attrs().RawAttributes {
+			if raw.Type == tcaIngressBlock && len(raw.Value) == 4 {
+				return native.Uint32(raw.Value)
+			}
+		}
+		return 0
+	}
+
+	qdisc := &GenericQdisc{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex:     link.Attrs().Index,
+			Handle:        MakeHandle(0xffff, 0),
+			Parent:        HANDLE_CLSACT,
+			RawAttributes: []RawAttribute{{Type: tcaIngressBlock, Value: nl.Uint32Attr(10)}},
+		},
+		QdiscType: "clsact",
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	if block := blockOf(qdiscs[0]); block != 10 {
+		t.Fatalf("TCA_INGRESS_BLOCK not preserved: %v", qdiscs[0].Attrs().RawAttributes)
+	}
+
+	listed := qdiscs[0]
+	if err := QdiscDel(listed); err != nil {
+		t.Fatal(err)
+	}
+	if err := QdiscAdd(listed); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err = SafeQdiscList(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to re-add qdisc")
+	}
+	if block := blockOf(qdiscs[0]); block != 10 {
+		t.Fatalf("TCA_INGRESS_BLOCK lost on re-add: %v", qdiscs[0].Attrs().RawAttributes)
+	}
+}
+
+func TestHtbDirectQlen(t *testing.T) {
+	minKernelRequired(t, 3, 10)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	directQlen := uint32(42)
+	qdisc.DirectQlen = &directQlen
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := QdiscListByType(link, "htb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	htb := qdiscs[0].(*Htb)
+	if htb.DirectQlen == nil || *htb.DirectQlen != directQlen {
+		t.Fatalf("DirectQlen doesn't match %d", directQlen)
+	}
+	if htb.DirectPkts != 0 {
+		t.Fatalf("Expected no direct packets, got %d", htb.DirectPkts)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEtfAddDel(t *testing.T) {
+	minKernelRequired(t, 4, 19)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewEtf(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	qdisc.Delta = 300000
+	qdisc.Deadline = true
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := QdiscListByType(link, "etf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	etf, ok := qdiscs[0].(*Etf)
+	if !ok {
+		t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+	}
+	if etf.ClockId != qdisc.ClockId || etf.Delta != qdisc.Delta ||
+		etf.Deadline != qdisc.Deadline || etf.Offload || etf.SkipSockCheck {
+		t.Fatalf("Qdisc %s does not match %s", etf, qdisc)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	qdisc.Delta = -1
+	if err := QdiscAdd(qdisc); err == nil {
+		t.Fatal("Expected an error for a negative delta")
+	}
+}
+
+func TestTaprioAddDel(t *testing.T) {
+	minKernelRequired(t, 5, 2)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo", NumTxQueues: 4}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewTaprio(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(1, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	qdisc.NumTc = 2
+	qdisc.PrioTcMap = [PRIORITY_MAP_LEN]uint8{0, 1, 1, 1}
+	qdisc.Count = [PRIORITY_MAP_LEN]uint16{1, 3}
+	qdisc.Offset = [PRIORITY_MAP_LEN]uint16{0, 1}
+	qdisc.Schedule = []TaprioEntry{
+		{Command: TAPRIO_CMD_SET_GATES, GateMask: 0x1, Interval: 300000},
+		{Command: TAPRIO_CMD_SET_GATES, GateMask: 0x2, Interval: 700000},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := QdiscListByType(link, "taprio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	taprio, ok := qdiscs[0].(*Taprio)
+	if !ok {
+		t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+	}
+	if taprio.NumTc != qdisc.NumTc || taprio.PrioTcMap != qdisc.PrioTcMap ||
+		taprio.Count != qdisc.Count || taprio.Offset != qdisc.Offset || taprio.ClockId != qdisc.ClockId {
+		t.Fatalf("Qdisc %s does not match %s", taprio, qdisc)
+	}
+	if !reflect.DeepEqual(taprio.Schedule, qdisc.Schedule) {
+		t.Fatalf("Schedule %v does not match %v", taprio.Schedule, qdisc.Schedule)
+	}
+	if taprio.CycleTime != 1000000 {
+		t.Fatalf("Expected a cycle time of 1000000, got %d", taprio.CycleTime)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	qdisc.Schedule = []TaprioEntry{{GateMask: 0x1}}
+	if err := QdiscAdd(qdisc); err == nil {
+		t.Fatal("Expected an error for an entry without interval")
+	}
+}
+
+func TestMqprioAddDel(t *testing.T) {
+	minKernelRequired(t, 4, 15)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo", NumTxQueues: 4}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Mqprio{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+		NumTc:     2,
+		PrioTcMap: [PRIORITY_MAP_LEN]uint8{0, 0, 1, 1},
+		Count:     [PRIORITY_MAP_LEN]uint16{2, 2},
+		Offset:    [PRIORITY_MAP_LEN]uint16{0, 2},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := QdiscListByType(link, "mqprio")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	mqprio, ok := qdiscs[0].(*Mqprio)
+	if !ok {
+		t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+	}
+	if mqprio.NumTc != qdisc.NumTc || mqprio.PrioTcMap != qdisc.PrioTcMap ||
+		mqprio.Count != qdisc.Count || mqprio.Offset != qdisc.Offset || mqprio.HwOffload {
+		t.Fatalf("Qdisc %s does not match %s", mqprio, qdisc)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMultiqAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Bridge{LinkAttrs: LinkAttrs{Name: "foo", NumTxQueues: 4}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Multiq{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := QdiscListByType(link, "multiq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	multiq, ok := qdiscs[0].(*Multiq)
+	if !ok {
+		t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+	}
+	if multiq.Bands != 4 || multiq.MaxBands != 4 {
+		t.Fatalf("Expected 4 bands for 4 tx queues, got %s", multiq)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQdiscString(t *testing.T) {
+	attrs := QdiscAttrs{LinkIndex: 2, Handle: MakeHandle(1, 0), Parent: HANDLE_ROOT}
+	htb := NewHtb(attrs)
+	htb.Defcls = 0x10
+	expected := "qdisc htb 1: dev 2 root r2q 10 default 0x10 direct_packets_stat 0"
+	if s := htb.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	pfifo := &Pfifo{QdiscAttrs: QdiscAttrs{LinkIndex: 2, Handle: MakeHandle(0x10, 0), Parent: MakeHandle(1, 0x10)}, Limit: 100}
+	expected = "qdisc pfifo 10: dev 2 parent 1:10 limit 100p"
+	if s := pfifo.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	fqCodel := &FqCodel{QdiscAttrs: attrs, Limit: 10240, Target: 5000, Interval: 100000, ECN: 1}
+	expected = "qdisc fq_codel 1: dev 2 root limit 10240p target 5ms interval 100ms ecn"
+	if s := fqCodel.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	ingress := &Ingress{QdiscAttrs: QdiscAttrs{LinkIndex: 2, Handle: MakeHandle(0xffff, 0), Parent: HANDLE_INGRESS}}
+	expected = "qdisc ingress ffff: dev 2 parent ffff:fff1"
+	if s := ingress.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	netem := NewNetem(attrs, NetemQdiscAttrs{Latency: 10000, Jitter: 1000, Loss: 1})
+	expected = "qdisc netem 1: dev 2 root limit 1000 delay 10ms 1ms loss 1%"
+	if s := netem.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	classAttrs := ClassAttrs{LinkIndex: 2, Handle: MakeHandle(1, 0x10), Parent: MakeHandle(1, 0), Leaf: MakeHandle(0x10, 0)}
+	class := NewHtbClass(classAttrs, HtbClassAttrs{Rate: 10000000, Ceil: 20000000, Buffer: 1600, Cbuffer: 1600})
+	expected = "class htb 1:10 dev 2 parent 1: leaf 10: prio 0 rate 10Mbit ceil 20Mbit burst 1600b cburst 1600b quantum 125000"
+	if s := class.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	hfsc := NewHfscClass(ClassAttrs{LinkIndex: 2, Handle: MakeHandle(1, 1), Parent: HANDLE_ROOT})
+	hfsc.SetLS(0, 0, 1000000)
+	expected = "class hfsc 1:1 dev 2 root ls m1 0bit d 0us m2 1Mbit"
+	if s := hfsc.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+}
+
+func TestCbsAddDel(t *testing.T) {
+	minKernelRequired(t, 4, 15)
+
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Cbs{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+		Hicredit:  30,
+		Locredit:  -1470,
+		Idleslope: 20000,
+		Sendslope: -980000,
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := QdiscListByType(link, "cbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	cbs, ok := qdiscs[0].(*Cbs)
+	if !ok {
+		t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+	}
+	if cbs.Hicredit != qdisc.Hicredit || cbs.Locredit != qdisc.Locredit ||
+		cbs.Idleslope != qdisc.Idleslope || cbs.Sendslope != qdisc.Sendslope || cbs.Offload {
+		t.Fatalf("Qdisc %s does not match %s", cbs, qdisc)
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+
+	qdisc.Sendslope = 1000
+	if err := QdiscAdd(qdisc); err == nil {
+		t.Fatal("Expected an error for a positive sendslope")
+	}
+}
+
+func TestPlugAddDel(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	qdisc := &Plug{
+		QdiscAttrs: QdiscAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    MakeHandle(1, 0),
+			Parent:    HANDLE_ROOT,
+		},
+	}
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	qdiscs, err := QdiscListByType(link, "plug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qdiscs) != 1 {
+		t.Fatal("Failed to add qdisc")
+	}
+	if _, ok := qdiscs[0].(*Plug); !ok {
+		t.Fatalf("Qdisc is the wrong type %T", qdiscs[0])
+	}
+
+	for _, action := range []PlugAction{PLUG_LIMIT, PLUG_BUFFER, PLUG_RELEASE_ONE, PLUG_RELEASE_INDEFINITE} {
+		qdisc.Action = action
+		qdisc.Limit = 0
+		if action == PLUG_LIMIT {
+			qdisc.Limit = 10000
+		}
+		if err := QdiscChange(qdisc); err != nil {
+			t.Fatalf("Failed to send action %d: %v", action, err)
+		}
+	}
+	if err := QdiscDel(qdisc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlugPayload(t *testing.T) {
+	hasOptions := func(flags int, qdisc Qdisc) bool {
+		req := nl.NewNetlinkRequest(unix.RTM_NEWQDISC, flags)
+		if err := qdiscPayload(req, qdisc); err != nil {
+			t.Fatal(err)
+		}
+		for _, data := range req.Data {
+			if attr, ok := data.(*nl.RtAttr); ok && attr.Type == nl.TCA_OPTIONS {
+				return true
+			}
+		}
+		return false
+	}
+
+	qdisc := &Plug{Action: PLUG_BUFFER}
+	if hasOptions(unix.NLM_F_CREATE|unix.NLM_F_EXCL, qdisc) {
+		t.Fatal("Options sent on creation without a limit")
+	}
+	if !hasOptions(0, qdisc) {
+		t.Fatal("Options not sent on change")
+	}
+	if !hasOptions(unix.NLM_F_CREATE|unix.NLM_F_REPLACE, qdisc) {
+		t.Fatal("Options not sent on replace")
+	}
+}
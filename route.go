@@ -34,8 +34,10 @@ type Route struct {
 	Scope      Scope
 	Dst        *net.IPNet
 	Src        net.IP
+	SrcPrefix  *net.IPNet // source prefix of a source-specific (IPv6) route
 	Gw         net.IP
 	MultiPath  []*NexthopInfo
+	NhID       uint32 // ID of a nexthop object, see NexthopAdd
 	Protocol   int
 	Priority   int
 	Table      int
@@ -48,33 +50,87 @@ type Route struct {
 	MTU        int
 	AdvMSS     int
 	Hoplimit   int
+	// Expires is the lifetime of an IPv6 route in seconds, the kernel
+	// removes the route when it runs out. 0 is no expiry.
+	Expires int
+	// RawAttributes holds the route attributes not decoded above. They
+	// are sent back unchanged when the route is added or replaced.
+	RawAttributes []RawAttribute
 }
 
+// String formats the route like `ip route`:
+// [$type] $dst [from $srcprefix] [as to $newdst] [nhid $id] [encap $encap]
+// [via $gw] [dev $index] [table $table] [proto $protocol] [scope $scope]
+// [src $src] [metric $priority] [$flags] [nexthop ...]
 func (r Route) String() string {
 	elems := []string{}
-	if len(r.MultiPath) == 0 {
-		elems = append(elems, fmt.Sprintf("Ifindex: %d", r.LinkIndex))
+	if t := routeTypeStr(r.Type); t != "" {
+		elems = append(elems, t)
 	}
-	if r.MPLSDst != nil {
-		elems = append(elems, fmt.Sprintf("Dst: %d", r.MPLSDst))
-	} else {
-		elems = append(elems, fmt.Sprintf("Dst: %s", r.Dst))
+	switch {
+	case r.MPLSDst != nil:
+		elems = append(elems, fmt.Sprintf("%d", *r.MPLSDst))
+	case r.Dst != nil:
+		elems = append(elems, r.Dst.String())
+	default:
+		elems = append(elems, "default")
+	}
+	if r.SrcPrefix != nil {
+		elems = append(elems, fmt.Sprintf("from %s", r.SrcPrefix))
 	}
 	if r.NewDst != nil {
-		elems = append(elems, fmt.Sprintf("NewDst: %s", r.NewDst))
+		elems = append(elems, fmt.Sprintf("as to %s", r.NewDst))
+	}
+	if r.NhID > 0 {
+		elems = append(elems, fmt.Sprintf("nhid %d", r.NhID))
 	}
 	if r.Encap != nil {
-		elems = append(elems, fmt.Sprintf("Encap: %s", r.Encap))
+		elems = append(elems, fmt.Sprintf("encap %s", r.Encap))
+	}
+	if r.Gw != nil {
+		elems = append(elems, fmt.Sprintf("via %s", r.Gw))
+	}
+	if r.LinkIndex > 0 {
+		elems = append(elems, fmt.Sprintf("dev %d", r.LinkIndex))
+	}
+	if r.ILinkIndex > 0 {
+		elems = append(elems, fmt.Sprintf("iif %d", r.ILinkIndex))
+	}
+	if table := routeTableStr(r.Table); table != "" {
+		elems = append(elems, fmt.Sprintf("table %s", table))
+	}
+	if r.Protocol > 0 {
+		elems = append(elems, fmt.Sprintf("proto %s", routeProtocolStr(r.Protocol)))
+	}
+	if r.Scope > 0 {
+		elems = append(elems, fmt.Sprintf("scope %s", r.Scope))
+	}
+	if r.Src != nil {
+		elems = append(elems, fmt.Sprintf("src %s", r.Src))
 	}
-	elems = append(elems, fmt.Sprintf("Src: %s", r.Src))
-	if len(r.MultiPath) > 0 {
-		elems = append(elems, fmt.Sprintf("Gw: %s", r.MultiPath))
-	} else {
-		elems = append(elems, fmt.Sprintf("Gw: %s", r.Gw))
+	if r.Priority > 0 {
+		elems = append(elems, fmt.Sprintf("metric %d", r.Priority))
 	}
-	elems = append(elems, fmt.Sprintf("Flags: %s", r.ListFlags()))
-	elems = append(elems, fmt.Sprintf("Table: %d", r.Table))
-	return fmt.Sprintf("{%s}", strings.Join(elems, " "))
+	if r.Tos > 0 {
+		elems = append(elems, fmt.Sprintf("tos %#x", r.Tos))
+	}
+	elems = append(elems, r.ListFlags()...)
+	if r.MTU > 0 {
+		elems = append(elems, fmt.Sprintf("mtu %d", r.MTU))
+	}
+	if r.AdvMSS > 0 {
+		elems = append(elems, fmt.Sprintf("advmss %d", r.AdvMSS))
+	}
+	if r.Hoplimit > 0 {
+		elems = append(elems, fmt.Sprintf("hoplimit %d", r.Hoplimit))
+	}
+	if r.Expires > 0 {
+		elems = append(elems, fmt.Sprintf("expires %dsec", r.Expires))
+	}
+	for _, nh := range r.MultiPath {
+		elems = append(elems, nh.String())
+	}
+	return strings.Join(elems, " ")
 }
 
 func (r Route) Equal(x Route) bool {
@@ -83,8 +139,10 @@ func (r Route) Equal(x Route) bool {
 		r.Scope == x.Scope &&
 		ipNetEqual(r.Dst, x.Dst) &&
 		r.Src.Equal(x.Src) &&
+		ipNetEqual(r.SrcPrefix, x.SrcPrefix) &&
 		r.Gw.Equal(x.Gw) &&
 		nexthopInfoSlice(r.MultiPath).Equal(x.MultiPath) &&
+		r.NhID == x.NhID &&
 		r.Protocol == x.Protocol &&
 		r.Priority == x.Priority &&
 		r.Table == x.Table &&
@@ -125,19 +183,24 @@ type NexthopInfo struct {
 	Encap     Encap
 }
 
+// String formats the nexthop like `ip route` does for the nexthops of a
+// multipath route:
+// nexthop [as to $newdst] [encap $encap] [via $gw] dev $index weight $weight [$flags]
 func (n *NexthopInfo) String() string {
-	elems := []string{}
-	elems = append(elems, fmt.Sprintf("Ifindex: %d", n.LinkIndex))
+	elems := []string{"nexthop"}
 	if n.NewDst != nil {
-		elems = append(elems, fmt.Sprintf("NewDst: %s", n.NewDst))
+		elems = append(elems, fmt.Sprintf("as to %s", n.NewDst))
 	}
 	if n.Encap != nil {
-		elems = append(elems, fmt.Sprintf("Encap: %s", n.Encap))
+		elems = append(elems, fmt.Sprintf("encap %s", n.Encap))
+	}
+	if n.Gw != nil {
+		elems = append(elems, fmt.Sprintf("via %s", n.Gw))
 	}
-	elems = append(elems, fmt.Sprintf("Weight: %d", n.Hops+1))
-	elems = append(elems, fmt.Sprintf("Gw: %s", n.Gw))
-	elems = append(elems, fmt.Sprintf("Flags: %s", n.ListFlags()))
-	return fmt.Sprintf("{%s}", strings.Join(elems, " "))
+	elems = append(elems, fmt.Sprintf("dev %d", n.LinkIndex))
+	elems = append(elems, fmt.Sprintf("weight %d", n.Hops+1))
+	elems = append(elems, n.ListFlags()...)
+	return strings.Join(elems, " ")
 }
 
 func (n NexthopInfo) Equal(x NexthopInfo) bool {
@@ -1,8 +1,11 @@
 package netlink
 
 import (
+	"bytes"
 	"fmt"
 	"net"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -33,6 +36,7 @@ const (
 	RT_FILTER_GW
 	RT_FILTER_TABLE
 	RT_FILTER_HOPLIMIT
+	RT_FILTER_PRIORITY
 )
 
 const (
@@ -40,6 +44,9 @@ const (
 	FLAG_PERVASIVE NextHopFlag = unix.RTNH_F_PERVASIVE
 )
 
+// userHz is the USER_HZ clock tick rate of the expiry in RTA_CACHEINFO
+const userHz = 100
+
 var testFlags = []flagString{
 	{f: FLAG_ONLINK, s: "onlink"},
 	{f: FLAG_PERVASIVE, s: "pervasive"},
@@ -63,6 +70,73 @@ func (n *NexthopInfo) ListFlags() []string {
 	return listFlags(n.Flags)
 }
 
+var routeTypeNames = map[int]string{
+	unix.RTN_LOCAL:       "local",
+	unix.RTN_BROADCAST:   "broadcast",
+	unix.RTN_ANYCAST:     "anycast",
+	unix.RTN_MULTICAST:   "multicast",
+	unix.RTN_BLACKHOLE:   "blackhole",
+	unix.RTN_UNREACHABLE: "unreachable",
+	unix.RTN_PROHIBIT:    "prohibit",
+	unix.RTN_THROW:       "throw",
+	unix.RTN_NAT:         "nat",
+	unix.RTN_XRESOLVE:    "xresolve",
+}
+
+var routeProtocolNames = map[int]string{
+	unix.RTPROT_REDIRECT: "redirect",
+	unix.RTPROT_KERNEL:   "kernel",
+	unix.RTPROT_BOOT:     "boot",
+	unix.RTPROT_STATIC:   "static",
+	unix.RTPROT_RA:       "ra",
+	unix.RTPROT_DHCP:     "dhcp",
+}
+
+var scopeNames = map[Scope]string{
+	SCOPE_UNIVERSE: "global",
+	SCOPE_SITE:     "site",
+	SCOPE_LINK:     "link",
+	SCOPE_HOST:     "host",
+	SCOPE_NOWHERE:  "nowhere",
+}
+
+// routeTypeStr returns the name `ip route` prints for a route type, or ""
+// for unicast routes, which it prints without one.
+func routeTypeStr(routeType int) string {
+	if routeType == unix.RTN_UNSPEC || routeType == unix.RTN_UNICAST {
+		return ""
+	}
+	if name, ok := routeTypeNames[routeType]; ok {
+		return name
+	}
+	return strconv.Itoa(routeType)
+}
+
+// routeTableStr returns the table `ip route` prints for a route, or "" for
+// the main table, which it leaves out.
+func routeTableStr(table int) string {
+	if table == unix.RT_TABLE_UNSPEC || table == unix.RT_TABLE_MAIN {
+		return ""
+	}
+	return strconv.Itoa(table)
+}
+
+func routeProtocolStr(protocol int) string {
+	if name, ok := routeProtocolNames[protocol]; ok {
+		return name
+	}
+	return strconv.Itoa(protocol)
+}
+
+func (s Scope) String() string {
+	if name, ok := scopeNames[s]; ok {
+		return name
+	}
+	return strconv.Itoa(int(s))
+}
+
+// MPLSDestination is the outgoing label stack of a native MPLS route, used
+// as Route.NewDst alongside Route.MPLSDst.
 type MPLSDestination struct {
 	Labels []int
 }
@@ -116,10 +190,16 @@ func (d *MPLSDestination) Equal(x Destination) bool {
 	return true
 }
 
+// MPLSEncap pushes a label stack onto packets matching an IP route.
 type MPLSEncap struct {
 	Labels []int
 }
 
+// NewMPLSEncap returns an encap that pushes labels, outermost first.
+func NewMPLSEncap(labels []int) *MPLSEncap {
+	return &MPLSEncap{Labels: labels}
+}
+
 func (e *MPLSEncap) Type() int {
 	return nl.LWTUNNEL_ENCAP_MPLS
 }
@@ -187,7 +267,11 @@ func (e *MPLSEncap) Equal(x Encap) bool {
 	return true
 }
 
-// SEG6 definitions
+// SEG6Encap steers packets matching a route through an IPv6 segment list.
+// Mode is nl.SEG6_IPTUN_MODE_ENCAP to wrap packets in an outer IPv6 header
+// or nl.SEG6_IPTUN_MODE_INLINE to insert the SRH into IPv6 packets. Segments
+// are given in the order they are stored in the SRH, so the first hop is the
+// last element.
 type SEG6Encap struct {
 	Mode     int
 	Segments []net.IP
@@ -267,6 +351,7 @@ type SEG6LocalEncap struct {
 	Action   int
 	Segments []net.IP // from SRH in seg6_local_lwt
 	Table    int      // table id for End.T and End.DT6
+	VrfTable int      // vrf table id for End.DT4 and End.DT6
 	InAddr   net.IP
 	In6Addr  net.IP
 	Iif      int
@@ -305,6 +390,9 @@ func (e *SEG6LocalEncap) Decode(buf []byte) error {
 		case nl.SEG6_LOCAL_OIF:
 			e.Oif = int(native.Uint32(attr.Value[0:4]))
 			e.Flags[nl.SEG6_LOCAL_OIF] = true
+		case nl.SEG6_LOCAL_VRFTABLE:
+			e.VrfTable = int(native.Uint32(attr.Value[0:4]))
+			e.Flags[nl.SEG6_LOCAL_VRFTABLE] = true
 		}
 	}
 	return err
@@ -367,6 +455,13 @@ func (e *SEG6LocalEncap) Encode() ([]byte, error) {
 		native.PutUint32(attr[4:], uint32(e.Oif))
 		res = append(res, attr...)
 	}
+	if e.Flags[nl.SEG6_LOCAL_VRFTABLE] {
+		attr := make([]byte, 8)
+		native.PutUint16(attr, 8)
+		native.PutUint16(attr[2:], nl.SEG6_LOCAL_VRFTABLE)
+		native.PutUint32(attr[4:], uint32(e.VrfTable))
+		res = append(res, attr...)
+	}
 	return res, err
 }
 func (e *SEG6LocalEncap) String() string {
@@ -376,6 +471,9 @@ func (e *SEG6LocalEncap) String() string {
 	if e.Flags[nl.SEG6_LOCAL_TABLE] {
 		strs = append(strs, fmt.Sprintf("table %d", e.Table))
 	}
+	if e.Flags[nl.SEG6_LOCAL_VRFTABLE] {
+		strs = append(strs, fmt.Sprintf("vrftable %d", e.VrfTable))
+	}
 	if e.Flags[nl.SEG6_LOCAL_NH4] {
 		strs = append(strs, fmt.Sprintf("nh4 %s", e.InAddr))
 	}
@@ -437,12 +535,49 @@ func (e *SEG6LocalEncap) Equal(x Encap) bool {
 	if !e.InAddr.Equal(o.InAddr) || !e.In6Addr.Equal(o.In6Addr) {
 		return false
 	}
-	if e.Action != o.Action || e.Table != o.Table || e.Iif != o.Iif || e.Oif != o.Oif {
+	if e.Action != o.Action || e.Table != o.Table || e.Iif != o.Iif || e.Oif != o.Oif || e.VrfTable != o.VrfTable {
 		return false
 	}
 	return true
 }
 
+// RawEncap carries a light weight tunnel encap this package does not decode,
+// such as bpf, ip or ip6. The attribute payload is kept verbatim so the
+// encap survives a list and re-add of the route.
+type RawEncap struct {
+	EncapType int
+	Data      []byte
+}
+
+func (e *RawEncap) Type() int {
+	return e.EncapType
+}
+
+func (e *RawEncap) Decode(buf []byte) error {
+	e.Data = make([]byte, len(buf))
+	copy(e.Data, buf)
+	return nil
+}
+
+func (e *RawEncap) Encode() ([]byte, error) {
+	return e.Data, nil
+}
+
+func (e *RawEncap) String() string {
+	return fmt.Sprintf("encap %s len %d", nl.LwtEncapTypeString(e.EncapType), len(e.Data))
+}
+
+func (e *RawEncap) Equal(x Encap) bool {
+	o, ok := x.(*RawEncap)
+	if !ok {
+		return false
+	}
+	if e == nil || o == nil {
+		return e == o
+	}
+	return e.EncapType == o.EncapType && bytes.Equal(e.Data, o.Data)
+}
+
 // RouteAdd will add a route to the system.
 // Equivalent to: `ip route add $route`
 func RouteAdd(route *Route) error {
@@ -459,12 +594,18 @@ func (h *Handle) RouteAdd(route *Route) error {
 
 // RouteReplace will add a route to the system.
 // Equivalent to: `ip route replace $route`
+//
+// Priority (the metric) is part of the identity of a route, so a route
+// to the same destination with another priority is added next to it.
 func RouteReplace(route *Route) error {
 	return pkgHandle.RouteReplace(route)
 }
 
 // RouteReplace will add a route to the system.
 // Equivalent to: `ip route replace $route`
+//
+// Priority (the metric) is part of the identity of a route, so a route
+// to the same destination with another priority is added next to it.
 func (h *Handle) RouteReplace(route *Route) error {
 	flags := unix.NLM_F_CREATE | unix.NLM_F_REPLACE | unix.NLM_F_ACK
 	req := h.newNetlinkRequest(unix.RTM_NEWROUTE, flags)
@@ -473,20 +614,36 @@ func (h *Handle) RouteReplace(route *Route) error {
 
 // RouteDel will delete a route from the system.
 // Equivalent to: `ip route del $route`
+//
+// With a Priority only the route with that metric is deleted, without
+// one the first route matching the other fields is.
 func RouteDel(route *Route) error {
 	return pkgHandle.RouteDel(route)
 }
 
 // RouteDel will delete a route from the system.
 // Equivalent to: `ip route del $route`
+//
+// With a Priority only the route with that metric is deleted, without
+// one the first route matching the other fields is.
 func (h *Handle) RouteDel(route *Route) error {
 	req := h.newNetlinkRequest(unix.RTM_DELROUTE, unix.NLM_F_ACK)
 	return h.routeHandle(route, req, nl.NewRtDelMsg())
 }
 
 func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg) error {
-	if (route.Dst == nil || route.Dst.IP == nil) && route.Src == nil && route.Gw == nil && route.MPLSDst == nil {
-		return fmt.Errorf("one of Dst.IP, Src, or Gw must not be nil")
+	if err := h.routeRequest(route, req, msg); err != nil {
+		return err
+	}
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
+// routeRequest adds msg and the attributes of route to req.
+func (h *Handle) routeRequest(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg) error {
+	if (route.Dst == nil || route.Dst.IP == nil) && route.Src == nil && route.Gw == nil && route.MPLSDst == nil &&
+		(route.SrcPrefix == nil || route.SrcPrefix.IP == nil) {
+		return fmt.Errorf("one of Dst.IP, Src, SrcPrefix.IP or Gw must not be nil")
 	}
 
 	family := -1
@@ -511,6 +668,23 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		rtAttrs = append(rtAttrs, nl.NewRtAttr(unix.RTA_DST, nl.EncodeMPLSStack(*route.MPLSDst)))
 	}
 
+	if route.SrcPrefix != nil && route.SrcPrefix.IP != nil {
+		srcFamily := nl.GetIPFamily(route.SrcPrefix.IP)
+		if family != -1 && family != srcFamily {
+			return fmt.Errorf("source prefix and destination are not the same IP family")
+		}
+		family = srcFamily
+		srcLen, _ := route.SrcPrefix.Mask.Size()
+		msg.Src_len = uint8(srcLen)
+		var srcData []byte
+		if srcFamily == FAMILY_V4 {
+			srcData = route.SrcPrefix.IP.To4()
+		} else {
+			srcData = route.SrcPrefix.IP.To16()
+		}
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(unix.RTA_SRC, srcData))
+	}
+
 	if route.NewDst != nil {
 		if family != -1 && family != route.NewDst.Family() {
 			return fmt.Errorf("new destination and destination are not the same address family")
@@ -564,6 +738,14 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		rtAttrs = append(rtAttrs, nl.NewRtAttr(unix.RTA_GATEWAY, gwData))
 	}
 
+	if route.NhID > 0 {
+		// the nexthop object carries the whole nexthop specification
+		if route.Gw != nil || len(route.MultiPath) > 0 || route.LinkIndex != 0 || route.Encap != nil {
+			return fmt.Errorf("nexthop id and gateway, multipath, link index or encap are mutually exclusive")
+		}
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(nl.RTA_NH_ID, nl.Uint32Attr(route.NhID)))
+	}
+
 	if len(route.MultiPath) > 0 {
 		buf := []byte{}
 		for _, nh := range route.MultiPath {
@@ -661,6 +843,17 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 		rtAttrs = append(rtAttrs, attr)
 	}
 
+	if route.Expires > 0 {
+		if family == FAMILY_V4 {
+			return fmt.Errorf("route expiry is only supported for IPv6 routes")
+		}
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(unix.RTA_EXPIRES, nl.Uint32Attr(uint32(route.Expires))))
+	}
+
+	for _, raw := range route.RawAttributes {
+		rtAttrs = append(rtAttrs, nl.NewRtAttr(int(raw.Type), raw.Value))
+	}
+
 	msg.Flags = uint32(route.Flags)
 	msg.Scope = uint8(route.Scope)
 	msg.Family = uint8(family)
@@ -677,8 +870,7 @@ func (h *Handle) routeHandle(route *Route, req *nl.NetlinkRequest, msg *nl.RtMsg
 
 	req.AddData(nl.NewRtAttr(unix.RTA_OIF, b))
 
-	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
-	return err
+	return nil
 }
 
 // RouteList gets a list of routes in the system.
@@ -703,12 +895,16 @@ func (h *Handle) RouteList(link Link, family int) ([]Route, error) {
 
 // RouteListFiltered gets a list of routes in the system filtered with specified rules.
 // All rules must be defined in RouteFilter struct
+// RT_FILTER_DST matches routes whose destination is exactly filter.Dst; use
+// RouteGet for a longest prefix match.
 func RouteListFiltered(family int, filter *Route, filterMask uint64) ([]Route, error) {
 	return pkgHandle.RouteListFiltered(family, filter, filterMask)
 }
 
 // RouteListFiltered gets a list of routes in the system filtered with specified rules.
 // All rules must be defined in RouteFilter struct
+// RT_FILTER_DST matches routes whose destination is exactly filter.Dst; use
+// RouteGet for a longest prefix match.
 func (h *Handle) RouteListFiltered(family int, filter *Route, filterMask uint64) ([]Route, error) {
 	req := h.newNetlinkRequest(unix.RTM_GETROUTE, unix.NLM_F_DUMP)
 	infmsg := nl.NewIfInfomsg(family)
@@ -756,14 +952,12 @@ func (h *Handle) RouteListFiltered(family int, filter *Route, filterMask uint64)
 				continue
 			case filterMask&RT_FILTER_SRC != 0 && !route.Src.Equal(filter.Src):
 				continue
-			case filterMask&RT_FILTER_DST != 0:
-				if filter.MPLSDst == nil || route.MPLSDst == nil || (*filter.MPLSDst) != (*route.MPLSDst) {
-					if !ipNetEqual(route.Dst, filter.Dst) {
-						continue
-					}
-				}
+			case filterMask&RT_FILTER_DST != 0 && !routeDstEqual(&route, filter):
+				continue
 			case filterMask&RT_FILTER_HOPLIMIT != 0 && route.Hoplimit != filter.Hoplimit:
 				continue
+			case filterMask&RT_FILTER_PRIORITY != 0 && route.Priority != filter.Priority:
+				continue
 			}
 		}
 		res = append(res, route)
@@ -771,6 +965,69 @@ func (h *Handle) RouteListFiltered(family int, filter *Route, filterMask uint64)
 	return res, nil
 }
 
+// RouteTableList gets the ids of all routing tables that hold at least one
+// route, sorted in ascending order. Use FAMILY_ALL to include both IPv4 and
+// IPv6 tables.
+func RouteTableList(family int) ([]int, error) {
+	return pkgHandle.RouteTableList(family)
+}
+
+// RouteTableList gets the ids of all routing tables that hold at least one
+// route, sorted in ascending order. Use FAMILY_ALL to include both IPv4 and
+// IPv6 tables.
+func (h *Handle) RouteTableList(family int) ([]int, error) {
+	req := h.newNetlinkRequest(unix.RTM_GETROUTE, unix.NLM_F_DUMP)
+	infmsg := nl.NewIfInfomsg(family)
+	req.AddData(infmsg)
+
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWROUTE)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool)
+	var res []int
+	for _, m := range msgs {
+		msg := nl.DeserializeRtMsg(m)
+		if msg.Flags&unix.RTM_F_CLONED != 0 {
+			// Ignore cloned routes
+			continue
+		}
+		// Tables above 255 are only carried in RTA_TABLE
+		route, err := deserializeRoute(m)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[route.Table] {
+			seen[route.Table] = true
+			res = append(res, route.Table)
+		}
+	}
+	sort.Ints(res)
+	return res, nil
+}
+
+// routeDstEqual reports whether the destination of route is exactly the
+// destination of filter. This is an exact prefix match, not a longest
+// prefix lookup. A nil Dst and a zero length prefix both denote the
+// default route.
+func routeDstEqual(route, filter *Route) bool {
+	if filter.MPLSDst != nil && route.MPLSDst != nil {
+		return *filter.MPLSDst == *route.MPLSDst
+	}
+	return ipNetEqual(routeDstPrefix(route.Dst), routeDstPrefix(filter.Dst))
+}
+
+func routeDstPrefix(dst *net.IPNet) *net.IPNet {
+	if dst == nil {
+		return nil
+	}
+	if ones, _ := dst.Mask.Size(); ones == 0 {
+		return nil
+	}
+	return &net.IPNet{IP: dst.IP.Mask(dst.Mask), Mask: dst.Mask}
+}
+
 // deserializeRoute decodes a binary netlink message into a Route struct
 func deserializeRoute(m []byte) (Route, error) {
 	msg := nl.DeserializeRtMsg(m)
@@ -795,6 +1052,11 @@ func deserializeRoute(m []byte) (Route, error) {
 			route.Gw = net.IP(attr.Value)
 		case unix.RTA_PREFSRC:
 			route.Src = net.IP(attr.Value)
+		case unix.RTA_SRC:
+			route.SrcPrefix = &net.IPNet{
+				IP:   attr.Value,
+				Mask: net.CIDRMask(int(msg.Src_len), 8*len(attr.Value)),
+			}
 		case unix.RTA_DST:
 			if msg.Family == nl.FAMILY_MPLS {
 				stack := nl.DecodeMPLSStack(attr.Value)
@@ -816,6 +1078,8 @@ func deserializeRoute(m []byte) (Route, error) {
 			route.Priority = int(native.Uint32(attr.Value[0:4]))
 		case unix.RTA_TABLE:
 			route.Table = int(native.Uint32(attr.Value[0:4]))
+		case nl.RTA_NH_ID:
+			route.NhID = native.Uint32(attr.Value[0:4])
 		case unix.RTA_MULTIPATH:
 			parseRtNexthop := func(value []byte) (*NexthopInfo, []byte, error) {
 				if len(value) < unix.SizeofRtNexthop {
@@ -865,6 +1129,11 @@ func deserializeRoute(m []byte) (Route, error) {
 						if err := e.Decode(encap.Value); err != nil {
 							return nil, nil, err
 						}
+					default:
+						e = &RawEncap{EncapType: typ}
+						if err := e.Decode(encap.Value); err != nil {
+							return nil, nil, err
+						}
 					}
 					info.Encap = e
 				}
@@ -909,6 +1178,20 @@ func deserializeRoute(m []byte) (Route, error) {
 					route.Hoplimit = int(native.Uint32(metric.Value[0:4]))
 				}
 			}
+		case unix.RTA_EXPIRES:
+			route.Expires = int(native.Uint32(attr.Value[0:4]))
+		case unix.RTA_CACHEINFO:
+			// Read only, rejected when sent back. The kernel reports the
+			// remaining lifetime of IPv6 routes as rta_expires here.
+			if len(attr.Value) >= 12 {
+				if expires := int32(native.Uint32(attr.Value[8:12])); expires > 0 {
+					route.Expires = int(expires) / userHz
+				}
+			}
+		default:
+			value := make([]byte, len(attr.Value))
+			copy(value, attr.Value)
+			route.RawAttributes = append(route.RawAttributes, RawAttribute{Type: attr.Attr.Type, Value: value})
 		}
 	}
 
@@ -931,6 +1214,11 @@ func deserializeRoute(m []byte) (Route, error) {
 			if err := e.Decode(encap.Value); err != nil {
 				return route, err
 			}
+		default:
+			e = &RawEncap{EncapType: typ}
+			if err := e.Decode(encap.Value); err != nil {
+				return route, err
+			}
 		}
 		route.Encap = e
 	}
@@ -3,7 +3,9 @@
 package netlink
 
 import (
+	"encoding/binary"
 	"net"
+	"sort"
 	"strconv"
 	"testing"
 	"time"
@@ -13,6 +15,59 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+func TestRouteAddDelPriority(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	dst := &net.IPNet{
+		IP:   net.IPv4(192, 168, 0, 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+	for _, priority := range []int{100, 200} {
+		route := Route{LinkIndex: link.Attrs().Index, Dst: dst, Priority: priority}
+		if err := RouteAdd(&route); err != nil {
+			t.Fatal(err)
+		}
+		// replacing a route must not touch the one with the other metric
+		if err := RouteReplace(&route); err != nil {
+			t.Fatal(err)
+		}
+	}
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{Dst: dst}, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 2 || routes[0].Priority != 100 || routes[1].Priority != 200 {
+		t.Fatalf("Expected routes with metric 100 and 200, got %v", routes)
+	}
+
+	if err := RouteDel(&Route{LinkIndex: link.Attrs().Index, Dst: dst, Priority: 200}); err != nil {
+		t.Fatal(err)
+	}
+	routes, err = RouteListFiltered(FAMILY_V4, &Route{Dst: dst, Priority: 100}, RT_FILTER_DST|RT_FILTER_PRIORITY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("Expected the route with metric 100 to be left, got %v", routes)
+	}
+	routes, err = RouteListFiltered(FAMILY_V4, &Route{Dst: dst, Priority: 200}, RT_FILTER_DST|RT_FILTER_PRIORITY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 0 {
+		t.Fatalf("Expected the route with metric 200 to be deleted, got %v", routes)
+	}
+}
+
 func TestRouteAddDel(t *testing.T) {
 	tearDown := setUpNetlinkTest(t)
 	defer tearDown()
@@ -554,6 +609,58 @@ func TestRouteFilterAllTables(t *testing.T) {
 	}
 }
 
+func TestRouteTableList(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := []Route{
+		{
+			LinkIndex: link.Attrs().Index,
+			Dst:       &net.IPNet{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(24, 32)},
+			Table:     100,
+		},
+		{
+			LinkIndex: link.Attrs().Index,
+			Dst:       &net.IPNet{IP: net.ParseIP("2001:db8::"), Mask: net.CIDRMask(64, 128)},
+			Table:     1000,
+		},
+	}
+	for i := range routes {
+		if err := RouteAdd(&routes[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tables, err := RouteTableList(FAMILY_ALL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{100, 1000, unix.RT_TABLE_LOCAL} {
+		if !tableIDIn(tables, id) {
+			t.Fatalf("Table %d not listed in %v", id, tables)
+		}
+	}
+	if !sort.IntsAreSorted(tables) {
+		t.Fatalf("Tables not sorted: %v", tables)
+	}
+
+	tables, err = RouteTableList(FAMILY_V4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tableIDIn(tables, 100) || tableIDIn(tables, 1000) {
+		t.Fatalf("Unexpected IPv4 tables %v", tables)
+	}
+}
+
 func tableIDIn(ids []int, id int) bool {
 	for _, v := range ids {
 		if v == id {
@@ -800,6 +907,216 @@ func TestMPLSRouteAddDel(t *testing.T) {
 
 }
 
+func TestMPLSEncapEncodeDecode(t *testing.T) {
+	encap := NewMPLSEncap([]int{100, 200, 300})
+	b, err := encap.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &MPLSEncap{}
+	if err := decoded.Decode(b); err != nil {
+		t.Fatal(err)
+	}
+	if !encap.Equal(decoded) {
+		t.Fatalf("Decoded encap %v, expected %v", decoded, encap)
+	}
+	if decoded.String() != "100/200/300" {
+		t.Fatalf("Unexpected label stack string %q", decoded.String())
+	}
+
+	dst := &MPLSDestination{Labels: []int{16, 17}}
+	b, err = dst.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decodedDst := &MPLSDestination{}
+	if err := decodedDst.Decode(b); err != nil {
+		t.Fatal(err)
+	}
+	if !dst.Equal(decodedDst) {
+		t.Fatalf("Decoded destination %v, expected %v", decodedDst, dst)
+	}
+}
+
+func TestRouteRawEncap(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	// LWTUNNEL_IP_ID and LWTUNNEL_IP_DST
+	id := make([]byte, 8)
+	binary.BigEndian.PutUint64(id, 5)
+	data := nl.NewRtAttr(1, id).Serialize()
+	data = append(data, nl.NewRtAttr(2, net.IPv4(10, 0, 0, 1).To4()).Serialize()...)
+	route := Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.IPv4(10, 9, 9, 0), Mask: net.CIDRMask(24, 32)},
+		Encap:     &RawEncap{EncapType: nl.LWTUNNEL_ENCAP_IP, Data: data},
+	}
+	if err := RouteAdd(&route); err != nil {
+		t.Skipf("Failed to add ip encap route: %v", err)
+	}
+
+	routes, err := RouteListFiltered(FAMILY_V4, &route, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	encap, ok := routes[0].Encap.(*RawEncap)
+	if !ok || encap.Type() != nl.LWTUNNEL_ENCAP_IP {
+		t.Fatalf("Unexpected encap %v", routes[0].Encap)
+	}
+
+	// Re-adding the listed route must preserve the encap
+	listed := routes[0]
+	if err := RouteDel(&listed); err != nil {
+		t.Fatal(err)
+	}
+	if err := RouteAdd(&listed); err != nil {
+		t.Fatal(err)
+	}
+	routes, err = RouteListFiltered(FAMILY_V4, &route, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 || !routes[0].Encap.Equal(encap) {
+		t.Fatalf("Encap not preserved: %v", routes)
+	}
+}
+
+func TestRouteRawAttributes(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	realmOf := func(r Route) uint32 {
+		for _, raw := range r.RawAttributes {
+			if raw.Type == unix.RTA_FLOW && len(raw.Value) == 4 {
+				return nl.NativeEndian().Uint32(raw.Value)
+			}
+		}
+		return 0
+	}
+
+	// RTA_FLOW (the route realm) is not modelled by Route
+	route := Route{
+		LinkIndex:     link.Attrs().Index,
+		Dst:           &net.IPNet{IP: net.IPv4(10, 9, 8, 0), Mask: net.CIDRMask(24, 32)},
+		RawAttributes: []RawAttribute{{Type: unix.RTA_FLOW, Value: nl.Uint32Attr(5)}},
+	}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteListFiltered(FAMILY_V4, &route, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	if realm := realmOf(routes[0]); realm != 5 {
+		t.Fatalf("RTA_FLOW not preserved: %v", routes[0].RawAttributes)
+	}
+
+	listed := routes[0]
+	if err := RouteDel(&listed); err != nil {
+		t.Fatal(err)
+	}
+	if err := RouteAdd(&listed); err != nil {
+		t.Fatal(err)
+	}
+	routes, err = RouteListFiltered(FAMILY_V4, &route, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not re-added properly")
+	}
+	if realm := realmOf(routes[0]); realm != 5 {
+		t.Fatalf("RTA_FLOW lost on re-add: %v", routes[0].RawAttributes)
+	}
+}
+
+func TestRouteExpires(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	// the kernel only keeps the expiry of IPv6 routes via a gateway
+	if err := LinkAdd(&Veth{LinkAttrs: LinkAttrs{Name: "foo"}, PeerName: "bar"}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+	address := &Addr{
+		IPNet: &net.IPNet{IP: net.ParseIP("2001:db8:5::1"), Mask: net.CIDRMask(64, 128)},
+		Flags: unix.IFA_F_NODAD,
+	}
+	if err := AddrAdd(link, address); err != nil {
+		t.Fatal(err)
+	}
+
+	route := Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.ParseIP("2001:db8:1::"), Mask: net.CIDRMask(64, 128)},
+		Gw:        net.ParseIP("2001:db8:5::2"),
+		Expires:   60,
+	}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteListFiltered(FAMILY_V6, &route, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatal("Route not added properly")
+	}
+	if routes[0].Expires == 0 {
+		t.Skip("Kernel does not report the route expiry")
+	}
+	if routes[0].Expires < 0 || routes[0].Expires > route.Expires {
+		t.Fatalf("Expected the route to expire within %ds, got %ds", route.Expires, routes[0].Expires)
+	}
+	for _, raw := range routes[0].RawAttributes {
+		if raw.Type == unix.RTA_EXPIRES {
+			t.Fatalf("Expiry left in the raw attributes %v", routes[0].RawAttributes)
+		}
+	}
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	route = Route{
+		LinkIndex: link.Attrs().Index,
+		Dst:       &net.IPNet{IP: net.IPv4(10, 9, 8, 0), Mask: net.CIDRMask(24, 32)},
+		Expires:   60,
+	}
+	if err := RouteAdd(&route); err == nil {
+		t.Fatal("Expected an error for an expiring IPv4 route")
+	}
+}
+
 func TestRouteEqual(t *testing.T) {
 	mplsDst := 100
 	seg6encap := &SEG6Encap{Mode: nl.SEG6_IPTUN_MODE_ENCAP}
@@ -1050,6 +1367,9 @@ func TestSEG6LocalEqual(t *testing.T) {
 	var flags_end_dt4 [nl.SEG6_LOCAL_MAX]bool
 	flags_end_dt4[nl.SEG6_LOCAL_ACTION] = true
 	flags_end_dt4[nl.SEG6_LOCAL_TABLE] = true
+	var flags_end_dt4_vrf [nl.SEG6_LOCAL_MAX]bool
+	flags_end_dt4_vrf[nl.SEG6_LOCAL_ACTION] = true
+	flags_end_dt4_vrf[nl.SEG6_LOCAL_VRFTABLE] = true
 	var flags_end_b6 [nl.SEG6_LOCAL_MAX]bool
 	flags_end_b6[nl.SEG6_LOCAL_ACTION] = true
 	flags_end_b6[nl.SEG6_LOCAL_SRH] = true
@@ -1097,6 +1417,11 @@ func TestSEG6LocalEqual(t *testing.T) {
 			Action: nl.SEG6_LOCAL_ACTION_END_DT4,
 			Table:  40,
 		},
+		{
+			Flags:    flags_end_dt4_vrf,
+			Action:   nl.SEG6_LOCAL_ACTION_END_DT4,
+			VrfTable: 40,
+		},
 		{
 			Flags:    flags_end_b6,
 			Action:   nl.SEG6_LOCAL_ACTION_END_B6,
@@ -1121,6 +1446,65 @@ func TestSEG6LocalEqual(t *testing.T) {
 		}
 	}
 }
+
+func TestSEG6LocalEncapEncodeDecode(t *testing.T) {
+	var flags [nl.SEG6_LOCAL_MAX]bool
+	flags[nl.SEG6_LOCAL_ACTION] = true
+	flags[nl.SEG6_LOCAL_VRFTABLE] = true
+	dt4 := &SEG6LocalEncap{
+		Flags:    flags,
+		Action:   nl.SEG6_LOCAL_ACTION_END_DT4,
+		VrfTable: 100,
+	}
+	flags = [nl.SEG6_LOCAL_MAX]bool{}
+	flags[nl.SEG6_LOCAL_ACTION] = true
+	flags[nl.SEG6_LOCAL_NH6] = true
+	endX := &SEG6LocalEncap{
+		Flags:   flags,
+		Action:  nl.SEG6_LOCAL_ACTION_END_X,
+		In6Addr: net.ParseIP("2001:db8::1"),
+	}
+	for _, encap := range []*SEG6LocalEncap{dt4, endX} {
+		b, err := encap.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := &SEG6LocalEncap{}
+		if err := decoded.Decode(b); err != nil {
+			t.Fatal(err)
+		}
+		if !encap.Equal(decoded) {
+			t.Fatalf("Decoded encap %v, expected %v", decoded, encap)
+		}
+	}
+}
+
+func TestSEG6EncapEncodeDecode(t *testing.T) {
+	encap := &SEG6Encap{
+		Mode:     nl.SEG6_IPTUN_MODE_ENCAP,
+		Segments: []net.IP{net.ParseIP("fc00:a000::22"), net.ParseIP("fc00:a000::21")},
+	}
+	b, err := encap.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &SEG6Encap{}
+	if err := decoded.Decode(b); err != nil {
+		t.Fatal(err)
+	}
+	if !encap.Equal(decoded) {
+		t.Fatalf("Decoded encap %v, expected %v", decoded, encap)
+	}
+
+	encap.Segments = []net.IP{net.IPv4(10, 0, 0, 1)}
+	if _, err := encap.Encode(); err == nil {
+		t.Fatal("Expected an error for an IPv4 segment")
+	}
+	if err := decoded.Decode(b[:8]); err == nil {
+		t.Fatal("Expected an error for a truncated encap")
+	}
+}
+
 func TestSEG6RouteAddDel(t *testing.T) {
 	// add/del routes with LWTUNNEL_SEG6 to/from loopback interface.
 	// Test both seg6 modes: encap (IPv4) & inline (IPv6).
@@ -1176,6 +1560,9 @@ func TestSEG6RouteAddDel(t *testing.T) {
 		if route.Encap.Type() != nl.LWTUNNEL_ENCAP_SEG6 {
 			t.Fatal("Invalid Type. SEG6_IPTUN_MODE_INLINE routes not added properly")
 		}
+		if !route.Encap.Equal(e1) {
+			t.Fatalf("Unexpected segment list %v", route.Encap)
+		}
 	}
 	// SEG6_IPTUN_MODE_ENCAP
 	routes, err = RouteList(link, FAMILY_V4)
@@ -1189,6 +1576,9 @@ func TestSEG6RouteAddDel(t *testing.T) {
 		if route.Encap.Type() != nl.LWTUNNEL_ENCAP_SEG6 {
 			t.Fatal("Invalid Type. SEG6_IPTUN_MODE_ENCAP routes not added properly")
 		}
+		if !route.Encap.Equal(e2) {
+			t.Fatalf("Unexpected segment list %v", route.Encap)
+		}
 	}
 
 	// Del (remove) SEG6 routes
@@ -1339,3 +1729,138 @@ func TestMTURouteAddDel(t *testing.T) {
 		t.Fatal("Route not removed properly")
 	}
 }
+
+func TestRouteFilterDstExact(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cidr := range []string{"10.0.0.0/16", "10.0.0.0/24", "0.0.0.0/0"} {
+		_, dst, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := RouteAdd(&Route{LinkIndex: link.Attrs().Index, Dst: dst}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var filterTests = []struct {
+		dst      *net.IPNet
+		expected string
+	}{
+		{&net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(24, 32)}, "10.0.0.0/24"},
+		{&net.IPNet{IP: net.IPv4(10, 0, 0, 1), Mask: net.CIDRMask(16, 32)}, "10.0.0.0/16"},
+		{&net.IPNet{IP: net.IPv4zero, Mask: net.CIDRMask(0, 32)}, "<nil>"},
+		{nil, "<nil>"},
+	}
+	for _, f := range filterTests {
+		routes, err := RouteListFiltered(FAMILY_V4, &Route{Dst: f.dst}, RT_FILTER_DST)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(routes) != 1 {
+			t.Fatalf("Expected 1 route for %s, got %v", f.dst, routes)
+		}
+		if routes[0].Dst.String() != f.expected {
+			t.Fatalf("Expected route to %s, got %s", f.expected, routes[0].Dst)
+		}
+	}
+
+	routes, err := RouteListFiltered(FAMILY_V4, &Route{
+		Dst:      &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(24, 32)},
+		Hoplimit: 10,
+	}, RT_FILTER_DST|RT_FILTER_HOPLIMIT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 0 {
+		t.Fatalf("Expected the hoplimit to filter out all routes, got %v", routes)
+	}
+}
+
+func TestRouteAddSrcPrefix(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+
+	link, err := LinkByName("lo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkSetUp(link); err != nil {
+		t.Fatal(err)
+	}
+
+	_, dst, _ := net.ParseCIDR("2001:db8:1::/48")
+	_, src, _ := net.ParseCIDR("2001:db8:2::/56")
+	route := Route{LinkIndex: link.Attrs().Index, Dst: dst, SrcPrefix: src}
+	if err := RouteAdd(&route); err != nil {
+		t.Fatal(err)
+	}
+	routes, err := RouteListFiltered(FAMILY_V6, &Route{Dst: dst}, RT_FILTER_DST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(routes) != 1 {
+		t.Fatalf("Expected 1 route, got %v", routes)
+	}
+	if !ipNetEqual(routes[0].SrcPrefix, src) {
+		t.Fatalf("Expected source prefix %s, got %s", src, routes[0].SrcPrefix)
+	}
+	if err := RouteDel(&route); err != nil {
+		t.Fatal(err)
+	}
+
+	v4src := &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+	if err := RouteAdd(&Route{LinkIndex: link.Attrs().Index, Dst: dst, SrcPrefix: v4src}); err == nil {
+		t.Fatal("Expected an error for mixed address families")
+	}
+}
+
+func TestRouteString(t *testing.T) {
+	_, dst, _ := net.ParseCIDR("10.0.0.0/24")
+	route := Route{
+		Dst:       dst,
+		Gw:        net.ParseIP("192.168.1.1"),
+		LinkIndex: 2,
+		Table:     10,
+		Protocol:  unix.RTPROT_STATIC,
+		Priority:  100,
+	}
+	expected := "10.0.0.0/24 via 192.168.1.1 dev 2 table 10 proto static metric 100"
+	if s := route.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	route = Route{
+		LinkIndex: 3,
+		Table:     unix.RT_TABLE_MAIN,
+		Scope:     SCOPE_LINK,
+		Src:       net.ParseIP("10.0.0.2"),
+		Type:      unix.RTN_UNICAST,
+	}
+	expected = "default dev 3 scope link src 10.0.0.2"
+	if s := route.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	route = Route{
+		Dst:  dst,
+		Type: unix.RTN_BLACKHOLE,
+		MultiPath: []*NexthopInfo{
+			{LinkIndex: 2, Gw: net.ParseIP("192.168.1.1")},
+			{LinkIndex: 3, Gw: net.ParseIP("192.168.2.1"), Hops: 1, Flags: int(FLAG_ONLINK)},
+		},
+	}
+	expected = "blackhole 10.0.0.0/24 nexthop via 192.168.1.1 dev 2 weight 1 nexthop via 192.168.2.1 dev 3 weight 2 onlink"
+	if s := route.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+}
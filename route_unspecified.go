@@ -2,6 +2,8 @@
 
 package netlink
 
+import "strconv"
+
 func (r *Route) ListFlags() []string {
 	return []string{}
 }
@@ -9,3 +11,19 @@ func (r *Route) ListFlags() []string {
 func (n *NexthopInfo) ListFlags() []string {
 	return []string{}
 }
+
+func routeTypeStr(routeType int) string {
+	return strconv.Itoa(routeType)
+}
+
+func routeTableStr(table int) string {
+	return strconv.Itoa(table)
+}
+
+func routeProtocolStr(protocol int) string {
+	return strconv.Itoa(protocol)
+}
+
+func (s Scope) String() string {
+	return strconv.Itoa(int(s))
+}
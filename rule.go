@@ -3,6 +3,7 @@ package netlink
 import (
 	"fmt"
 	"net"
+	"strings"
 )
 
 // Rule represents a netlink rule.
@@ -22,10 +23,60 @@ type Rule struct {
 	SuppressIfgroup   int
 	SuppressPrefixlen int
 	Invert            bool
+	// L3mdev looks up the table of the l3mdev (VRF) device the packet
+	// arrived on or is sent to. Table must not be set with it.
+	L3mdev bool
 }
 
+// String formats the rule like `ip rule`, selectors that are not set are
+// left out.
 func (r Rule) String() string {
-	return fmt.Sprintf("ip rule %d: from %s table %d", r.Priority, r.Src, r.Table)
+	elems := []string{fmt.Sprintf("ip rule %d:", r.Priority)}
+	if r.Invert {
+		elems = append(elems, "not")
+	}
+	if r.Src != nil {
+		elems = append(elems, fmt.Sprintf("from %s", r.Src))
+	} else {
+		elems = append(elems, "from all")
+	}
+	if r.Dst != nil {
+		elems = append(elems, fmt.Sprintf("to %s", r.Dst))
+	}
+	if r.IifName != "" {
+		elems = append(elems, fmt.Sprintf("iif %s", r.IifName))
+	}
+	if r.OifName != "" {
+		elems = append(elems, fmt.Sprintf("oif %s", r.OifName))
+	}
+	if r.Mark >= 0 {
+		if r.Mask >= 0 {
+			elems = append(elems, fmt.Sprintf("fwmark %#x/%#x", r.Mark, r.Mask))
+		} else {
+			elems = append(elems, fmt.Sprintf("fwmark %#x", r.Mark))
+		}
+	}
+	if r.TunID > 0 {
+		elems = append(elems, fmt.Sprintf("tun_id %d", r.TunID))
+	}
+	if r.Flow >= 0 {
+		elems = append(elems, fmt.Sprintf("realms %d", r.Flow))
+	}
+	if r.SuppressPrefixlen >= 0 {
+		elems = append(elems, fmt.Sprintf("suppress_prefixlength %d", r.SuppressPrefixlen))
+	}
+	if r.SuppressIfgroup >= 0 {
+		elems = append(elems, fmt.Sprintf("suppress_ifgroup %d", r.SuppressIfgroup))
+	}
+	switch {
+	case r.Goto >= 0:
+		elems = append(elems, fmt.Sprintf("goto %d", r.Goto))
+	case r.L3mdev:
+		elems = append(elems, "lookup [l3mdev-table]")
+	default:
+		elems = append(elems, fmt.Sprintf("table %d", r.Table))
+	}
+	return strings.Join(elems, " ")
 }
 
 // NewRule return empty rules.
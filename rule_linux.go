@@ -125,7 +125,13 @@ func ruleHandle(rule *Rule, req *nl.NetlinkRequest) error {
 		native.PutUint32(b, uint32(rule.Table))
 		req.AddData(nl.NewRtAttr(nl.FRA_TABLE, b))
 	}
-	if msg.Table > 0 {
+	if rule.L3mdev {
+		if rule.Table > 0 {
+			return fmt.Errorf("l3mdev and table are mutually exclusive")
+		}
+		req.AddData(nl.NewRtAttr(nl.FRA_L3MDEV, nl.Uint8Attr(1)))
+	}
+	if msg.Table > 0 || rule.Table >= 256 || rule.L3mdev {
 		if rule.SuppressPrefixlen >= 0 {
 			b := make([]byte, 4)
 			native.PutUint32(b, uint32(rule.SuppressPrefixlen))
@@ -225,6 +231,8 @@ func (h *Handle) RuleList(family int) ([]Rule, error) {
 				rule.Goto = int(native.Uint32(attrs[j].Value[0:4]))
 			case nl.FRA_PRIORITY:
 				rule.Priority = int(native.Uint32(attrs[j].Value[0:4]))
+			case nl.FRA_L3MDEV:
+				rule.L3mdev = attrs[j].Value[0] != 0
 			}
 		}
 		res = append(res, *rule)
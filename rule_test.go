@@ -73,3 +73,88 @@ func TestRuleAddDel(t *testing.T) {
 		t.Fatal("Rule not removed properly")
 	}
 }
+
+func TestRuleL3mdevSuppressPrefixlen(t *testing.T) {
+	skipUnlessRoot(t)
+	minKernelRequired(t, 4, 8)
+	defer setUpNetlinkTest(t)()
+
+	l3mdev := NewRule()
+	l3mdev.Priority = 1000
+	l3mdev.L3mdev = true
+	if err := RuleAdd(l3mdev); err != nil {
+		t.Fatal(err)
+	}
+
+	suppress := NewRule()
+	suppress.Priority = 1001
+	suppress.Table = unix.RT_TABLE_MAIN
+	suppress.SuppressPrefixlen = 0
+	if err := RuleAdd(suppress); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := RuleList(unix.AF_INET)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var foundL3mdev, foundSuppress bool
+	for _, rule := range rules {
+		switch rule.Priority {
+		case l3mdev.Priority:
+			foundL3mdev = rule.L3mdev
+		case suppress.Priority:
+			foundSuppress = rule.SuppressPrefixlen == 0 && rule.Table == unix.RT_TABLE_MAIN
+		}
+	}
+	if !foundL3mdev {
+		t.Fatal("l3mdev rule not found")
+	}
+	if !foundSuppress {
+		t.Fatal("suppress_prefixlen rule not found")
+	}
+
+	if err := RuleDel(l3mdev); err != nil {
+		t.Fatal(err)
+	}
+	if err := RuleDel(suppress); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := NewRule()
+	invalid.L3mdev = true
+	invalid.Table = unix.RT_TABLE_MAIN
+	if err := RuleAdd(invalid); err == nil {
+		t.Fatal("expected an error for an l3mdev rule with a table")
+	}
+}
+
+func TestRuleString(t *testing.T) {
+	_, src, _ := net.ParseCIDR("10.0.0.0/24")
+	_, dst, _ := net.ParseCIDR("192.168.0.0/16")
+
+	r := NewRule()
+	r.Priority = 100
+	r.Table = 10
+	if s := r.String(); s != "ip rule 100: from all table 10" {
+		t.Fatalf("Unexpected rule string %q", s)
+	}
+
+	r.Src = src
+	r.Dst = dst
+	r.IifName = "eth0"
+	r.Mark = 0x10
+	r.Mask = 0xff
+	r.Invert = true
+	expected := "ip rule 100: not from 10.0.0.0/24 to 192.168.0.0/16 iif eth0 fwmark 0x10/0xff table 10"
+	if s := r.String(); s != expected {
+		t.Fatalf("Expected %q, got %q", expected, s)
+	}
+
+	r = NewRule()
+	r.Priority = 5
+	r.Goto = 100
+	if s := r.String(); s != "ip rule 5: from all goto 100" {
+		t.Fatalf("Unexpected rule string %q", s)
+	}
+}
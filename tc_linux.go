@@ -0,0 +1,358 @@
+package netlink
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TcConfig is a snapshot of the traffic control configuration of a link:
+// its qdiscs, classes and filters. It can be serialized to JSON and later
+// re-applied to the same or to another link with ApplyTc.
+type TcConfig struct {
+	Qdiscs  []Qdisc
+	Classes []Class
+	Filters []Filter
+}
+
+// tcObject is the JSON envelope of a Qdisc, Class, Filter or Action. The
+// type is stored alongside the object so the interface can be decoded back
+// into its concrete type.
+type tcObject struct {
+	Type     string          `json:"type"`
+	Object   json.RawMessage `json:"object"`
+	Actions  []tcObject      `json:"actions,omitempty"`
+	Ematches []tcObject      `json:"ematches,omitempty"`
+}
+
+type tcConfigJSON struct {
+	Qdiscs  []tcObject `json:"qdiscs"`
+	Classes []tcObject `json:"classes"`
+	Filters []tcObject `json:"filters"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (c *TcConfig) MarshalJSON() ([]byte, error) {
+	var out tcConfigJSON
+	for _, qdisc := range c.Qdiscs {
+		obj, err := newTcObject(qdisc.Type(), qdisc)
+		if err != nil {
+			return nil, err
+		}
+		out.Qdiscs = append(out.Qdiscs, obj)
+	}
+	for _, class := range c.Classes {
+		obj, err := newTcObject(class.Type(), class)
+		if err != nil {
+			return nil, err
+		}
+		out.Classes = append(out.Classes, obj)
+	}
+	for _, filter := range c.Filters {
+		obj, err := newTcObject(filter.Type(), filter)
+		if err != nil {
+			return nil, err
+		}
+		if actions := filterActions(filter); actions != nil {
+			// actions are interfaces as well, so they are stored
+			// in their own envelopes next to the filter
+			if obj.Object, err = deleteJSONField(obj.Object, "Actions"); err != nil {
+				return nil, err
+			}
+			for _, action := range *actions {
+				aobj, err := newTcObject(action.Type(), action)
+				if err != nil {
+					return nil, err
+				}
+				obj.Actions = append(obj.Actions, aobj)
+			}
+		}
+		if basic, ok := filter.(*Basic); ok {
+			// and so are ematches
+			if obj.Object, err = deleteJSONField(obj.Object, "Ematches"); err != nil {
+				return nil, err
+			}
+			for _, ematch := range basic.Ematches {
+				eobj, err := newTcObject(ematch.Type(), ematch)
+				if err != nil {
+					return nil, err
+				}
+				obj.Ematches = append(obj.Ematches, eobj)
+			}
+		}
+		out.Filters = append(out.Filters, obj)
+	}
+	return json.Marshal(out)
+}
+
+// deleteJSONField removes the field name from the JSON object b.
+func deleteJSONField(b []byte, name string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, name)
+	return json.Marshal(fields)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *TcConfig) UnmarshalJSON(b []byte) error {
+	var in tcConfigJSON
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	c.Qdiscs, c.Classes, c.Filters = nil, nil, nil
+	for _, obj := range in.Qdiscs {
+		qdisc := newQdiscOfType(obj.Type)
+		if err := json.Unmarshal(obj.Object, qdisc); err != nil {
+			return err
+		}
+		c.Qdiscs = append(c.Qdiscs, qdisc)
+	}
+	for _, obj := range in.Classes {
+		class := newClassOfType(obj.Type)
+		if err := json.Unmarshal(obj.Object, class); err != nil {
+			return err
+		}
+		c.Classes = append(c.Classes, class)
+	}
+	for _, obj := range in.Filters {
+		filter := newFilterOfType(obj.Type)
+		if err := json.Unmarshal(obj.Object, filter); err != nil {
+			return err
+		}
+		if len(obj.Actions) > 0 {
+			actions := filterActions(filter)
+			if actions == nil {
+				return fmt.Errorf("filter type %s does not support actions", obj.Type)
+			}
+			for _, aobj := range obj.Actions {
+				action, err := newActionOfType(aobj.Type)
+				if err != nil {
+					return err
+				}
+				if err := json.Unmarshal(aobj.Object, action); err != nil {
+					return err
+				}
+				*actions = append(*actions, action)
+			}
+		}
+		if len(obj.Ematches) > 0 {
+			basic, ok := filter.(*Basic)
+			if !ok {
+				return fmt.Errorf("filter type %s does not support ematches", obj.Type)
+			}
+			for _, eobj := range obj.Ematches {
+				ematch, err := newEmatchOfType(eobj.Type)
+				if err != nil {
+					return err
+				}
+				if err := json.Unmarshal(eobj.Object, ematch); err != nil {
+					return err
+				}
+				basic.Ematches = append(basic.Ematches, ematch)
+			}
+		}
+		c.Filters = append(c.Filters, filter)
+	}
+	return nil
+}
+
+func newTcObject(typ string, v interface{}) (tcObject, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return tcObject{}, err
+	}
+	return tcObject{Type: typ, Object: b}, nil
+}
+
+func newEmatchOfType(typ string) (Ematch, error) {
+	switch typ {
+	case "u32":
+		return &U32Ematch{}, nil
+	case "cmp":
+		return &CmpEmatch{}, nil
+	case "container":
+		return &ContainerEmatch{}, nil
+	case "meta":
+		return &MetaEmatch{}, nil
+	case "generic":
+		return &GenericEmatch{}, nil
+	}
+	return nil, fmt.Errorf("unknown ematch type %s", typ)
+}
+
+// filterActions returns a pointer to the action list of the filter, or nil
+// if the filter type does not carry actions.
+func filterActions(filter Filter) *[]Action {
+	switch filter := filter.(type) {
+	case *U32:
+		return &filter.Actions
+	case *MatchAll:
+		return &filter.Actions
+	case *Basic:
+		return &filter.Actions
+	}
+	return nil
+}
+
+// DumpTc returns the qdiscs, classes and filters configured on a link.
+// Equivalent to: `tc qdisc show dev $link; tc class show dev $link;
+// tc filter show dev $link`
+// Qdiscs without a handle are created by the kernel as defaults and are
+// not part of the dump. Statistics are not included.
+func DumpTc(link Link) (*TcConfig, error) {
+	return pkgHandle.DumpTc(link)
+}
+
+// DumpTc returns the qdiscs, classes and filters configured on a link.
+// Equivalent to: `tc qdisc show dev $link; tc class show dev $link;
+// tc filter show dev $link`
+// Qdiscs without a handle are created by the kernel as defaults and are
+// not part of the dump. Statistics are not included.
+func (h *Handle) DumpTc(link Link) (*TcConfig, error) {
+	qdiscs, err := h.QdiscList(link)
+	if err != nil {
+		return nil, err
+	}
+	config := &TcConfig{}
+	var parents []uint32
+	for _, qdisc := range qdiscs {
+		if qdisc.Attrs().Handle == HANDLE_NONE {
+			continue
+		}
+		config.Qdiscs = append(config.Qdiscs, qdisc)
+		switch qdisc.Type() {
+		case "ingress":
+			parents = append(parents, HANDLE_MIN_INGRESS)
+		case "clsact":
+			parents = append(parents, HANDLE_MIN_INGRESS, HANDLE_MIN_EGRESS)
+		default:
+			parents = append(parents, qdisc.Attrs().Handle)
+		}
+	}
+
+	seen := make(map[uint32]bool)
+	for _, qdisc := range config.Qdiscs {
+		classes, err := h.ClassList(link, qdisc.Attrs().Handle)
+		if err != nil {
+			return nil, err
+		}
+		for _, class := range classes {
+			// classes of classful qdiscs such as prio are created
+			// implicitly and cannot be added back
+			if _, ok := class.(*GenericClass); ok {
+				continue
+			}
+			if seen[class.Attrs().Handle] {
+				continue
+			}
+			seen[class.Attrs().Handle] = true
+			class.Attrs().Statistics = nil
+			config.Classes = append(config.Classes, class)
+			parents = append(parents, class.Attrs().Handle)
+		}
+	}
+
+	for _, parent := range parents {
+		filters, err := h.FilterList(link, parent)
+		if err != nil {
+			return nil, err
+		}
+		for _, filter := range filters {
+			if actions := filterActions(filter); actions != nil {
+				for _, action := range *actions {
+					if action != nil {
+						action.Attrs().Statistics = nil
+					}
+				}
+			}
+		}
+		config.Filters = append(config.Filters, filters...)
+	}
+	return config, nil
+}
+
+// ApplyTc adds the qdiscs, classes and filters of config to a link. The
+// hierarchy is created in dependency order: root qdiscs first, then their
+// classes and child qdiscs, and the filters last. The link index of every
+// object in config is updated to the index of link.
+// The link is expected to carry only its default qdiscs.
+func ApplyTc(link Link, config *TcConfig) error {
+	return pkgHandle.ApplyTc(link, config)
+}
+
+// ApplyTc adds the qdiscs, classes and filters of config to a link. The
+// hierarchy is created in dependency order: root qdiscs first, then their
+// classes and child qdiscs, and the filters last. The link index of every
+// object in config is updated to the index of link.
+// The link is expected to carry only its default qdiscs.
+func (h *Handle) ApplyTc(link Link, config *TcConfig) error {
+	base := link.Attrs()
+	h.ensureIndex(base)
+	for _, qdisc := range config.Qdiscs {
+		qdisc.Attrs().LinkIndex = base.Index
+	}
+	for _, class := range config.Classes {
+		class.Attrs().LinkIndex = base.Index
+	}
+	for _, filter := range config.Filters {
+		filter.Attrs().LinkIndex = base.Index
+	}
+
+	qdiscs := append([]Qdisc{}, config.Qdiscs...)
+	classes := append([]Class{}, config.Classes...)
+	// pending reports whether the qdisc or class owning handle has yet
+	// to be created
+	pending := func(handle uint32) bool {
+		major, _ := MajorMinor(handle)
+		for _, qdisc := range qdiscs {
+			if qdisc.Attrs().Handle == MakeHandle(major, 0) {
+				return true
+			}
+		}
+		for _, class := range classes {
+			if class.Attrs().Handle == handle {
+				return true
+			}
+		}
+		return false
+	}
+	for len(qdiscs) > 0 || len(classes) > 0 {
+		progress := false
+		for i := 0; i < len(qdiscs); {
+			parent := qdiscs[i].Attrs().Parent
+			if parent != HANDLE_ROOT && parent != HANDLE_INGRESS && pending(parent) {
+				i++
+				continue
+			}
+			if err := h.QdiscAdd(qdiscs[i]); err != nil {
+				return err
+			}
+			qdiscs = append(qdiscs[:i], qdiscs[i+1:]...)
+			progress = true
+		}
+		for i := 0; i < len(classes); {
+			attrs := classes[i].Attrs()
+			major, _ := MajorMinor(attrs.Handle)
+			if pending(MakeHandle(major, 0)) || (attrs.Parent != HANDLE_ROOT && pending(attrs.Parent)) {
+				i++
+				continue
+			}
+			if err := h.ClassAdd(classes[i]); err != nil {
+				return err
+			}
+			classes = append(classes[:i], classes[i+1:]...)
+			progress = true
+		}
+		if !progress {
+			return fmt.Errorf("tc config contains parents that cannot be resolved")
+		}
+	}
+
+	for _, filter := range config.Filters {
+		if err := h.FilterAdd(filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
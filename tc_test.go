@@ -0,0 +1,231 @@
+// +build linux
+
+package netlink
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTcConfigJSON(t *testing.T) {
+	config := &TcConfig{
+		Qdiscs: []Qdisc{
+			NewHtb(QdiscAttrs{
+				LinkIndex: 2,
+				Handle:    MakeHandle(0xffff, 0),
+				Parent:    HANDLE_ROOT,
+			}),
+			&GenericQdisc{
+				QdiscAttrs: QdiscAttrs{
+					LinkIndex: 2,
+					Handle:    MakeHandle(0xffff, 0),
+					Parent:    HANDLE_CLSACT,
+				},
+				QdiscType: "clsact",
+			},
+		},
+		Classes: []Class{
+			&HtbClass{
+				ClassAttrs: ClassAttrs{
+					LinkIndex: 2,
+					Handle:    MakeHandle(0xffff, 2),
+					Parent:    MakeHandle(0xffff, 0),
+				},
+				Rate: 1234000,
+				Ceil: 1234000,
+			},
+		},
+		Filters: []Filter{
+			&MatchAll{
+				FilterAttrs: FilterAttrs{
+					LinkIndex: 2,
+					Parent:    HANDLE_MIN_INGRESS,
+					Priority:  1,
+				},
+				Actions: []Action{NewMirredAction(3), NewConnmarkAction()},
+			},
+			&Basic{
+				FilterAttrs: FilterAttrs{
+					LinkIndex: 2,
+					Parent:    HANDLE_MIN_INGRESS,
+					Priority:  2,
+				},
+				ClassId: MakeHandle(1, 1),
+				Ematches: []Ematch{
+					&CmpEmatch{
+						EmatchAttrs: EmatchAttrs{Relation: EMATCH_REL_AND},
+						Val:         6,
+						Mask:        0xff,
+						Off:         9,
+						Align:       1,
+						Layer:       EMATCH_LAYER_NETWORK,
+					},
+					&U32Ematch{
+						EmatchAttrs: EmatchAttrs{Relation: EMATCH_REL_AND},
+						Mask:        0xffff,
+						Val:         443,
+						Off:         20,
+					},
+					&MetaEmatch{Id: EMATCH_META_ID_PRIORITY, Val: 5},
+				},
+				Actions: []Action{NewMirredAction(3)},
+			},
+		},
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &TcConfig{}
+	if err := json.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(config, decoded) {
+		t.Fatalf("%#v is expected but it actually was %#v", config, decoded)
+	}
+}
+
+func TestTcConfigJSONQdiscs(t *testing.T) {
+	attrs := QdiscAttrs{LinkIndex: 2, Handle: MakeHandle(1, 0), Parent: HANDLE_ROOT}
+	config := &TcConfig{
+		Qdiscs: []Qdisc{
+			&Pfifo{QdiscAttrs: attrs, Limit: 100},
+			&Bfifo{QdiscAttrs: attrs, Limit: 10000},
+			&Cake{QdiscAttrs: attrs, Bandwidth: 125000, RTT: 50000, Diffserv: CAKE_DIFFSERV_BESTEFFORT, Wash: true},
+			&Codel{QdiscAttrs: attrs, Target: 4000, Limit: 500, Interval: 80000, ECN: 1},
+			&Pie{QdiscAttrs: attrs, Target: 15000, Limit: 1000, Alpha: 2, Beta: 20},
+			&FqPie{QdiscAttrs: attrs, Limit: 10240, Flows: 1024, Quantum: 1514},
+			&Etf{QdiscAttrs: attrs, ClockId: 11, Delta: 300000, Deadline: true},
+			&Taprio{QdiscAttrs: attrs, NumTc: 2, ClockId: 11, CycleTime: 1000000, Schedule: []TaprioEntry{{Command: TAPRIO_CMD_SET_GATES, GateMask: 1, Interval: 300000}}},
+			&Mqprio{QdiscAttrs: attrs, NumTc: 2, Count: [PRIORITY_MAP_LEN]uint16{1, 1}, Offset: [PRIORITY_MAP_LEN]uint16{0, 1}},
+			&Multiq{QdiscAttrs: attrs, Bands: 4},
+			&Cbs{QdiscAttrs: attrs, Hicredit: 30, Locredit: -1470, Idleslope: 20000, Sendslope: -980000},
+			&Plug{QdiscAttrs: attrs, Limit: 10000},
+			&Hhf{QdiscAttrs: attrs, Limit: 1000, Quantum: 1514, NonHHWeight: 2},
+			NewSfb(attrs),
+		},
+	}
+
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &TcConfig{}
+	if err := json.Unmarshal(b, decoded); err != nil {
+		t.Fatal(err)
+	}
+	for i, qdisc := range config.Qdiscs {
+		if !reflect.DeepEqual(qdisc, decoded.Qdiscs[i]) {
+			t.Fatalf("%#v is expected but it actually was %#v", qdisc, decoded.Qdiscs[i])
+		}
+	}
+}
+
+func TestDumpApplyTc(t *testing.T) {
+	tearDown := setUpNetlinkTest(t)
+	defer tearDown()
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "foo"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := LinkAdd(&Ifb{LinkAttrs{Name: "bar"}}); err != nil {
+		t.Fatal(err)
+	}
+	link, err := LinkByName("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := LinkByName("bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qdisc := NewHtb(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(0xffff, 0),
+		Parent:    HANDLE_ROOT,
+	})
+	if err := QdiscAdd(qdisc); err != nil {
+		t.Fatal(err)
+	}
+	class := NewHtbClass(ClassAttrs{
+		LinkIndex: link.Attrs().Index,
+		Parent:    MakeHandle(0xffff, 0),
+		Handle:    MakeHandle(0xffff, 2),
+	}, HtbClassAttrs{
+		Rate:    1234000,
+		Cbuffer: 1690,
+	})
+	if err := ClassAdd(class); err != nil {
+		t.Fatal(err)
+	}
+	netem := NewNetem(QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    MakeHandle(0x2, 0),
+		Parent:    MakeHandle(0xffff, 2),
+	}, NetemQdiscAttrs{
+		Latency:     20000,
+		Loss:        23.4,
+		Duplicate:   14.3,
+		LossCorr:    8.34,
+		Jitter:      1000,
+		DelayCorr:   12.3,
+		ReorderProb: 23.4,
+		CorruptProb: 10.0,
+		CorruptCorr: 10,
+	})
+	if err := QdiscAdd(netem); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := DumpTc(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Qdiscs) != 2 || len(config.Classes) != 1 {
+		t.Fatalf("unexpected dump %#v", config)
+	}
+	b, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored := &TcConfig{}
+	if err := json.Unmarshal(b, restored); err != nil {
+		t.Fatal(err)
+	}
+	// apply in reverse order to verify the dependency ordering
+	for i, j := 0, len(restored.Qdiscs)-1; i < j; i, j = i+1, j-1 {
+		restored.Qdiscs[i], restored.Qdiscs[j] = restored.Qdiscs[j], restored.Qdiscs[i]
+	}
+	if err := ApplyTc(other, restored); err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := DumpTc(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied.Qdiscs) != 2 || len(applied.Classes) != 1 {
+		t.Fatalf("unexpected dump %#v", applied)
+	}
+	htb, ok := applied.Classes[0].(*HtbClass)
+	if !ok {
+		t.Fatal("Class is the wrong type")
+	}
+	if htb.Rate != class.Rate || htb.Ceil != class.Ceil || htb.Buffer != class.Buffer || htb.Cbuffer != class.Cbuffer {
+		t.Fatalf("%v is expected but it actually was %v", class, htb)
+	}
+	var found bool
+	for _, qdisc := range applied.Qdiscs {
+		if n, ok := qdisc.(*Netem); ok {
+			found = true
+			if n.Parent != netem.Parent || n.Latency != netem.Latency || n.Loss != netem.Loss ||
+				n.Jitter != netem.Jitter || n.CorruptProb != netem.CorruptProb {
+				t.Fatalf("%v is expected but it actually was %v", netem, n)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("Netem qdisc was not restored")
+	}
+}
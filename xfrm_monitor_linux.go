@@ -21,14 +21,20 @@ func (ue *XfrmMsgExpire) Type() nl.XfrmMsgType {
 	return nl.XFRM_MSG_EXPIRE
 }
 
-func parseXfrmMsgExpire(b []byte) *XfrmMsgExpire {
+func parseXfrmMsgExpire(b []byte) (*XfrmMsgExpire, error) {
 	var e XfrmMsgExpire
 
 	msg := nl.DeserializeXfrmUserExpire(b)
 	e.XfrmState = xfrmStateFromXfrmUsersaInfo(&msg.XfrmUsersaInfo)
 	e.Hard = msg.Hard == 1
 
-	return &e
+	attrs, err := nl.ParseRouteAttr(b[nl.SizeofXfrmUserExpire:])
+	if err != nil {
+		return nil, err
+	}
+	parseXfrmStateAttrs(e.XfrmState, attrs)
+
+	return &e, nil
 }
 
 func XfrmMonitor(ch chan<- XfrmMsg, done <-chan struct{}, errorChan chan<- error,
@@ -66,7 +72,12 @@ func XfrmMonitor(ch chan<- XfrmMsg, done <-chan struct{}, errorChan chan<- error
 			for _, m := range msgs {
 				switch m.Header.Type {
 				case nl.XFRM_MSG_EXPIRE:
-					ch <- parseXfrmMsgExpire(m.Data)
+					e, err := parseXfrmMsgExpire(m.Data)
+					if err != nil {
+						errorChan <- err
+						continue
+					}
+					ch <- e
 				default:
 					errorChan <- fmt.Errorf("unsupported msg type: %x", m.Header.Type)
 				}
@@ -3,6 +3,7 @@
 package netlink
 
 import (
+	"net"
 	"testing"
 
 	"github.com/vishvananda/netlink/nl"
@@ -48,3 +49,38 @@ func TestXfrmMonitorExpire(t *testing.T) {
 		t.Fatal("Missing expire msg: hard found:", hardFound, "soft found:", softFound)
 	}
 }
+
+func TestXfrmMonitorParseExpire(t *testing.T) {
+	msg := &nl.XfrmUserExpire{Hard: 1}
+	msg.XfrmUsersaInfo.Id.Daddr.FromIP(net.ParseIP("127.0.0.2"))
+	msg.XfrmUsersaInfo.Saddr.FromIP(net.ParseIP("127.0.0.1"))
+	msg.XfrmUsersaInfo.Id.Spi = nl.Swap32(0x1234)
+	msg.XfrmUsersaInfo.Lft.SoftByteLimit = 1000
+	msg.XfrmUsersaInfo.Lft.HardByteLimit = 2000
+	b := msg.Serialize()
+
+	cur := &nl.XfrmLifetimeCur{Bytes: 1500, Packets: 10, AddTime: 1, UseTime: 2}
+	b = append(b, nl.NewRtAttr(nl.XFRMA_LTIME_VAL, cur.Serialize()).Serialize()...)
+	mark := &nl.XfrmMark{Value: 0x12340000, Mask: 0xffff0000}
+	b = append(b, nl.NewRtAttr(nl.XFRMA_MARK, mark.Serialize()).Serialize()...)
+
+	e, err := parseXfrmMsgExpire(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Hard {
+		t.Fatal("Expected hard expire")
+	}
+	if e.XfrmState.Spi != 0x1234 {
+		t.Fatalf("Expected spi 0x1234, got 0x%x", e.XfrmState.Spi)
+	}
+	if e.XfrmState.Limits.ByteSoft != 1000 || e.XfrmState.Limits.ByteHard != 2000 {
+		t.Fatalf("Unexpected limits %+v", e.XfrmState.Limits)
+	}
+	if e.XfrmState.Statistics.Bytes != 1500 || e.XfrmState.Statistics.Packets != 10 {
+		t.Fatalf("Unexpected statistics %+v", e.XfrmState.Statistics)
+	}
+	if e.XfrmState.Mark == nil || e.XfrmState.Mark.Value != mark.Value || e.XfrmState.Mark.Mask != mark.Mask {
+		t.Fatalf("Unexpected mark %v", e.XfrmState.Mark)
+	}
+}
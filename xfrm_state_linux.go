@@ -2,6 +2,7 @@ package netlink
 
 import (
 	"fmt"
+	"syscall"
 	"unsafe"
 
 	"github.com/vishvananda/netlink/nl"
@@ -334,7 +335,14 @@ func parseXfrmState(m []byte, family int) (*XfrmState, error) {
 	if err != nil {
 		return nil, err
 	}
+	parseXfrmStateAttrs(state, attrs)
 
+	return state, nil
+}
+
+// parseXfrmStateAttrs decodes the netlink attributes that follow a
+// xfrm_usersa_info into state.
+func parseXfrmStateAttrs(state *XfrmState, attrs []syscall.NetlinkRouteAttr) {
 	for _, attr := range attrs {
 		switch attr.Attr.Type {
 		case nl.XFRMA_ALG_AUTH, nl.XFRMA_ALG_CRYPT:
@@ -381,10 +389,14 @@ func parseXfrmState(m []byte, family int) (*XfrmState, error) {
 			state.OutputMark = int(native.Uint32(attr.Value))
 		case nl.XFRMA_IF_ID:
 			state.Ifid = int(native.Uint32(attr.Value))
+		case nl.XFRMA_LTIME_VAL:
+			cur := nl.DeserializeXfrmLifetimeCur(attr.Value[:])
+			state.Statistics.Bytes = cur.Bytes
+			state.Statistics.Packets = cur.Packets
+			state.Statistics.AddTime = cur.AddTime
+			state.Statistics.UseTime = cur.UseTime
 		}
 	}
-
-	return state, nil
 }
 
 // XfrmStateFlush will flush the xfrm state on the system.